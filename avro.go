@@ -0,0 +1,994 @@
+package serializebench
+
+import (
+	_ "embed" // for the Avro schema
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// Avro binary encoding (https://avro.apache.org/docs/current/spec.html) of Go values, driven by a JSON schema.
+// Records map to structs by field name, or to map[string]interface{}; arrays to slices; fixed to byte arrays;
+// int and long to any integer type, with uint64 values stored as their two's complement;
+// a union of null and another type to a pointer, or the other type's value.
+// Data is decoded with an AvroResolver, which resolves the schema the data was written with
+// against the schema of the reader. Maps, enums, floats and doubles are not supported.
+
+//go:embed block.avsc
+var avroBlockSchemaJSON []byte
+
+// avroBlockSchema is the schema of coin.SignedBlock
+var avroBlockSchema = mustParseAvroSchema(avroBlockSchemaJSON)
+
+// avroBlockResolver decodes coin.SignedBlock written with avroBlockSchema
+var avroBlockResolver = mustNewAvroResolver(avroBlockSchema, avroBlockSchema)
+
+// avroArrayLimits are the decode limits of the arrays of coin.SignedBlock, by field name
+var avroArrayLimits = map[string]string{
+	"Transactions": "MaxTransactions",
+	"Sigs":         "MaxSigs",
+	"In":           "MaxInputs",
+	"Out":          "MaxOutputs",
+}
+
+type avroKind int
+
+const (
+	avroNull avroKind = iota
+	avroBoolean
+	avroInt
+	avroLong
+	avroBytes
+	avroString
+	avroRecord
+	avroArray
+	avroFixed
+	avroUnion
+)
+
+var avroPrimitives = map[string]avroKind{
+	"null":    avroNull,
+	"boolean": avroBoolean,
+	"int":     avroInt,
+	"long":    avroLong,
+	"bytes":   avroBytes,
+	"string":  avroString,
+}
+
+var avroKindNames = map[avroKind]string{
+	avroNull:    "null",
+	avroBoolean: "boolean",
+	avroInt:     "int",
+	avroLong:    "long",
+	avroBytes:   "bytes",
+	avroString:  "string",
+	avroRecord:  "record",
+	avroArray:   "array",
+	avroFixed:   "fixed",
+	avroUnion:   "union",
+}
+
+// avroType is a node of a parsed schema
+type avroType struct {
+	kind avroKind
+	// name is the full name of a record or fixed type
+	name string
+	// fields are the fields of a record
+	fields []avroField
+	// items is the item type of an array
+	items *avroType
+	// size is the size of a fixed type
+	size int
+	// branches are the types of a union
+	branches []*avroType
+}
+
+type avroField struct {
+	name string
+	typ  *avroType
+	// def is the field's default value as parsed from JSON, if hasDefault is set
+	def        interface{}
+	hasDefault bool
+}
+
+func (t *avroType) String() string {
+	if t.name != "" {
+		return t.name
+	}
+	return avroKindNames[t.kind]
+}
+
+// AvroSchema is a parsed Avro schema
+type AvroSchema struct {
+	root *avroType
+}
+
+// ParseAvroSchema parses an Avro schema in JSON
+func ParseAvroSchema(data []byte) (*AvroSchema, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	p := avroParser{
+		names: make(map[string]*avroType),
+	}
+	root, err := p.parse(v, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &AvroSchema{
+		root: root,
+	}, nil
+}
+
+func mustParseAvroSchema(data []byte) *AvroSchema {
+	s, err := ParseAvroSchema(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+type avroParser struct {
+	names map[string]*avroType
+}
+
+func (p *avroParser) fullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func (p *avroParser) define(t *avroType, name, namespace string) error {
+	if name == "" {
+		return fmt.Errorf("%s type has no name", avroKindNames[t.kind])
+	}
+	t.name = p.fullName(name, namespace)
+	if _, ok := p.names[t.name]; ok {
+		return fmt.Errorf("type %s is defined twice", t.name)
+	}
+	p.names[t.name] = t
+	return nil
+}
+
+func (p *avroParser) parse(v interface{}, namespace string) (*avroType, error) {
+	switch v := v.(type) {
+	case string:
+		if kind, ok := avroPrimitives[v]; ok {
+			return &avroType{kind: kind}, nil
+		}
+		if t, ok := p.names[p.fullName(v, namespace)]; ok {
+			return t, nil
+		}
+		if t, ok := p.names[v]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", v)
+
+	case []interface{}:
+		t := &avroType{kind: avroUnion}
+		for _, b := range v {
+			branch, err := p.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if branch.kind == avroUnion {
+				return nil, errors.New("union contains a union")
+			}
+			t.branches = append(t.branches, branch)
+		}
+		return t, nil
+
+	case map[string]interface{}:
+		return p.parseObject(v, namespace)
+
+	default:
+		return nil, fmt.Errorf("invalid type %v", v)
+	}
+}
+
+func (p *avroParser) parseObject(v map[string]interface{}, namespace string) (*avroType, error) {
+	typ, _ := v["type"].(string)
+	name, _ := v["name"].(string)
+	if ns, ok := v["namespace"].(string); ok {
+		namespace = ns
+	}
+
+	switch typ {
+	case "record":
+		t := &avroType{kind: avroRecord}
+		if err := p.define(t, name, namespace); err != nil {
+			return nil, err
+		}
+		if i := strings.LastIndex(t.name, "."); i >= 0 {
+			namespace = t.name[:i]
+		}
+
+		fields, _ := v["fields"].([]interface{})
+		for _, f := range fields {
+			fv, ok := f.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("record %s has an invalid field", t.name)
+			}
+			fname, _ := fv["name"].(string)
+			if fname == "" {
+				return nil, fmt.Errorf("record %s has a field without a name", t.name)
+			}
+			ftyp, err := p.parse(fv["type"], namespace)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %v", t.name, fname, err)
+			}
+			def, hasDefault := fv["default"]
+			t.fields = append(t.fields, avroField{
+				name:       fname,
+				typ:        ftyp,
+				def:        def,
+				hasDefault: hasDefault,
+			})
+		}
+		return t, nil
+
+	case "fixed":
+		size, ok := v["size"].(float64)
+		if !ok || size < 0 || size != math.Trunc(size) {
+			return nil, fmt.Errorf("fixed type %s has an invalid size", name)
+		}
+		t := &avroType{
+			kind: avroFixed,
+			size: int(size),
+		}
+		if err := p.define(t, name, namespace); err != nil {
+			return nil, err
+		}
+		return t, nil
+
+	case "array":
+		items, err := p.parse(v["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroType{
+			kind:  avroArray,
+			items: items,
+		}, nil
+
+	default:
+		if kind, ok := avroPrimitives[typ]; ok {
+			return &avroType{kind: kind}, nil
+		}
+		return nil, fmt.Errorf("unsupported type %v", v["type"])
+	}
+}
+
+/* encoding */
+
+// Encode appends v encoded with the schema to buf
+func (s *AvroSchema) Encode(buf []byte, v interface{}) ([]byte, error) {
+	return avroEncode(buf, s.root, reflect.ValueOf(v))
+}
+
+// avroValue dereferences interfaces and pointers. A nil value is returned as the invalid reflect.Value.
+func avroValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isByteArray(t reflect.Type) bool {
+	return (t.Kind() == reflect.Array || t.Kind() == reflect.Slice) && t.Elem().Kind() == reflect.Uint8
+}
+
+// avroInteger returns the value of an integer, or of an integral float such as a JSON default value
+func avroInteger(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	default:
+		return 0, false
+	}
+}
+
+// avroMatches reports whether v can be encoded as t, for choosing the branch of a union
+func avroMatches(t *avroType, v reflect.Value) bool {
+	if !v.IsValid() {
+		return t.kind == avroNull
+	}
+
+	switch t.kind {
+	case avroBoolean:
+		return v.Kind() == reflect.Bool
+	case avroInt, avroLong:
+		_, ok := avroInteger(v)
+		return ok
+	case avroBytes:
+		return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+	case avroString:
+		return v.Kind() == reflect.String
+	case avroFixed:
+		return isByteArray(v.Type()) && v.Len() == t.size
+	case avroRecord:
+		return v.Kind() == reflect.Struct || v.Kind() == reflect.Map
+	case avroArray:
+		return (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && !isByteArray(v.Type())
+	default:
+		return false
+	}
+}
+
+func avroEncode(buf []byte, t *avroType, v reflect.Value) ([]byte, error) {
+	v = avroValue(v)
+
+	if t.kind == avroUnion {
+		for i, b := range t.branches {
+			if avroMatches(b, v) {
+				buf = appendVarint(buf, int64(i))
+				return avroEncode(buf, b, v)
+			}
+		}
+		return nil, fmt.Errorf("value of type %v matches no branch of the union", v.Type())
+	}
+
+	if !v.IsValid() {
+		if t.kind == avroNull {
+			return buf, nil
+		}
+		return nil, fmt.Errorf("nil value for type %s", t)
+	}
+
+	switch t.kind {
+	case avroNull:
+		return buf, nil
+
+	case avroBoolean:
+		if v.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("cannot encode %v as boolean", v.Type())
+		}
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+
+	case avroInt, avroLong:
+		x, ok := avroInteger(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot encode %v as %s", v.Type(), t)
+		}
+		if t.kind == avroInt && x != int64(int32(x)) {
+			return nil, fmt.Errorf("%d overflows int", x)
+		}
+		return appendVarint(buf, x), nil
+
+	case avroBytes, avroString:
+		var b []byte
+		switch {
+		case t.kind == avroString && v.Kind() == reflect.String:
+			b = []byte(v.String())
+		case t.kind == avroBytes && v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			b = v.Bytes()
+		default:
+			return nil, fmt.Errorf("cannot encode %v as %s", v.Type(), t)
+		}
+		buf = appendVarint(buf, int64(len(b)))
+		return append(buf, b...), nil
+
+	case avroFixed:
+		if !isByteArray(v.Type()) || v.Len() != t.size {
+			return nil, fmt.Errorf("cannot encode %v as %s", v.Type(), t)
+		}
+		for i := 0; i < t.size; i++ {
+			buf = append(buf, byte(v.Index(i).Uint()))
+		}
+		return buf, nil
+
+	case avroRecord:
+		for _, f := range t.fields {
+			var fv reflect.Value
+			switch v.Kind() {
+			case reflect.Struct:
+				fv = v.FieldByName(f.name)
+			case reflect.Map:
+				fv = v.MapIndex(reflect.ValueOf(f.name))
+			default:
+				return nil, fmt.Errorf("cannot encode %v as %s", v.Type(), t)
+			}
+
+			if !fv.IsValid() {
+				if !f.hasDefault {
+					return nil, fmt.Errorf("%s.%s has no value and no default", t, f.name)
+				}
+				fv = reflect.ValueOf(f.def)
+			}
+
+			var err error
+			if buf, err = avroEncode(buf, f.typ, fv); err != nil {
+				return nil, fmt.Errorf("%s.%s: %v", t, f.name, err)
+			}
+		}
+		return buf, nil
+
+	case avroArray:
+		if !avroMatches(t, v) {
+			return nil, fmt.Errorf("cannot encode %v as array", v.Type())
+		}
+		if n := v.Len(); n != 0 {
+			buf = appendVarint(buf, int64(n))
+			for i := 0; i < n; i++ {
+				var err error
+				if buf, err = avroEncode(buf, t.items, v.Index(i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return appendVarint(buf, 0), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+/* resolution */
+
+// AvroResolver decodes data written with one schema into values of a reader schema
+type AvroResolver struct {
+	writer *AvroSchema
+	reader *AvroSchema
+	// defaults are the encoded default values of reader fields which the writer does not have,
+	// by reader record and field name
+	defaults map[*avroType]map[string][]byte
+}
+
+// NewAvroResolver returns an AvroResolver for data written with writer and read with reader.
+// It fails if the schemas are incompatible, for example if the reader has a field
+// which the writer does not have and which has no default.
+func NewAvroResolver(writer, reader *AvroSchema) (*AvroResolver, error) {
+	r := &AvroResolver{
+		writer:   writer,
+		reader:   reader,
+		defaults: make(map[*avroType]map[string][]byte),
+	}
+	if err := r.check(writer.root, reader.root, make(map[[2]*avroType]bool)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func mustNewAvroResolver(writer, reader *AvroSchema) *AvroResolver {
+	r, err := NewAvroResolver(writer, reader)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// avroPromotes reports whether a value written as w can be read as r, ignoring unions
+func avroPromotes(w, r *avroType) bool {
+	switch {
+	case w.kind == r.kind:
+		return w.kind != avroRecord && w.kind != avroFixed || w.name == r.name
+	case w.kind == avroInt && r.kind == avroLong:
+		return true
+	case w.kind == avroBytes && r.kind == avroString, w.kind == avroString && r.kind == avroBytes:
+		return true
+	default:
+		return false
+	}
+}
+
+// avroBranch returns the index of the first branch of the reader union r which w can be read as, or -1
+func avroBranch(w, r *avroType) int {
+	for i, b := range r.branches {
+		if avroPromotes(w, b) {
+			return i
+		}
+	}
+	return -1
+}
+
+// check checks that data written as w can be read as r, and encodes the defaults of the reader's added fields
+func (r *AvroResolver) check(w, rt *avroType, seen map[[2]*avroType]bool) error {
+	if seen[[2]*avroType{w, rt}] {
+		return nil
+	}
+	seen[[2]*avroType{w, rt}] = true
+
+	if w.kind == avroUnion {
+		for _, b := range w.branches {
+			if err := r.check(b, rt, seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if rt.kind == avroUnion {
+		i := avroBranch(w, rt)
+		if i < 0 {
+			return fmt.Errorf("writer type %s matches no branch of the reader union", w)
+		}
+		return r.check(w, rt.branches[i], seen)
+	}
+
+	if !avroPromotes(w, rt) {
+		return fmt.Errorf("writer type %s can not be read as %s", w, rt)
+	}
+
+	switch w.kind {
+	case avroArray:
+		return r.check(w.items, rt.items, seen)
+
+	case avroFixed:
+		if w.size != rt.size {
+			return fmt.Errorf("fixed type %s has size %d, the reader expects %d", w, w.size, rt.size)
+		}
+
+	case avroRecord:
+		for _, rf := range rt.fields {
+			wf, ok := avroFieldByName(w, rf.name)
+			if ok {
+				if err := r.check(wf.typ, rf.typ, seen); err != nil {
+					return fmt.Errorf("%s.%s: %v", rt, rf.name, err)
+				}
+				continue
+			}
+
+			if !rf.hasDefault {
+				return fmt.Errorf("reader field %s.%s is not written and has no default", rt, rf.name)
+			}
+			def, err := avroEncodeDefault(rf)
+			if err != nil {
+				return fmt.Errorf("default of %s.%s: %v", rt, rf.name, err)
+			}
+			if r.defaults[rt] == nil {
+				r.defaults[rt] = make(map[string][]byte)
+			}
+			r.defaults[rt][rf.name] = def
+		}
+	}
+
+	return nil
+}
+
+// avroEncodeDefault encodes the default value of a field. The default of a union is a value of its first branch.
+func avroEncodeDefault(f avroField) ([]byte, error) {
+	typ := f.typ
+	if typ.kind == avroUnion {
+		typ = typ.branches[0]
+	}
+	def := f.def
+	if s, ok := def.(string); ok && (typ.kind == avroBytes || typ.kind == avroFixed) {
+		// Default bytes are a JSON string of code points 0-255
+		b := make([]byte, 0, len(s))
+		for _, c := range s {
+			b = append(b, byte(c))
+		}
+		if typ.kind == avroFixed {
+			if len(b) != typ.size {
+				return nil, fmt.Errorf("default has %d bytes, expected %d", len(b), typ.size)
+			}
+			return b, nil
+		}
+		def = b
+	}
+	return avroEncode(nil, typ, reflect.ValueOf(def))
+}
+
+func avroFieldByName(t *avroType, name string) (avroField, bool) {
+	for _, f := range t.fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return avroField{}, false
+}
+
+/* decoding */
+
+// Decode decodes buf, which must contain exactly one value, into v, which must be a pointer.
+// A record is decoded into a struct's fields by name, and fields of either which the other does not have are skipped.
+// A value decoded into an interface{} is a map[string]interface{}, []interface{}, int32, int64, bool,
+// []byte, string or nil. limits bound the arrays of coin.SignedBlock by field name.
+// Errors are a *DecodeError.
+func (r *AvroResolver) Decode(buf []byte, v interface{}, limits DecodeLimits) (int, error) {
+	if err := checkCommonLimits(buf, limits); err != nil {
+		return 0, err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return 0, &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: -1,
+			Err:    fmt.Errorf("cannot decode into %T", v),
+		}
+	}
+
+	d := &avroDecoder{
+		limitScanner: newLimitScanner(wireLayout{}, buf),
+		resolver:     r,
+		limits:       limits,
+	}
+	if err := d.decode(r.writer.root, r.reader.root, rv.Elem(), ""); err != nil {
+		return 0, err
+	}
+	if err := d.end(); err != nil {
+		return 0, err
+	}
+
+	return len(buf), nil
+}
+
+type avroDecoder struct {
+	limitScanner
+	resolver *AvroResolver
+	limits   DecodeLimits
+}
+
+// decode reads a value written as w into v as the reader type rt. An invalid v discards the value.
+func (d *avroDecoder) decode(w, rt *avroType, v reflect.Value, field string) error {
+	if w.kind == avroUnion {
+		offset := d.offset()
+		i, err := d.varint(field)
+		if err != nil {
+			return err
+		}
+		if i < 0 || i >= int64(len(w.branches)) {
+			return d.fail(ErrMalformed, offset, field, fmt.Errorf("union branch %d out of range", i))
+		}
+		return d.decode(w.branches[i], rt, v, field)
+	}
+
+	if rt.kind == avroUnion {
+		rt = rt.branches[avroBranch(w, rt)]
+	}
+
+	if v.IsValid() {
+		switch {
+		case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+			// Decode a generic value. A null is decoded as nil.
+			typ := avroGenericType(rt)
+			if typ == nil {
+				v.Set(reflect.Zero(v.Type()))
+				return d.decode(w, rt, reflect.Value{}, field)
+			}
+			g := reflect.New(typ).Elem()
+			if err := d.decode(w, rt, g, field); err != nil {
+				return err
+			}
+			v.Set(g)
+			return nil
+
+		case v.Kind() == reflect.Ptr:
+			if rt.kind == avroNull {
+				v.Set(reflect.Zero(v.Type()))
+				return nil
+			}
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			return d.decode(w, rt, v.Elem(), field)
+		}
+	}
+
+	switch w.kind {
+	case avroNull:
+		if v.IsValid() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+
+	case avroBoolean:
+		b, err := d.take(1, field)
+		if err != nil {
+			return err
+		}
+		if b[0] > 1 {
+			return d.fail(ErrMalformed, d.offset()-1, field, fmt.Errorf("invalid boolean %d", b[0]))
+		}
+		return d.set(v, reflect.ValueOf(b[0] == 1), field)
+
+	case avroInt, avroLong:
+		offset := d.offset()
+		x, err := d.varint(field)
+		if err != nil {
+			return err
+		}
+		if w.kind == avroInt && x != int64(int32(x)) {
+			return d.fail(ErrMalformed, offset, field, fmt.Errorf("%d overflows int", x))
+		}
+		if !v.IsValid() {
+			return nil
+		}
+		return d.setInteger(v, x, rt.kind, offset, field)
+
+	case avroBytes, avroString:
+		offset := d.offset()
+		n, err := d.varint(field)
+		if err != nil {
+			return err
+		}
+		if n < 0 || uint64(n) > uint64(len(d.buf)) {
+			return d.fail(ErrTruncated, offset, field, fmt.Errorf("length %d exceeds the remaining %d bytes", n, len(d.buf)))
+		}
+		b, _ := d.take(int(n), field)
+		if rt.kind == avroString {
+			return d.set(v, reflect.ValueOf(string(b)), field)
+		}
+		return d.set(v, reflect.ValueOf(append([]byte(nil), b...)), field)
+
+	case avroFixed:
+		b, err := d.take(w.size, field)
+		if err != nil {
+			return err
+		}
+		if !v.IsValid() {
+			return nil
+		}
+		switch {
+		case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 && v.Len() == w.size:
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes(append([]byte(nil), b...))
+			return nil
+		default:
+			return d.fail(ErrMalformed, d.offset()-w.size, field, fmt.Errorf("cannot decode %s into %v", w, v.Type()))
+		}
+
+	case avroRecord:
+		return d.record(w, rt, v, field)
+
+	case avroArray:
+		return d.array(w, rt, v, field)
+
+	default:
+		return d.fail(ErrMalformed, d.offset(), field, fmt.Errorf("unsupported type %s", w))
+	}
+}
+
+// avroGenericType returns the Go type of a generic value of the reader type rt, or nil for null
+func avroGenericType(rt *avroType) reflect.Type {
+	switch rt.kind {
+	case avroBoolean:
+		return reflect.TypeOf(false)
+	case avroInt:
+		return reflect.TypeOf(int32(0))
+	case avroLong:
+		return reflect.TypeOf(int64(0))
+	case avroBytes, avroFixed:
+		return reflect.TypeOf([]byte(nil))
+	case avroString:
+		return reflect.TypeOf("")
+	case avroRecord:
+		return reflect.TypeOf(map[string]interface{}(nil))
+	case avroArray:
+		return reflect.TypeOf([]interface{}(nil))
+	default:
+		return nil
+	}
+}
+
+// set assigns x to v, unless v is invalid
+func (d *avroDecoder) set(v, x reflect.Value, field string) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if !x.Type().AssignableTo(v.Type()) {
+		if x.Type().ConvertibleTo(v.Type()) && x.Kind() == v.Kind() {
+			x = x.Convert(v.Type())
+		} else {
+			return d.fail(ErrMalformed, d.offset(), field, fmt.Errorf("cannot decode %v into %v", x.Type(), v.Type()))
+		}
+	}
+	v.Set(x)
+	return nil
+}
+
+func (d *avroDecoder) setInteger(v reflect.Value, x int64, kind avroKind, offset int, field string) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(x) {
+			return d.fail(ErrMalformed, offset, field, fmt.Errorf("%d overflows %v", x, v.Type()))
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		// uint64 values are stored as their two's complement
+		u := uint64(x)
+		if v.Kind() != reflect.Uint64 && (x < 0 || v.OverflowUint(u)) {
+			return d.fail(ErrMalformed, offset, field, fmt.Errorf("%d overflows %v", x, v.Type()))
+		}
+		v.SetUint(u)
+	default:
+		if kind == avroInt {
+			return d.set(v, reflect.ValueOf(int32(x)), field)
+		}
+		return d.set(v, reflect.ValueOf(x), field)
+	}
+	return nil
+}
+
+func (d *avroDecoder) record(w, rt *avroType, v reflect.Value, field string) error {
+	if field != "" {
+		d.enter(field)
+		defer d.leave()
+	}
+
+	if v.IsValid() {
+		switch v.Kind() {
+		case reflect.Struct:
+		case reflect.Map:
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+		default:
+			return d.fail(ErrMalformed, d.offset(), "", fmt.Errorf("cannot decode %s into %v", w, v.Type()))
+		}
+	}
+
+	target := func(name string) (reflect.Value, func()) {
+		if !v.IsValid() {
+			return reflect.Value{}, func() {}
+		}
+		if v.Kind() == reflect.Struct {
+			return v.FieldByName(name), func() {}
+		}
+		fv := reflect.New(v.Type().Elem()).Elem()
+		return fv, func() {
+			v.SetMapIndex(reflect.ValueOf(name), fv)
+		}
+	}
+
+	for _, wf := range w.fields {
+		rf, ok := avroFieldByName(rt, wf.name)
+		if !ok {
+			// The reader does not have the field
+			if err := d.decode(wf.typ, wf.typ, reflect.Value{}, wf.name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fv, store := target(wf.name)
+		if err := d.decode(wf.typ, rf.typ, fv, wf.name); err != nil {
+			return err
+		}
+		store()
+	}
+
+	// Set the defaults of the fields which the writer does not have
+	for name, def := range d.resolver.defaults[rt] {
+		if _, ok := avroFieldByName(w, name); ok {
+			continue
+		}
+		rf, _ := avroFieldByName(rt, name)
+		typ := rf.typ
+		if typ.kind == avroUnion {
+			typ = typ.branches[0]
+		}
+
+		fv, store := target(name)
+		dd := &avroDecoder{
+			limitScanner: newLimitScanner(wireLayout{}, def),
+			resolver:     d.resolver,
+			limits:       d.limits,
+		}
+		if err := dd.decode(typ, rf.typ, fv, name); err != nil {
+			return err
+		}
+		store()
+	}
+
+	return nil
+}
+
+func (d *avroDecoder) array(w, rt *avroType, v reflect.Value, field string) error {
+	if v.IsValid() {
+		switch {
+		case v.Kind() == reflect.Slice && !isByteArray(v.Type()):
+			v.Set(v.Slice(0, 0))
+		default:
+			return d.fail(ErrMalformed, d.offset(), field, fmt.Errorf("cannot decode %s into %v", w, v.Type()))
+		}
+	}
+
+	limit := avroArrayLimits[field]
+	max := 0
+	if limit != "" {
+		max = d.limits.max(limit)
+	}
+
+	d.enter(field)
+	defer d.leave()
+
+	var n uint64
+	for {
+		offset := d.offset()
+		count, err := d.varint("")
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			break
+		}
+		if count < 0 {
+			// A negative count is followed by the size of the block in bytes
+			if count == math.MinInt64 {
+				return d.fail(ErrMalformed, offset, "", errors.New("invalid block count"))
+			}
+			count = -count
+			if _, err := d.varint(""); err != nil {
+				return err
+			}
+		}
+
+		if max > 0 && n+uint64(count) > uint64(max) {
+			return checkLimit(limit, max, n+uint64(count), offset, d.fieldPath(""))
+		}
+		// Every item of the supported schemas takes at least one byte
+		if uint64(count) > uint64(len(d.buf)) {
+			return d.fail(ErrTruncated, offset, "", fmt.Errorf("count %d exceeds the remaining %d bytes", count, len(d.buf)))
+		}
+
+		// The capacity grows geometrically, so that an array of many small blocks is not copied for every block.
+		// The first block always allocates, so that the items are not decoded into a slice held by the caller.
+		if v.IsValid() {
+			length := int(n) + int(count)
+			if n == 0 || length > v.Cap() {
+				size := 2 * v.Cap()
+				if n == 0 || size < length {
+					size = length
+				}
+				grown := reflect.MakeSlice(v.Type(), int(n), size)
+				reflect.Copy(grown, v)
+				v.Set(grown)
+			}
+			v.Set(v.Slice(0, length))
+		}
+
+		for i := uint64(0); i < uint64(count); i++ {
+			d.at(n)
+			var item reflect.Value
+			if v.IsValid() {
+				item = v.Index(int(n))
+			}
+			if err := d.decode(w.items, rt.items, item, ""); err != nil {
+				return err
+			}
+			n++
+		}
+	}
+
+	if v.IsValid() && n == 0 {
+		v.Set(reflect.Zero(v.Type()))
+	}
+
+	return nil
+}
+
+func encodeAvro(obj *coin.SignedBlock) ([]byte, error) {
+	return avroBlockSchema.Encode(nil, obj)
+}
+
+func decodeAvro(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	*obj = coin.SignedBlock{}
+	return avroBlockResolver.Decode(buf, obj, limits)
+}
@@ -0,0 +1,259 @@
+package serializebench
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// avroVariant returns block.avsc with old replaced by new, which must occur once
+func avroVariant(t *testing.T, old, new string) *AvroSchema {
+	t.Helper()
+
+	if n := bytes.Count(avroBlockSchemaJSON, []byte(old)); n != 1 {
+		t.Fatalf("%q occurs %d times in block.avsc", old, n)
+	}
+	s, err := ParseAvroSchema(bytes.Replace(avroBlockSchemaJSON, []byte(old), []byte(new), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+const avroLengthField = `{"name": "Length", "type": "long"},`
+
+// avroMemoSchema adds an optional Memo field to Transaction
+func avroMemoSchema(t *testing.T) *AvroSchema {
+	return avroVariant(t, avroLengthField, avroLengthField+`{"name": "Memo", "type": ["null", "string"], "default": null},`)
+}
+
+func mustResolver(t *testing.T, writer, reader *AvroSchema) *AvroResolver {
+	t.Helper()
+	r, err := NewAvroResolver(writer, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestAvroResolveAddedField(t *testing.T) {
+	block := getBlock()
+	data, err := avroBlockSchema.Encode(nil, &block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := mustResolver(t, avroBlockSchema, avroMemoSchema(t))
+
+	// The reader's generic value has the default Memo
+	var generic interface{}
+	if _, err := r.Decode(data, &generic, DefaultDecodeLimits); err != nil {
+		t.Fatal(err)
+	}
+	txns := generic.(map[string]interface{})["Block"].(map[string]interface{})["Body"].(map[string]interface{})["Transactions"].([]interface{})
+	if len(txns) != len(block.Block.Body.Transactions) {
+		t.Fatalf("expected %d transactions, got %d", len(block.Block.Body.Transactions), len(txns))
+	}
+	for i, txn := range txns {
+		txn := txn.(map[string]interface{})
+		if memo, ok := txn["Memo"]; !ok || memo != nil {
+			t.Errorf("transaction %d: expected a nil Memo, got %v", i, memo)
+		}
+		if length := txn["Length"]; length != int64(block.Block.Body.Transactions[i].Length) {
+			t.Errorf("transaction %d: expected Length %d, got %v", i, block.Block.Body.Transactions[i].Length, length)
+		}
+	}
+
+	// A struct without the field ignores it
+	var result coin.SignedBlock
+	if _, err := r.Decode(data, &result, DefaultDecodeLimits); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(block, result) {
+		t.Error(cmp.Diff(block, result))
+	}
+}
+
+func TestAvroResolveRemovedField(t *testing.T) {
+	block := getBlock()
+	memoSchema := avroMemoSchema(t)
+
+	// Write the block with memos in the new schema
+	data, err := avroBlockSchema.Encode(nil, &block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generic interface{}
+	if _, err := mustResolver(t, avroBlockSchema, memoSchema).Decode(data, &generic, DefaultDecodeLimits); err != nil {
+		t.Fatal(err)
+	}
+	txns := generic.(map[string]interface{})["Block"].(map[string]interface{})["Body"].(map[string]interface{})["Transactions"].([]interface{})
+	for _, txn := range txns {
+		txn.(map[string]interface{})["Memo"] = "memo"
+	}
+	newData, err := memoSchema.Encode(nil, generic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newData) != len(data)+len(txns)*len("\x02\x08memo") {
+		t.Errorf("expected the memos to add %d bytes, got %d", len(txns)*6, len(newData)-len(data))
+	}
+
+	// An old reader skips the memos
+	var result coin.SignedBlock
+	if _, err := mustResolver(t, memoSchema, avroBlockSchema).Decode(newData, &result, DefaultDecodeLimits); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(block, result) {
+		t.Error(cmp.Diff(block, result))
+	}
+
+	// A new reader reads them
+	var memos struct {
+		Block struct {
+			Body struct {
+				Transactions []struct {
+					Memo *string
+				}
+			}
+		}
+	}
+	if _, err := mustResolver(t, memoSchema, memoSchema).Decode(newData, &memos, DefaultDecodeLimits); err != nil {
+		t.Fatal(err)
+	}
+	for i, txn := range memos.Block.Body.Transactions {
+		if txn.Memo == nil || *txn.Memo != "memo" {
+			t.Errorf("transaction %d: unexpected memo %v", i, txn.Memo)
+		}
+	}
+}
+
+func TestAvroResolvePromotion(t *testing.T) {
+	block := getBlock()
+	data, err := avroBlockSchema.Encode(nil, &block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Type is written as an int and read as a long
+	reader := avroVariant(t, `{"name": "Type", "type": "int"}`, `{"name": "Type", "type": "long"}`)
+
+	var result coin.SignedBlock
+	if _, err := mustResolver(t, avroBlockSchema, reader).Decode(data, &result, DefaultDecodeLimits); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(block, result) {
+		t.Error(cmp.Diff(block, result))
+	}
+}
+
+func TestAvroResolveIncompatible(t *testing.T) {
+	cases := []struct {
+		name     string
+		old, new string
+	}{
+		{
+			name: "added field without default",
+			old:  avroLengthField,
+			new:  avroLengthField + `{"name": "Memo", "type": "string"},`,
+		},
+		{
+			name: "long read as int",
+			old:  `{"name": "Time", "type": "long"}`,
+			new:  `{"name": "Time", "type": "int"}`,
+		},
+		{
+			name: "fixed size",
+			old:  `{"type": "fixed", "name": "SHA256", "size": 32}`,
+			new:  `{"type": "fixed", "name": "SHA256", "size": 20}`,
+		},
+		{
+			name: "renamed record",
+			old:  `"name": "TransactionOutput"`,
+			new:  `"name": "Output"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := avroVariant(t, tc.old, tc.new)
+			if _, err := NewAvroResolver(avroBlockSchema, reader); err == nil {
+				t.Error("expected the schemas to be incompatible")
+			}
+		})
+	}
+}
+
+func TestAvroArrayBlocks(t *testing.T) {
+	schema, err := ParseAvroSchema([]byte(`{"type": "array", "items": "long"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := mustResolver(t, schema, schema)
+
+	// A block of 1 item, then a block of -2 items followed by its size in bytes, then the end of the array
+	data := []byte{2, 2, 3, 4, 4, 6, 0}
+
+	var result []int64
+	n, err := r.Decode(data, &result, DefaultDecodeLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(data) {
+		t.Errorf("read %d bytes of %d", n, len(data))
+	}
+	if !cmp.Equal([]int64{1, 2, 3}, result) {
+		t.Error(cmp.Diff([]int64{1, 2, 3}, result))
+	}
+
+	// Decoding needs a pointer
+	if _, err := r.Decode(data, result, DefaultDecodeLimits); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed, got %v", err)
+	}
+
+	// An array of one item blocks is not copied for every block
+	const blocks = 1 << 14
+	data = data[:0]
+	for i := 0; i < blocks; i++ {
+		data = append(data, 2, 0)
+	}
+	data = append(data, 0)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = r.Decode(data, &result, DecodeLimits{})
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != blocks {
+		t.Errorf("decoded %d items, expected %d", len(result), blocks)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1024*1024 {
+		t.Errorf("allocated %d bytes for %d items", allocated, blocks)
+	}
+}
+
+func TestParseAvroSchemaErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown type":       `{"type": "record", "name": "A", "fields": [{"name": "x", "type": "B"}]}`,
+		"unsupported type":   `{"type": "map", "values": "long"}`,
+		"redefined type":     `[{"type": "fixed", "name": "A", "size": 1}, {"type": "fixed", "name": "A", "size": 2}]`,
+		"nested union":       `["null", ["long"]]`,
+		"fixed size":         `{"type": "fixed", "name": "A", "size": -1}`,
+		"unnamed record":     `{"type": "record", "fields": []}`,
+		"field without name": `{"type": "record", "name": "A", "fields": [{"type": "long"}]}`,
+	}
+
+	for name, schema := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseAvroSchema([]byte(schema)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
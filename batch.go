@@ -0,0 +1,117 @@
+package serializebench
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// DecodeBlockBatch decodes a batch of blocks encoded in one of ViewFormats, one block per element of data,
+// appending them to blocks. Rather than allocating the slices of each transaction separately, it counts
+// the elements of the whole batch first, then allocates one backing array each for the transactions,
+// signatures, inputs and outputs of the batch. The decoded slices are capped to their length, so appending
+// to one copies it rather than overwriting the next, but any block keeps the arrays of the whole batch alive.
+//
+// Blocks exceeding limits are rejected before anything is allocated. Errors are a *DecodeError with
+// the offset in the block and a path such as Blocks[1].Block.Head.
+func DecodeBlockBatch(format string, data [][]byte, blocks []coin.SignedBlock, limits DecodeLimits) ([]coin.SignedBlock, error) {
+	layout, ok := viewLayout(format)
+	if !ok {
+		return blocks, fmt.Errorf("batch decoding is not supported for format %q", format)
+	}
+	limits = viewLimits(layout, limits)
+
+	views := make([]BlockView, len(data))
+	var nTxns, nSigs, nIn, nOut int
+	for i := range data {
+		b, err := newBlockView(layout, data[i])
+		if err == nil {
+			err = checkViewLimits(b, data[i], limits)
+		}
+		if err != nil {
+			if e, ok := err.(*DecodeError); ok {
+				e.Path = joinPath(fmt.Sprintf("Blocks[%d]", i), e.Path)
+			}
+			return blocks, err
+		}
+		views[i] = b
+
+		nTxns += b.NumTransactions()
+		it := b.Transactions()
+		for it.Next() {
+			t := it.Transaction()
+			nSigs += t.NumSigs()
+			nIn += t.NumIn()
+			nOut += t.NumOut()
+		}
+	}
+
+	txns := make([]coin.Transaction, nTxns)
+	sigs := make([]cipher.Sig, nSigs)
+	in := make([]cipher.SHA256, nIn)
+	out := make([]coin.TransactionOutput, nOut)
+
+	for _, b := range views {
+		var obj coin.SignedBlock
+		obj.Block.Head = b.Head()
+		obj.Sig = b.Sig()
+
+		// Empty slices are left nil, as DecodeSignedBlock leaves them
+		if n := b.NumTransactions(); n != 0 {
+			obj.Block.Body.Transactions, txns = txns[:n:n], txns[n:]
+		}
+
+		it := b.Transactions()
+		for it.Next() {
+			t := it.Transaction()
+			txn := &obj.Block.Body.Transactions[it.Index()]
+			if n := t.NumSigs(); n != 0 {
+				txn.Sigs, sigs = sigs[:n:n], sigs[n:]
+			}
+			if n := t.NumIn(); n != 0 {
+				txn.In, in = in[:n:n], in[n:]
+			}
+			if n := t.NumOut(); n != 0 {
+				txn.Out, out = out[:n:n], out[n:]
+			}
+			t.copyTo(txn)
+		}
+
+		blocks = append(blocks, obj)
+	}
+
+	return blocks, nil
+}
+
+// checkViewLimits checks a block view of data against limits. Unlike checkLayoutLimits, it does not allocate
+// unless a limit is exceeded.
+func checkViewLimits(b BlockView, data []byte, limits DecodeLimits) error {
+	if err := checkCommonLimits(data, limits); err != nil {
+		return err
+	}
+	if err := checkLimit("MaxTransactions", limits.MaxTransactions, uint64(b.NumTransactions()), -1, "Block.Body.Transactions"); err != nil {
+		return err
+	}
+
+	it := b.Transactions()
+	for it.Next() {
+		t := it.Transaction()
+		for _, l := range [...]struct {
+			name  string
+			max   int
+			n     int
+			field string
+		}{
+			{"MaxSigs", limits.MaxSigs, t.NumSigs(), "Sigs"},
+			{"MaxInputs", limits.MaxInputs, t.NumIn(), "In"},
+			{"MaxOutputs", limits.MaxOutputs, t.NumOut(), "Out"},
+		} {
+			if l.max > 0 && l.n > l.max {
+				return checkLimit(l.name, l.max, uint64(l.n), -1, fmt.Sprintf("Block.Body.Transactions[%d].%s", it.Index(), l.field))
+			}
+		}
+	}
+
+	return nil
+}
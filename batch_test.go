@@ -0,0 +1,178 @@
+package serializebench
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// encodeBlocks encodes each of blocks with the named codec
+func encodeBlocks(tb testing.TB, format string, blocks []coin.SignedBlock) (Codec, [][]byte) {
+	c, err := CodecByName(format)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	data := make([][]byte, len(blocks))
+	for i := range blocks {
+		if data[i], err = c.Encode(&blocks[i]); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return c, data
+}
+
+func TestDecodeBlockBatch(t *testing.T) {
+	blocks := append([]coin.SignedBlock{getBlock()}, GenerateChain(50, 1)...)
+
+	for _, format := range ViewFormats {
+		t.Run(format, func(t *testing.T) {
+			c, data := encodeBlocks(t, format, blocks)
+
+			result, err := DecodeBlockBatch(format, data, nil, DefaultDecodeLimits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result) != len(blocks) {
+				t.Fatalf("expected %d blocks, got %d", len(blocks), len(result))
+			}
+			for i := range blocks {
+				var single coin.SignedBlock
+				if _, err := c.Decode(data[i], &single, DefaultDecodeLimits); err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(single, result[i]); diff != "" {
+					t.Errorf("block %d differs from Decode: %s", i, diff)
+				}
+			}
+
+			// Appending to a slice of one block does not overwrite the next block
+			sigs := result[2].Block.Body.Transactions[0].Sigs
+			_ = append(sigs, sigs[0])
+			txns := result[2].Block.Body.Transactions
+			_ = append(txns, txns[0])
+			if diff := diffBlocks(blocks[3], result[3]); diff != "" {
+				t.Errorf("appending to block 2 changed block 3: %s", diff)
+			}
+
+			// One allocation for the views and one for each backing array, whatever the size of the batch
+			allocs := testing.AllocsPerRun(10, func() {
+				if _, err := DecodeBlockBatch(format, data, result[:0], DefaultDecodeLimits); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if allocs != 5 {
+				t.Errorf("expected 5 allocations per batch, got %v", allocs)
+			}
+		})
+	}
+}
+
+func TestDecodeBlockBatchErrors(t *testing.T) {
+	blocks := []coin.SignedBlock{getBlock(), getBlock()}
+	_, data := encodeBlocks(t, "skyenc", blocks)
+
+	cases := []struct {
+		name   string
+		data   [][]byte
+		limits DecodeLimits
+		kind   error
+		path   string
+	}{
+		{"truncated", [][]byte{data[0], data[1][:10]}, DefaultDecodeLimits, ErrTruncated, "Blocks[1].Block.Head"},
+		{"trailing bytes", [][]byte{data[0], append(append([]byte(nil), data[1]...), 0)}, DefaultDecodeLimits, ErrTrailingBytes, "Blocks[1]"},
+		{"MaxTransactions", data, DecodeLimits{MaxTransactions: 1}, ErrLimitExceeded, "Blocks[0].Block.Body.Transactions"},
+		{"MaxSigs", data, DecodeLimits{MaxSigs: 1}, ErrLimitExceeded, "Blocks[0].Block.Body.Transactions[0].Sigs"},
+		{"MaxBytes", data, DecodeLimits{MaxBytes: 100}, ErrLimitExceeded, "Blocks[0]"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := DecodeBlockBatch("skyenc", tc.data, nil, tc.limits)
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) || !errors.Is(err, tc.kind) {
+				t.Fatalf("expected %v, got %v", tc.kind, err)
+			}
+			if decodeErr.Path != tc.path {
+				t.Errorf("expected path %q, got %q", tc.path, decodeErr.Path)
+			}
+			if len(result) != 0 {
+				t.Errorf("expected no blocks to be decoded, got %d", len(result))
+			}
+		})
+	}
+
+	if _, err := DecodeBlockBatch("json", data, nil, DefaultDecodeLimits); err == nil {
+		t.Error("expected an error for a format without batch decoding")
+	}
+}
+
+// BenchmarkDecodeBatch decodes storeBlocks blocks per op, as one batch with DecodeBlockBatch
+// and one block at a time with Codec.Decode, which for skyenc is DecodeSignedBlock.
+// The decoded blocks of the previous op are released as the next op decodes,
+// and gc-pause-ns/op is the garbage collector's stop-the-world pause time per op.
+func BenchmarkDecodeBatch(b *testing.B) {
+	blocks := GenerateChain(storeBlocks, 1)
+
+	for _, format := range []string{"skyenc", "gencode"} {
+		c, data := encodeBlocks(b, format, blocks)
+
+		var size int64
+		for _, d := range data {
+			size += int64(len(d))
+		}
+
+		decoders := []struct {
+			name   string
+			decode func(result []coin.SignedBlock) ([]coin.SignedBlock, error)
+		}{
+			{"batch", func(result []coin.SignedBlock) ([]coin.SignedBlock, error) {
+				return DecodeBlockBatch(format, data, result[:0], DefaultDecodeLimits)
+			}},
+			{"single", func(result []coin.SignedBlock) ([]coin.SignedBlock, error) {
+				result = result[:0]
+				for _, d := range data {
+					result = append(result, coin.SignedBlock{})
+					if _, err := c.Decode(d, &result[len(result)-1], DefaultDecodeLimits); err != nil {
+						return result, err
+					}
+				}
+				return result, nil
+			}},
+		}
+
+		for _, d := range decoders {
+			b.Run(format+"/"+d.name, func(b *testing.B) {
+				result := make([]coin.SignedBlock, 0, len(data))
+
+				runtime.GC()
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+
+				b.SetBytes(size)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					var err error
+					if result, err = d.decode(result); err != nil {
+						b.Fatal(err)
+					}
+
+					if validate {
+						if diffBlocks(blocks, result) != "" {
+							b.Fatalf("%s %s result differs", format, d.name)
+						}
+					}
+				}
+				b.StopTimer()
+
+				runtime.ReadMemStats(&after)
+				b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "gc-pause-ns/op")
+				b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gc/op")
+			})
+		}
+	}
+}
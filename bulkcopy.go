@@ -0,0 +1,170 @@
+package serializebench
+
+import (
+	"encoding/binary"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// outputSize is the size of a coin.TransactionOutput in the skyencoder layout. The struct is 40 bytes in memory,
+// as Coins is aligned to 8 bytes, so outputs can not be copied as one region like hashes and signatures.
+const outputSize = 1 + 20 + 8 + 8
+
+// DecodeSignedBlockBulk decodes a coin.SignedBlock encoded by EncodeSignedBlock, as DecodeSignedBlock does.
+// The Sigs and In of a transaction are stored contiguously, so each is bounds checked once and copied
+// by copySigs and copyHashes, rather than an element at a time. The outputs of a transaction are also bounds
+// checked once. With the unsafecopy build tag, the copies are single memmoves into the backing arrays.
+// On error, the returned offset may differ from DecodeSignedBlock's, as a truncated array fails at its start.
+func DecodeSignedBlockBulk(buf []byte, obj *coin.SignedBlock) (int, error) {
+	d := bulkDecoder{
+		buf: buf,
+	}
+	if err := d.signedBlock(obj); err != nil {
+		return d.i, err
+	}
+	return d.i, nil
+}
+
+// bulkDecoder reads the skyencoder layout with the errors of encoder.Decoder
+type bulkDecoder struct {
+	buf []byte
+	i   int
+}
+
+// next returns the next n bytes
+func (d *bulkDecoder) next(n int) ([]byte, error) {
+	if len(d.buf)-d.i < n {
+		return nil, encoder.ErrBufferUnderflow
+	}
+	b := d.buf[d.i : d.i+n]
+	d.i += n
+	return b, nil
+}
+
+func (d *bulkDecoder) uint32() (uint32, error) {
+	b, err := d.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (d *bulkDecoder) uint64() (uint64, error) {
+	b, err := d.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// length reads a list length, checked as the generated decoder checks it
+func (d *bulkDecoder) length() (int, error) {
+	ul, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	length := int(ul)
+	if length < 0 || length > len(d.buf)-d.i {
+		return 0, encoder.ErrBufferUnderflow
+	}
+	if length > maxLenSkyencoder {
+		return 0, encoder.ErrMaxLenExceeded
+	}
+	return length, nil
+}
+
+// array reads a list length and returns the region of its elements of size bytes each
+func (d *bulkDecoder) array(size int) (int, []byte, error) {
+	length, err := d.length()
+	if err != nil {
+		return 0, nil, err
+	}
+	b, err := d.next(length * size)
+	if err != nil {
+		return 0, nil, err
+	}
+	return length, b, nil
+}
+
+func (d *bulkDecoder) signedBlock(obj *coin.SignedBlock) error {
+	head := &obj.Block.Head
+	b, err := d.next(4 + 8 + 8 + 8 + 3*len(cipher.SHA256{}))
+	if err != nil {
+		return err
+	}
+	head.Version = binary.LittleEndian.Uint32(b)
+	head.Time = binary.LittleEndian.Uint64(b[4:])
+	head.BkSeq = binary.LittleEndian.Uint64(b[12:])
+	head.Fee = binary.LittleEndian.Uint64(b[20:])
+	copy(head.PrevHash[:], b[28:])
+	copy(head.BodyHash[:], b[60:])
+	copy(head.UxHash[:], b[92:])
+
+	length, err := d.length()
+	if err != nil {
+		return err
+	}
+	if length != 0 {
+		obj.Block.Body.Transactions = make(coin.Transactions, length)
+		for i := range obj.Block.Body.Transactions {
+			if err := d.transaction(&obj.Block.Body.Transactions[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	sig, err := d.next(len(obj.Sig))
+	if err != nil {
+		return err
+	}
+	copy(obj.Sig[:], sig)
+	return nil
+}
+
+func (d *bulkDecoder) transaction(obj *coin.Transaction) error {
+	b, err := d.next(4 + 1 + len(obj.InnerHash))
+	if err != nil {
+		return err
+	}
+	obj.Length = binary.LittleEndian.Uint32(b)
+	obj.Type = b[4]
+	copy(obj.InnerHash[:], b[5:])
+
+	n, b, err := d.array(len(cipher.Sig{}))
+	if err != nil {
+		return err
+	}
+	if n != 0 {
+		obj.Sigs = make([]cipher.Sig, n)
+		copySigs(obj.Sigs, b)
+	}
+
+	n, b, err = d.array(len(cipher.SHA256{}))
+	if err != nil {
+		return err
+	}
+	if n != 0 {
+		obj.In = make([]cipher.SHA256, n)
+		copyHashes(obj.In, b)
+	}
+
+	n, b, err = d.array(outputSize)
+	if err != nil {
+		return err
+	}
+	if n != 0 {
+		obj.Out = make([]coin.TransactionOutput, n)
+		for i := range obj.Out {
+			out := &obj.Out[i]
+			e := b[i*outputSize : (i+1)*outputSize]
+			out.Address.Version = e[0]
+			copy(out.Address.Key[:], e[1:21])
+			out.Coins = binary.LittleEndian.Uint64(e[21:29])
+			out.Hours = binary.LittleEndian.Uint64(e[29:37])
+		}
+	}
+
+	return nil
+}
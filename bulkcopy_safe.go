@@ -0,0 +1,26 @@
+//go:build !unsafecopy
+// +build !unsafecopy
+
+package serializebench
+
+import "github.com/skycoin/skycoin/src/cipher"
+
+// copySigs copies the signatures stored contiguously in src to dst, an element at a time.
+// src must hold len(dst) signatures. Reslicing src checks that before anything is copied,
+// but each element is still bounds checked as it is sliced.
+func copySigs(dst []cipher.Sig, src []byte) {
+	src = src[:len(dst)*len(cipher.Sig{})]
+	for i := range dst {
+		copy(dst[i][:], src[i*len(cipher.Sig{}):])
+	}
+}
+
+// copyHashes copies the hashes stored contiguously in src to dst, an element at a time.
+// src must hold len(dst) hashes. Reslicing src checks that before anything is copied,
+// but each element is still bounds checked as it is sliced.
+func copyHashes(dst []cipher.SHA256, src []byte) {
+	src = src[:len(dst)*len(cipher.SHA256{})]
+	for i := range dst {
+		copy(dst[i][:], src[i*len(cipher.SHA256{}):])
+	}
+}
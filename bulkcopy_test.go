@@ -0,0 +1,152 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// wideBlock returns a block with one transaction spending inputs outputs, with a signature per input
+func wideBlock(inputs int) coin.SignedBlock {
+	r := rand.New(rand.NewSource(int64(inputs)))
+	block := getBlock()
+	txn := &block.Block.Body.Transactions[0]
+	txn.Sigs = make([]cipher.Sig, inputs)
+	txn.In = make([]cipher.SHA256, inputs)
+	for i := 0; i < inputs; i++ {
+		r.Read(txn.Sigs[i][:])
+		r.Read(txn.In[i][:])
+	}
+	txn.Length = uint32(EncodeSizeTransaction(txn))
+	return block
+}
+
+func TestDecodeSignedBlockBulk(t *testing.T) {
+	blocks := append([]coin.SignedBlock{getBlock(), {}, wideBlock(500)}, GenerateChain(20, 1)...)
+
+	for i := range blocks {
+		data, err := encodeSkyencoder(&blocks[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var want coin.SignedBlock
+		if _, err := DecodeSignedBlock(data, &want); err != nil {
+			t.Fatal(err)
+		}
+		var got coin.SignedBlock
+		n, err := DecodeSignedBlockBulk(data, &got)
+		if err != nil {
+			t.Fatalf("block %d: %v", i, err)
+		}
+		if n != len(data) {
+			t.Errorf("block %d: read %d bytes of %d", i, n, len(data))
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("block %d decodes differently: %s", i, diff)
+		}
+
+		// Both decoders reject every truncation. The wide block is only truncated within its header,
+		// as each truncation decodes the whole payload.
+		end := len(data)
+		if end > 4096 {
+			end = 256
+		}
+		for j := 0; j < end; j++ {
+			if _, err := DecodeSignedBlock(data[:j], &coin.SignedBlock{}); err == nil {
+				continue
+			}
+			if _, err := DecodeSignedBlockBulk(data[:j], &coin.SignedBlock{}); err != encoder.ErrBufferUnderflow {
+				t.Fatalf("block %d truncated to %d bytes: expected encoder.ErrBufferUnderflow, got %v", i, j, err)
+			}
+		}
+	}
+}
+
+func TestDecodeSignedBlockBulkMaxLen(t *testing.T) {
+	block := getBlock()
+	data, err := encodeSkyencoder(&block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The number of signatures of the first transaction follows the header, the transaction count,
+	// the transaction's Length, Type and InnerHash
+	offset := 4 + 8 + 8 + 8 + 3*32 + 4 + 4 + 1 + 32
+	binary.LittleEndian.PutUint32(data[offset:], maxLenSkyencoder+1)
+	data = append(data, make([]byte, (maxLenSkyencoder+1)*65)...)
+
+	if _, err := DecodeSignedBlock(data, &coin.SignedBlock{}); err != encoder.ErrMaxLenExceeded {
+		t.Fatalf("expected DecodeSignedBlock to fail with encoder.ErrMaxLenExceeded, got %v", err)
+	}
+	if _, err := DecodeSignedBlockBulk(data, &coin.SignedBlock{}); err != encoder.ErrMaxLenExceeded {
+		t.Errorf("expected encoder.ErrMaxLenExceeded, got %v", err)
+	}
+}
+
+func TestBulkCopyEmpty(t *testing.T) {
+	// An empty dst copies nothing, with or without the unsafecopy build tag
+	copySigs(nil, nil)
+	copySigs([]cipher.Sig{}, make([]byte, len(cipher.Sig{})))
+	copyHashes(nil, nil)
+	copyHashes([]cipher.SHA256{}, make([]byte, len(cipher.SHA256{})))
+}
+
+// BenchmarkDecodeSignedBlockBulk compares DecodeSignedBlockBulk to the generated DecodeSignedBlock on the
+// generated chain and on blocks with one transaction of hundreds of inputs.
+// Run it with -tags unsafecopy for the unsafe.Slice copies.
+func BenchmarkDecodeSignedBlockBulk(b *testing.B) {
+	sources := []struct {
+		name   string
+		blocks []coin.SignedBlock
+	}{
+		{"chain", GenerateChain(storeBlocks, 1)},
+		{"inputs-100", []coin.SignedBlock{wideBlock(100)}},
+		{"inputs-500", []coin.SignedBlock{wideBlock(500)}},
+	}
+
+	decoders := []struct {
+		name   string
+		decode func([]byte, *coin.SignedBlock) (int, error)
+	}{
+		{"generated", DecodeSignedBlock},
+		{"bulk", DecodeSignedBlockBulk},
+	}
+
+	for _, src := range sources {
+		data := make([][]byte, len(src.blocks))
+		var size int
+		for i := range src.blocks {
+			var err error
+			if data[i], err = encodeSkyencoder(&src.blocks[i]); err != nil {
+				b.Fatal(err)
+			}
+			size += len(data[i])
+		}
+
+		for _, d := range decoders {
+			b.Run(src.name+"/"+d.name, func(b *testing.B) {
+				b.SetBytes(int64(size / len(data)))
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					var result coin.SignedBlock
+					if _, err := d.decode(data[i%len(data)], &result); err != nil {
+						b.Fatal(err)
+					}
+
+					if validate {
+						if !cmp.Equal(src.blocks[i%len(data)], result) {
+							b.Fatalf("%s decode result differs", d.name)
+						}
+					}
+				}
+			})
+		}
+	}
+}
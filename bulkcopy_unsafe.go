@@ -0,0 +1,27 @@
+//go:build unsafecopy
+// +build unsafecopy
+
+package serializebench
+
+import (
+	"unsafe"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// copySigs copies the signatures stored contiguously in src to dst, whose backing array has the same layout,
+// in one copy. src must hold len(dst) signatures.
+func copySigs(dst []cipher.Sig, src []byte) {
+	if len(dst) == 0 {
+		return
+	}
+	copy(unsafe.Slice(&dst[0][0], len(dst)*len(cipher.Sig{})), src)
+}
+
+// copyHashes copies the hashes stored contiguously in src to dst in one copy. src must hold len(dst) hashes.
+func copyHashes(dst []cipher.SHA256, src []byte) {
+	if len(dst) == 0 {
+		return
+	}
+	copy(unsafe.Slice(&dst[0][0], len(dst)*len(cipher.SHA256{})), src)
+}
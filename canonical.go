@@ -0,0 +1,370 @@
+package serializebench
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// Changes made to an encoded block by CheckCanonical
+const (
+	// ChangeTrailingByte appends a zero byte
+	ChangeTrailingByte = "trailing byte"
+	// ChangeNonMinimalVarint adds a redundant continuation byte to a varint
+	ChangeNonMinimalVarint = "non-minimal varint"
+	// ChangeOverflowingVarint adds 1<<33 to a varint integer, which is truncated by a decoder
+	// which does not check that the value fits the integer
+	ChangeOverflowingVarint = "overflowing varint"
+	// ChangeIntegerWidth writes a Colfer varint integer as a fixed-width integer, or the reverse
+	ChangeIntegerWidth = "integer width"
+	// ChangeExplicitZero writes a zero Colfer field which was omitted
+	ChangeExplicitZero = "explicit zero field"
+	// ChangePadding sets a byte of XDR padding
+	ChangePadding = "non-zero padding"
+	// ChangeWhitespace inserts whitespace in JSON
+	ChangeWhitespace = "whitespace"
+	// ChangeEscapedKey escapes a character of a JSON object key
+	ChangeEscapedKey = "escaped key"
+	// ChangeKeyCase changes the case of a JSON object key
+	ChangeKeyCase = "key case"
+)
+
+// CanonicalResult reports whether the decoders of a Codec accept the alternative encodings
+// of a block made by one kind of change
+type CanonicalResult struct {
+	// Change is the kind of change, such as ChangeNonMinimalVarint
+	Change string
+	// Alternatives is the number of alternative encodings tried, usually one per field the change applies to
+	Alternatives int
+	// Accepted is the number of alternatives which Decode decodes to the original block
+	Accepted int
+	// CanonicalAccepted is the number of alternatives which DecodeCanonical decodes to the original block,
+	// or -1 if the Codec has no DecodeCanonical
+	CanonicalAccepted int
+	// Path is the field path of the first accepted alternative, or of the first alternative if none is accepted
+	Path string
+}
+
+// canonicalAlternative is an encoding of a block other than the one its Codec's Encode writes
+type canonicalAlternative struct {
+	change string
+	path   string
+	data   []byte
+}
+
+// CheckCanonical encodes obj with c, changes the encoding in ways which may not change the decoded block,
+// and reports which of the alternative encodings c decodes to obj.
+// A format used for block hashes and signatures must accept none of them.
+func CheckCanonical(c Codec, obj *coin.SignedBlock) ([]CanonicalResult, error) {
+	data, err := c.Encode(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	alts, err := canonicalAlternatives(c.Name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []CanonicalResult
+	index := make(map[string]int)
+	for _, alt := range alts {
+		i, ok := index[alt.change]
+		if !ok {
+			i = len(results)
+			index[alt.change] = i
+			results = append(results, CanonicalResult{
+				Change:            alt.change,
+				CanonicalAccepted: -1,
+				Path:              alt.path,
+			})
+			if c.DecodeCanonical != nil {
+				results[i].CanonicalAccepted = 0
+			}
+		}
+		r := &results[i]
+
+		r.Alternatives++
+		if decodesTo(c, c.Decode, alt.data, data) {
+			if r.Accepted == 0 {
+				r.Path = alt.path
+			}
+			r.Accepted++
+		}
+		if c.DecodeCanonical != nil && decodesTo(c, c.DecodeCanonical, alt.data, data) {
+			r.CanonicalAccepted++
+		}
+	}
+
+	return results, nil
+}
+
+// decodesTo reports whether decode decodes alt to the block which c encodes as data
+func decodesTo(c Codec, decode func([]byte, *coin.SignedBlock, DecodeLimits) (int, error), alt, data []byte) bool {
+	var obj coin.SignedBlock
+	if _, err := decode(alt, &obj, DefaultDecodeLimits); err != nil {
+		return false
+	}
+
+	// Re-encoding compares the blocks without distinguishing nil and empty slices
+	encoded, err := c.Encode(&obj)
+	return err == nil && bytes.Equal(encoded, data)
+}
+
+// splice returns a copy of data with size bytes at offset replaced by b
+func splice(data []byte, offset, size int, b []byte) []byte {
+	out := make([]byte, 0, len(data)-size+len(b))
+	out = append(out, data[:offset]...)
+	out = append(out, b...)
+	return append(out, data[offset+size:]...)
+}
+
+// canonicalAlternatives returns alternative encodings of the block encoded as data in format
+func canonicalAlternatives(format string, data []byte) ([]canonicalAlternative, error) {
+	alts := []canonicalAlternative{{
+		change: ChangeTrailingByte,
+		data:   splice(data, len(data), 0, []byte{0}),
+	}}
+
+	switch format {
+	case "json":
+		return append(alts, jsonAlternatives(data)...), nil
+
+	case "dict", "avro":
+		// Both start with Block.Head.Version as a varint. The rest of the format can not be dissected.
+		_, n := binary.Uvarint(data)
+		f := Field{
+			Size: n,
+			Path: "Block.Head.Version",
+			Kind: "int",
+		}
+		return append(alts, varintAlternatives(data, f, true)...), nil
+	}
+
+	if !inStrings(DissectFormats, format) {
+		return alts, nil
+	}
+
+	fields, err := Dissect(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "colfer" {
+		return append(alts, colferAlternatives(data, fields)...), nil
+	}
+
+	layout, _ := dissectLayout(format)
+	for _, f := range fields {
+		switch {
+		case f.Kind == "int" && layout.varint:
+			alts = append(alts, varintAlternatives(data, f, true)...)
+		case f.Kind == "length" && layout.varintLength:
+			alts = append(alts, varintAlternatives(data, f, false)...)
+		case f.Kind == "padding":
+			alts = append(alts, canonicalAlternative{
+				change: ChangePadding,
+				path:   f.Path,
+				data:   splice(data, f.Offset+f.Size-1, 1, []byte{1}),
+			})
+		}
+	}
+
+	return alts, nil
+}
+
+func inStrings(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// varintAlternatives returns the varint f with a redundant continuation byte,
+// and if it is an integer rather than a length, with 1<<33 added
+func varintAlternatives(data []byte, f Field, integer bool) []canonicalAlternative {
+	v := data[f.Offset : f.Offset+f.Size]
+	x, n := binary.Uvarint(v)
+	if n != len(v) || n >= 9 {
+		return nil
+	}
+
+	nonMinimal := append(append([]byte(nil), v...), 0)
+	nonMinimal[n-1] |= 0x80
+
+	alts := []canonicalAlternative{{
+		change: ChangeNonMinimalVarint,
+		path:   f.Path,
+		data:   splice(data, f.Offset, f.Size, nonMinimal),
+	}}
+
+	if integer && x < 1<<48 {
+		alts = append(alts, canonicalAlternative{
+			change: ChangeOverflowingVarint,
+			path:   f.Path,
+			data:   splice(data, f.Offset, f.Size, appendUvarint(nil, x+1<<33)),
+		})
+	}
+
+	return alts
+}
+
+// colferAlternatives returns the alternative encodings of a Colfer payload dissected as fields
+func colferAlternatives(data []byte, fields []Field) []canonicalAlternative {
+	var alts []canonicalAlternative
+
+	// lastIndex is the index of the last field header seen in each struct, by struct path
+	lastIndex := make(map[string]int)
+
+	for i, f := range fields {
+		switch f.Kind {
+		case "length":
+			alts = append(alts, varintAlternatives(data, f, false)...)
+
+		case "header", "fixed header":
+			// A gap between the indexes of the fields of a struct is an omitted zero field
+			parent := parentPath(f.Path)
+			index, _ := strconv.Atoi(f.Value)
+			last, ok := lastIndex[parent]
+			if !ok {
+				last = -1
+			}
+			lastIndex[parent] = index
+
+			if index > last+1 {
+				missing := colferFieldsAt(parent)[last+1]
+				zero := []byte{byte(last + 1), 0}
+				if missing.kind == colferStruct {
+					zero[1] = 0x7f
+				}
+				alts = append(alts, canonicalAlternative{
+					change: ChangeExplicitZero,
+					path:   joinPath(parent, missing.name),
+					data:   splice(data, f.Offset, 0, zero),
+				})
+			}
+
+		case "int":
+			header := fields[i-1]
+			cf, ok := colferFieldAt(f.Path)
+			if !ok || cf.kind == colferUint8 {
+				continue
+			}
+
+			size := 4
+			if cf.kind == colferUint64 {
+				size = 8
+			}
+
+			var alt []byte
+			if header.Kind == "fixed header" {
+				x := readUint(binary.BigEndian, data[f.Offset:f.Offset+f.Size])
+				if x >= 1<<56 {
+					continue
+				}
+				alt = appendUvarint([]byte{data[header.Offset] &^ 0x80}, x)
+			} else {
+				alts = append(alts, varintAlternatives(data, f, true)...)
+
+				x, _ := binary.Uvarint(data[f.Offset : f.Offset+f.Size])
+				alt = make([]byte, 1+size)
+				alt[0] = data[header.Offset] | 0x80
+				if size == 4 {
+					binary.BigEndian.PutUint32(alt[1:], uint32(x))
+				} else {
+					binary.BigEndian.PutUint64(alt[1:], x)
+				}
+			}
+
+			alts = append(alts, canonicalAlternative{
+				change: ChangeIntegerWidth,
+				path:   f.Path,
+				data:   splice(data, header.Offset, f.Offset+f.Size-header.Offset, alt),
+			})
+		}
+	}
+
+	return alts
+}
+
+// parentPath returns the path of the struct holding the field at path
+func parentPath(path string) string {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+	return parent + "." + name
+}
+
+// colferFieldsAt returns the fields of the Colfer struct at path, such as Block.Body.Transactions[1]
+func colferFieldsAt(path string) []colferField {
+	fields := colferSignedBlockFields
+	if path == "" {
+		return fields
+	}
+
+	for _, name := range strings.Split(path, ".") {
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			name = name[:i]
+		}
+		for _, f := range fields {
+			if f.name == name {
+				fields = f.fields
+				break
+			}
+		}
+	}
+
+	return fields
+}
+
+// colferFieldAt returns the Colfer field at path
+func colferFieldAt(path string) (colferField, bool) {
+	name := path[strings.LastIndexByte(path, '.')+1:]
+	for _, f := range colferFieldsAt(parentPath(path)) {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return colferField{}, false
+}
+
+// jsonAlternatives returns the alternative encodings of a JSON payload
+func jsonAlternatives(data []byte) []canonicalAlternative {
+	alts := []canonicalAlternative{{
+		change: ChangeWhitespace,
+		data:   splice(data, 1, 0, []byte{' '}),
+	}}
+
+	key := []byte(`"Sig"`)
+	if i := bytes.Index(data, key); i >= 0 {
+		alts = append(alts,
+			canonicalAlternative{
+				change: ChangeEscapedKey,
+				path:   "Sig",
+				data:   splice(data, i, len(key), []byte(`"\u0053ig"`)),
+			},
+			canonicalAlternative{
+				change: ChangeKeyCase,
+				path:   "Sig",
+				data:   splice(data, i, len(key), []byte(`"sig"`)),
+			},
+		)
+	}
+
+	return alts
+}
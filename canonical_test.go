@@ -0,0 +1,161 @@
+package serializebench
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+func TestDecodeCanonicalRoundTrip(t *testing.T) {
+	blocks := append([]coin.SignedBlock{getBlock(), {}}, GenerateChain(20, 1)...)
+
+	for _, c := range Codecs {
+		if c.DecodeCanonical == nil {
+			continue
+		}
+
+		t.Run(c.Name, func(t *testing.T) {
+			for i, block := range blocks {
+				data, err := c.Encode(&block)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var result coin.SignedBlock
+				n, err := c.DecodeCanonical(data, &result, DefaultDecodeLimits)
+				if err != nil {
+					t.Fatalf("block %d: %v", i, err)
+				}
+				if n != len(data) {
+					t.Errorf("block %d: read %d bytes of %d", i, n, len(data))
+				}
+
+				// Compare the encodings, since decoders differ on nil and empty slices
+				encoded, err := c.Encode(&result)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !cmp.Equal(data, encoded) {
+					t.Errorf("block %d: the decoded block is different", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCanonicalErrors(t *testing.T) {
+	block := getBlock()
+
+	cases := []struct {
+		codec  string
+		change string
+		path   string
+	}{
+		{"gencode", ChangeNonMinimalVarint, "Block.Body.Transactions"},
+		{"gencodevar", ChangeNonMinimalVarint, "Block.Head.Version"},
+		{"gencodevar", ChangeOverflowingVarint, "Block.Head.Version"},
+		{"cgvarint", ChangeNonMinimalVarint, "Block.Head.Version"},
+		{"colfer", ChangeNonMinimalVarint, "Sig"},
+		{"colfer", ChangeIntegerWidth, "Block.Head.Version"},
+		{"colfer", ChangeExplicitZero, "Block.Body.Transactions[0].Out[0].Address.Version"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.codec+" "+tc.change, func(t *testing.T) {
+			c, err := CodecByName(tc.codec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			alts, err := canonicalAlternatives(c.Name, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, alt := range alts {
+				if alt.change != tc.change {
+					continue
+				}
+
+				var result coin.SignedBlock
+				_, err := c.DecodeCanonical(alt.data, &result, DefaultDecodeLimits)
+				var decodeErr *DecodeError
+				if !errors.As(err, &decodeErr) || !errors.Is(err, ErrNonCanonical) {
+					t.Fatalf("expected ErrNonCanonical, got %v", err)
+				}
+				if decodeErr.Path != tc.path {
+					t.Errorf("expected path %s, got %q", tc.path, decodeErr.Path)
+				}
+				return
+			}
+
+			t.Fatalf("no %s alternative", tc.change)
+		})
+	}
+}
+
+// TestCheckCanonical prints the alternative encodings of a block accepted by each Codec's Decode and DecodeCanonical,
+// and checks that DecodeCanonical accepts none
+func TestCheckCanonical(t *testing.T) {
+	block := getBlock()
+
+	fmt.Printf("%-11s %-20s %6s %8s %9s  %s\n", "codec", "change", "tried", "accepted", "canonical", "example")
+	for _, c := range Codecs {
+		results, err := CheckCanonical(c, &block)
+		if err != nil {
+			t.Fatalf("%s: %v", c.Name, err)
+		}
+
+		for _, r := range results {
+			canonical := "-"
+			if r.CanonicalAccepted >= 0 {
+				canonical = fmt.Sprint(r.CanonicalAccepted)
+			}
+			fmt.Printf("%-11s %-20s %6d %8d %9s  %s\n", c.Name, r.Change, r.Alternatives, r.Accepted, canonical, r.Path)
+
+			if r.CanonicalAccepted > 0 {
+				t.Errorf("%s: DecodeCanonical accepts %d %s alternatives, such as %s", c.Name, r.CanonicalAccepted, r.Change, r.Path)
+			}
+		}
+	}
+}
+
+// TestCheckCanonicalNonCanonical checks that the checker finds the known non-canonical forms of the formats in this repo
+func TestCheckCanonicalNonCanonical(t *testing.T) {
+	block := getBlock()
+
+	cases := map[string][]string{
+		"colfer":     {ChangeNonMinimalVarint, ChangeOverflowingVarint, ChangeIntegerWidth, ChangeExplicitZero},
+		"gencode":    {ChangeNonMinimalVarint},
+		"gencodevar": {ChangeNonMinimalVarint, ChangeOverflowingVarint},
+		"cgvarint":   {ChangeNonMinimalVarint},
+		"dict":       {ChangeNonMinimalVarint},
+	}
+
+	for name, changes := range cases {
+		c, err := CodecByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := CheckCanonical(c, &block)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		accepted := make(map[string]bool)
+		for _, r := range results {
+			accepted[r.Change] = r.Accepted > 0
+		}
+		for _, change := range changes {
+			if !accepted[change] {
+				t.Errorf("%s: expected Decode to accept a %s", name, change)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package serializebench
+
+import (
+	"math/rand"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+const (
+	// chainAddresses is the number of addresses that receive outputs in a generated chain
+	chainAddresses = 100
+	// chainHotAddresses is the number of frequently used addresses, such as exchange wallets
+	chainHotAddresses = 10
+	// chainGenesisTime is the timestamp of the first generated block
+	chainGenesisTime = 1426562704
+)
+
+// chainOutput is an unspent output of a generated chain
+type chainOutput struct {
+	hash  cipher.SHA256
+	owner cipher.Address
+	coins uint64
+	hours uint64
+}
+
+// GenerateChain returns the first n blocks of a synthetic chain, generated deterministically from seed.
+//
+// The first block distributes coins to a pool of addresses. Each following block has 1 to 5 transactions,
+// each spending 1 to 3 earlier outputs with one signature per input, and paying one output to a random address
+// and a change output back to the owner of the first input, so addresses repeat across transactions
+// as they do on the real chain. Hashes and signatures are random, except that each block links to
+// the hash of the previous block's header.
+func GenerateChain(n int, seed int64) []coin.SignedBlock {
+	r := rand.New(rand.NewSource(seed))
+
+	randBytes := func(b []byte) {
+		r.Read(b)
+	}
+	randHash := func() cipher.SHA256 {
+		var h cipher.SHA256
+		randBytes(h[:])
+		return h
+	}
+	randSig := func() cipher.Sig {
+		var s cipher.Sig
+		randBytes(s[:])
+		return s
+	}
+
+	addresses := make([]cipher.Address, chainAddresses)
+	for i := range addresses {
+		randBytes(addresses[i].Key[:])
+	}
+
+	// Half of the outputs go to the hot addresses
+	randAddress := func() cipher.Address {
+		if r.Intn(2) == 0 {
+			return addresses[r.Intn(chainHotAddresses)]
+		}
+		return addresses[r.Intn(len(addresses))]
+	}
+
+	var unspent []chainOutput
+
+	newTransaction := func(in []chainOutput, out []coin.TransactionOutput) coin.Transaction {
+		txn := coin.Transaction{
+			InnerHash: randHash(),
+			Out:       out,
+		}
+		for _, o := range in {
+			txn.Sigs = append(txn.Sigs, randSig())
+			txn.In = append(txn.In, o.hash)
+		}
+		txn.Length = uint32(4 + 1 + 32 + 4 + 65*len(txn.Sigs) + 4 + 32*len(txn.In) + 4 + 37*len(txn.Out))
+
+		for _, o := range out {
+			unspent = append(unspent, chainOutput{
+				hash:  randHash(),
+				owner: o.Address,
+				coins: o.Coins,
+				hours: o.Hours,
+			})
+		}
+
+		return txn
+	}
+
+	blocks := make([]coin.SignedBlock, 0, n)
+	for seq := 0; seq < n; seq++ {
+		var b coin.SignedBlock
+		b.Block.Head.BkSeq = uint64(seq)
+		b.Block.Head.Time = uint64(chainGenesisTime + seq*10 + r.Intn(10))
+		b.Block.Head.BodyHash = randHash()
+		b.Block.Head.UxHash = randHash()
+		b.Sig = randSig()
+
+		if seq == 0 {
+			var out []coin.TransactionOutput
+			for _, a := range addresses {
+				out = append(out, coin.TransactionOutput{
+					Address: a,
+					Coins:   1e6 * uint64(1+r.Intn(1e6)),
+					Hours:   uint64(r.Intn(1e5)),
+				})
+			}
+			b.Block.Body.Transactions = append(b.Block.Body.Transactions, newTransaction(nil, out))
+		} else {
+			b.Block.Head.PrevHash = headerHash(&blocks[seq-1].Block.Head)
+
+			nTxns := 1 + r.Intn(5)
+			for i := 0; i < nTxns && len(unspent) > 3; i++ {
+				var in []chainOutput
+				var coins, hours uint64
+				for j := 1 + r.Intn(3); j > 0; j-- {
+					k := r.Intn(len(unspent))
+					o := unspent[k]
+					unspent[k] = unspent[len(unspent)-1]
+					unspent = unspent[:len(unspent)-1]
+
+					in = append(in, o)
+					coins += o.coins
+					hours += o.hours
+				}
+
+				// Half of the hours are burned as a fee
+				fee := hours / 2
+				b.Block.Head.Fee += fee
+				hours -= fee
+
+				sent := coins / 1e6 / 2 * 1e6
+				out := []coin.TransactionOutput{
+					{
+						Address: randAddress(),
+						Coins:   sent,
+						Hours:   hours / 2,
+					},
+					{
+						Address: in[0].owner,
+						Coins:   coins - sent,
+						Hours:   hours - hours/2,
+					},
+				}
+
+				b.Block.Body.Transactions = append(b.Block.Body.Transactions, newTransaction(in, out))
+			}
+		}
+
+		blocks = append(blocks, b)
+	}
+
+	return blocks
+}
@@ -0,0 +1,45 @@
+package main
+
+// Format describes how scalars and length prefixes are laid out on the wire
+type Format struct {
+	// Name is the value of the -format flag
+	Name string
+	// Suffix is appended to the generated function names
+	Suffix string
+	// Description is included in the generated file's doc comment
+	Description string
+	// Varint encodes integers and length prefixes as varints (zigzag for signed integers)
+	Varint bool
+	// ByteOrder is the encoding/binary byte order used for fixed-width integers
+	ByteOrder string
+	// MinIntSize widens fixed-width integers narrower than this many bytes, as XDR writes a uint8 in 4 bytes
+	MinIntSize int
+}
+
+var formats = map[string]Format{
+	"fixed": {
+		Name:        "fixed",
+		Suffix:      "Fixed",
+		Description: "the Skycoin encoder format (little-endian fixed-width integers, uint32 length prefixes)",
+		ByteOrder:   "LittleEndian",
+	},
+	"bigendian": {
+		Name:        "bigendian",
+		Suffix:      "BigEndian",
+		Description: "the Skycoin encoder format in network byte order (big-endian fixed-width integers, uint32 length prefixes)",
+		ByteOrder:   "BigEndian",
+	},
+	"xdr": {
+		Name:        "xdr",
+		Suffix:      "XDR",
+		Description: "an XDR-like format without padding (big-endian integers of at least 4 bytes, uint32 length prefixes, unpadded byte arrays)",
+		ByteOrder:   "BigEndian",
+		MinIntSize:  4,
+	},
+	"varint": {
+		Name:        "varint",
+		Suffix:      "Varint",
+		Description: "the gencode-varint.schema format (varint integers, varint length prefixes)",
+		Varint:      true,
+	},
+}
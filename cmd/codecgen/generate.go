@@ -0,0 +1,658 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/types"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Options configures Generate
+type Options struct {
+	// Type is the struct type to generate a codec for
+	Type *types.Named
+	// Format is the wire format to generate
+	Format Format
+	// PackageName is the package name of the generated file
+	PackageName string
+	// MaxLen is the maximum number of elements accepted for a slice
+	MaxLen int
+}
+
+type generator struct {
+	opts    Options
+	buf     bytes.Buffer
+	imports map[string]string
+	// canonical generates a decoder which rejects non-minimal varints
+	canonical bool
+}
+
+// Generate returns the formatted source of a file with EncodeSize, Encode and Decode functions for opts.Type,
+// and a canonical Decode function for varint formats
+func Generate(opts Options) ([]byte, error) {
+	if opts.MaxLen <= 0 || uint64(opts.MaxLen) > math.MaxUint32 {
+		return nil, fmt.Errorf("invalid max len %d", opts.MaxLen)
+	}
+
+	g := &generator{
+		opts:    opts,
+		imports: make(map[string]string),
+	}
+
+	if err := g.generate(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by github.com/gz-c/skycoin-serialization-benchmarks/cmd/codecgen. DO NOT EDIT.\n")
+	fmt.Fprintf(&out, "package %s\n\n", opts.PackageName)
+	fmt.Fprintf(&out, "import (\n")
+	std, other := g.importPaths()
+	for _, path := range std {
+		fmt.Fprintf(&out, "%q\n", path)
+	}
+	fmt.Fprintf(&out, "\n")
+	for _, path := range other {
+		fmt.Fprintf(&out, "%q\n", path)
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, out.String())
+	}
+
+	return src, nil
+}
+
+// importPaths returns the sorted standard library and third party imports used by the generated code
+func (g *generator) importPaths() ([]string, []string) {
+	body := g.buf.String()
+	for name, path := range map[string]string{
+		"binary":  "encoding/binary",
+		"errors":  "errors",
+		"math":    "math",
+		"encoder": "github.com/skycoin/skycoin/src/cipher/encoder",
+	} {
+		if strings.Contains(body, name+".") {
+			g.imports[path] = name
+		}
+	}
+
+	var std, other []string
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	return std, other
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg.Name() == g.opts.PackageName {
+		return ""
+	}
+	g.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func (g *generator) p(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+func (g *generator) generate() error {
+	name := g.opts.Type.Obj().Name()
+	typeName := g.typeString(g.opts.Type)
+	suffix := g.opts.Format.Suffix
+
+	g.p("// EncodeSize%s%s computes the size of an encoded object of type %s", name, suffix, name)
+	g.p("// in %s", g.opts.Format.Description)
+	g.p("func EncodeSize%s%s(obj *%s) int {", name, suffix, typeName)
+	g.p("i0 := 0\n")
+	if err := g.size(g.opts.Type, "obj", 0); err != nil {
+		return err
+	}
+	g.p("return i0")
+	g.p("}\n")
+
+	g.p("// Encode%s%s encodes an object of type %s to the buffer", name, suffix, name)
+	g.p("// in %s.", g.opts.Format.Description)
+	g.p("// The buffer must be at least EncodeSize%s%s(obj) bytes long, otherwise an error is returned.", name, suffix)
+	g.p("func Encode%s%s(buf []byte, obj *%s) error {", name, suffix, typeName)
+	g.p("if len(buf) < EncodeSize%s%s(obj) {", name, suffix)
+	g.p("return encoder.ErrBufferOverflow")
+	g.p("}\n")
+	g.p("i := 0\n")
+	if err := g.encode(g.opts.Type, "obj", 0); err != nil {
+		return err
+	}
+	g.p("return nil")
+	g.p("}\n")
+
+	g.p("// Decode%s%s decodes an object of type %s from the buffer", name, suffix, name)
+	g.p("// in %s.", g.opts.Format.Description)
+	g.p("// Returns the number of bytes used from the buffer to decode the object.")
+	g.p("func Decode%s%s(buf []byte, obj *%s) (int, error) {", name, suffix, typeName)
+	g.p("i := 0\n")
+	if err := g.decode(g.opts.Type, "obj", 0); err != nil {
+		return err
+	}
+	g.p("return i, nil")
+	g.p("}")
+
+	// The fixed-width formats have one encoding per value already
+	if !g.opts.Format.Varint {
+		return nil
+	}
+
+	g.canonical = true
+	defer func() {
+		g.canonical = false
+	}()
+
+	g.p("")
+	g.p("// Decode%s%sCanonical decodes an object of type %s from the buffer like Decode%s%s,", name, suffix, name, name, suffix)
+	g.p("// except that it rejects varints with redundant continuation bytes, so that an object has exactly one encoding.")
+	g.p("// Returns the number of bytes used from the buffer to decode the object.")
+	g.p("func Decode%s%sCanonical(buf []byte, obj *%s) (int, error) {", name, suffix, typeName)
+	g.p("i := 0\n")
+	if err := g.decode(g.opts.Type, "obj", 0); err != nil {
+		return err
+	}
+	g.p("return i, nil")
+	g.p("}")
+
+	return nil
+}
+
+// fields calls f for each encoded field of a struct
+func (g *generator) fields(st *types.Struct, expr string, f func(t types.Type, expr string) error) error {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if reflect.StructTag(st.Tag(i)).Get("enc") == "-" {
+			continue
+		}
+		if !field.Exported() {
+			return fmt.Errorf("%s.%s: unexported fields must be tagged enc:\"-\"", expr, field.Name())
+		}
+		if err := f(field.Type(), expr+"."+field.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// basicSize returns the natural size of an integer or bool type in bytes
+func basicSize(t *types.Basic) (int, error) {
+	switch t.Kind() {
+	case types.Bool, types.Int8, types.Uint8:
+		return 1, nil
+	case types.Int16, types.Uint16:
+		return 2, nil
+	case types.Int32, types.Uint32:
+		return 4, nil
+	case types.Int64, types.Uint64:
+		return 8, nil
+	default:
+		return 0, fmt.Errorf("unsupported basic type %s", t)
+	}
+}
+
+// wireSize returns the encoded size of a fixed-width integer of n bytes, widened to the format's MinIntSize
+func (g *generator) wireSize(n int) int {
+	if n < g.opts.Format.MinIntSize {
+		return g.opts.Format.MinIntSize
+	}
+	return n
+}
+
+func isSigned(t *types.Basic) bool {
+	return t.Info()&types.IsUnsigned == 0
+}
+
+func isByte(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
+
+// fixedSize returns the encoded size of t if it does not depend on the value
+func (g *generator) fixedSize(t types.Type) (int, bool) {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		n, err := basicSize(u)
+		if err != nil {
+			return 0, false
+		}
+		if u.Kind() == types.Bool {
+			return n, true
+		}
+		if g.opts.Format.Varint {
+			return 0, false
+		}
+		return g.wireSize(n), true
+	case *types.Array:
+		if isByte(u.Elem()) {
+			return int(u.Len()), true
+		}
+		n, ok := g.fixedSize(u.Elem())
+		return int(u.Len()) * n, ok
+	case *types.Struct:
+		n := 0
+		for i := 0; i < u.NumFields(); i++ {
+			if reflect.StructTag(u.Tag(i)).Get("enc") == "-" {
+				continue
+			}
+			m, ok := g.fixedSize(u.Field(i).Type())
+			if !ok {
+				return 0, false
+			}
+			n += m
+		}
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func (g *generator) size(t types.Type, expr string, depth int) error {
+	if n, ok := g.fixedSize(t); ok && !isStruct(t) {
+		g.p("// %s", expr)
+		g.p("i0 += %d\n", n)
+		return nil
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if _, err := basicSize(u); err != nil {
+			return fmt.Errorf("%s: %v", expr, err)
+		}
+		g.p("// %s", expr)
+		g.p("{")
+		g.uvarintValue(u, expr)
+		g.p("for t >= 0x80 {")
+		g.p("t >>= 7")
+		g.p("i0++")
+		g.p("}")
+		g.p("i0++")
+		g.p("}\n")
+		return nil
+
+	case *types.Array:
+		x := fmt.Sprintf("x%d", depth+1)
+		g.p("// %s", expr)
+		g.p("for _, %s := range &%s {", x, expr)
+		if err := g.size(u.Elem(), x, depth+1); err != nil {
+			return err
+		}
+		g.p("}\n")
+		return nil
+
+	case *types.Slice:
+		g.p("// %s", expr)
+		if g.opts.Format.Varint {
+			g.p("{")
+			g.p("t := uint64(len(%s))", expr)
+			g.p("for t >= 0x80 {")
+			g.p("t >>= 7")
+			g.p("i0++")
+			g.p("}")
+			g.p("i0++")
+			g.p("}")
+		} else {
+			g.p("i0 += 4")
+		}
+
+		if n, ok := g.fixedSize(u.Elem()); ok {
+			g.p("i0 += len(%s) * %d\n", expr, n)
+			return nil
+		}
+
+		x := fmt.Sprintf("x%d", depth+1)
+		g.p("for _, %s := range %s {", x, expr)
+		if err := g.size(u.Elem(), x, depth+1); err != nil {
+			return err
+		}
+		g.p("}\n")
+		return nil
+
+	case *types.Struct:
+		return g.fields(u, expr, func(t types.Type, expr string) error {
+			return g.size(t, expr, depth)
+		})
+
+	default:
+		return fmt.Errorf("%s: unsupported type %s", expr, t)
+	}
+}
+
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// uvarintValue declares t as the unsigned varint representation of expr
+func (g *generator) uvarintValue(b *types.Basic, expr string) {
+	if isSigned(b) {
+		g.p("v := int64(%s)", expr)
+		g.p("t := uint64(v<<1) ^ uint64(v>>63)")
+	} else {
+		g.p("t := uint64(%s)", expr)
+	}
+}
+
+func (g *generator) encode(t types.Type, expr string, depth int) error {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		n, err := basicSize(u)
+		if err != nil {
+			return fmt.Errorf("%s: %v", expr, err)
+		}
+
+		g.p("// %s", expr)
+
+		switch {
+		case u.Kind() == types.Bool:
+			g.p("if %s {", expr)
+			g.p("buf[i] = 1")
+			g.p("} else {")
+			g.p("buf[i] = 0")
+			g.p("}")
+			g.p("i++\n")
+		case g.opts.Format.Varint && isSigned(u):
+			g.p("i += binary.PutVarint(buf[i:], int64(%s))\n", expr)
+		case g.opts.Format.Varint:
+			g.p("i += binary.PutUvarint(buf[i:], uint64(%s))\n", expr)
+		case g.wireSize(n) == 1:
+			g.p("buf[i] = %s", g.convertTo(t, types.Typ[types.Uint8], expr))
+			g.p("i++\n")
+		default:
+			w := g.wireSize(n)
+			ut := types.Typ[unsignedKind(w)]
+			g.p("binary.%s.PutUint%d(buf[i:], %s)", g.opts.Format.ByteOrder, w*8, g.convertTo(t, ut, expr))
+			g.p("i += %d\n", w)
+		}
+		return nil
+
+	case *types.Array:
+		g.p("// %s", expr)
+		if isByte(u.Elem()) {
+			g.p("i += copy(buf[i:], %s[:])\n", expr)
+			return nil
+		}
+
+		x := fmt.Sprintf("x%d", depth+1)
+		g.p("for _, %s := range &%s {", x, expr)
+		if err := g.encode(u.Elem(), x, depth+1); err != nil {
+			return err
+		}
+		g.p("}\n")
+		return nil
+
+	case *types.Slice:
+		g.p("// %s maxlen check", expr)
+		g.p("if len(%s) > %d {", expr, g.opts.MaxLen)
+		g.p("return encoder.ErrMaxLenExceeded")
+		g.p("}\n")
+
+		g.p("// %s length", expr)
+		if g.opts.Format.Varint {
+			g.p("i += binary.PutUvarint(buf[i:], uint64(len(%s)))\n", expr)
+		} else {
+			g.p("binary.%s.PutUint32(buf[i:], uint32(len(%s)))", g.opts.Format.ByteOrder, expr)
+			g.p("i += 4\n")
+		}
+
+		g.p("// %s", expr)
+		if isByte(u.Elem()) {
+			g.p("i += copy(buf[i:], %s)\n", expr)
+			return nil
+		}
+
+		x := fmt.Sprintf("x%d", depth+1)
+		g.p("for _, %s := range %s {", x, expr)
+		if err := g.encode(u.Elem(), x, depth+1); err != nil {
+			return err
+		}
+		g.p("}\n")
+		return nil
+
+	case *types.Struct:
+		return g.fields(u, expr, func(t types.Type, expr string) error {
+			return g.encode(t, expr, depth)
+		})
+
+	default:
+		return fmt.Errorf("%s: unsupported type %s", expr, t)
+	}
+}
+
+func unsignedKind(n int) types.BasicKind {
+	switch n {
+	case 1:
+		return types.Uint8
+	case 2:
+		return types.Uint16
+	case 4:
+		return types.Uint32
+	case 8:
+		return types.Uint64
+	default:
+		panic(errors.New("invalid integer size"))
+	}
+}
+
+func (g *generator) decode(t types.Type, expr string, depth int) error {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		n, err := basicSize(u)
+		if err != nil {
+			return fmt.Errorf("%s: %v", expr, err)
+		}
+
+		g.p("{")
+		g.p("// %s", expr)
+
+		switch {
+		case u.Kind() == types.Bool:
+			g.p("if len(buf)-i < 1 {")
+			g.p("return i, encoder.ErrBufferUnderflow")
+			g.p("}")
+			g.p("switch buf[i] {")
+			g.p("case 0:")
+			g.p("%s = false", expr)
+			g.p("case 1:")
+			g.p("%s = true", expr)
+			g.p("default:")
+			g.p("return i, errors.New(\"%s: invalid bool value\")", expr)
+			g.p("}")
+			g.p("i++")
+
+		case g.opts.Format.Varint:
+			g.decodeVarint(t, u, expr, n)
+
+		case g.wireSize(n) == 1:
+			g.p("if len(buf)-i < 1 {")
+			g.p("return i, encoder.ErrBufferUnderflow")
+			g.p("}")
+			g.p("%s = %s", expr, g.convertTo(types.Typ[types.Uint8], t, "buf[i]"))
+			g.p("i++")
+
+		case g.wireSize(n) > n:
+			g.decodeWidened(t, u, expr, n)
+
+		default:
+			ut := types.Typ[unsignedKind(n)]
+			g.p("if len(buf)-i < %d {", n)
+			g.p("return i, encoder.ErrBufferUnderflow")
+			g.p("}")
+			g.p("%s = %s", expr, g.convertTo(ut, t, fmt.Sprintf("binary.%s.Uint%d(buf[i:])", g.opts.Format.ByteOrder, n*8)))
+			g.p("i += %d", n)
+		}
+
+		g.p("}\n")
+		return nil
+
+	case *types.Array:
+		if isByte(u.Elem()) {
+			g.p("{")
+			g.p("// %s", expr)
+			g.p("if len(buf)-i < len(%s) {", expr)
+			g.p("return i, encoder.ErrBufferUnderflow")
+			g.p("}")
+			g.p("i += copy(%s[:], buf[i:])", expr)
+			g.p("}\n")
+			return nil
+		}
+
+		z := fmt.Sprintf("z%d", depth+1)
+		g.p("// %s", expr)
+		g.p("for %s := range %s {", z, expr)
+		if err := g.decode(u.Elem(), fmt.Sprintf("%s[%s]", expr, z), depth+1); err != nil {
+			return err
+		}
+		g.p("}\n")
+		return nil
+
+	case *types.Slice:
+		g.p("{")
+		g.p("// %s", expr)
+		g.p("")
+		if g.opts.Format.Varint {
+			g.p("ul, n := binary.Uvarint(buf[i:])")
+			g.p("if n == 0 {")
+			g.p("return i, encoder.ErrBufferUnderflow")
+			g.p("}")
+			g.p("if n < 0 {")
+			g.p("return i, errors.New(\"%s: length varint overflows uint64\")", expr)
+			g.p("}")
+			g.nonMinimalVarint(expr)
+			g.p("i += n\n")
+		} else {
+			g.p("if len(buf)-i < 4 {")
+			g.p("return i, encoder.ErrBufferUnderflow")
+			g.p("}")
+			g.p("ul := binary.%s.Uint32(buf[i:])", g.opts.Format.ByteOrder)
+			g.p("i += 4\n")
+		}
+
+		g.p("if ul > %d {", g.opts.MaxLen)
+		g.p("return i, encoder.ErrMaxLenExceeded")
+		g.p("}\n")
+
+		g.p("length := int(ul)")
+		g.p("if length > len(buf)-i {")
+		g.p("return i, encoder.ErrBufferUnderflow")
+		g.p("}\n")
+
+		g.p("if length != 0 {")
+		g.p("%s = make(%s, length)\n", expr, g.typeString(t))
+		if isByte(u.Elem()) {
+			g.p("i += copy(%s, buf[i:])", expr)
+		} else {
+			z := fmt.Sprintf("z%d", depth+1)
+			g.p("for %s := range %s {", z, expr)
+			if err := g.decode(u.Elem(), fmt.Sprintf("%s[%s]", expr, z), depth+1); err != nil {
+				return err
+			}
+			g.p("}")
+		}
+		g.p("}")
+		g.p("}\n")
+		return nil
+
+	case *types.Struct:
+		return g.fields(u, expr, func(t types.Type, expr string) error {
+			return g.decode(t, expr, depth)
+		})
+
+	default:
+		return fmt.Errorf("%s: unsupported type %s", expr, t)
+	}
+}
+
+// decodeWidened decodes an integer of n bytes widened to the format's MinIntSize,
+// rejecting values which overflow it
+func (g *generator) decodeWidened(t types.Type, u *types.Basic, expr string, n int) {
+	w := g.wireSize(n)
+	g.p("if len(buf)-i < %d {", w)
+	g.p("return i, encoder.ErrBufferUnderflow")
+	g.p("}")
+	g.p("x := binary.%s.Uint%d(buf[i:])", g.opts.Format.ByteOrder, w*8)
+	if isSigned(u) {
+		g.p("if v := int%d(x); v < math.MinInt%d || v > math.MaxInt%d {", w*8, n*8, n*8)
+	} else {
+		g.p("if x > math.MaxUint%d {", n*8)
+	}
+	g.p("return i, errors.New(\"%s: value overflows %s\")", expr, u)
+	g.p("}")
+	if isSigned(u) {
+		g.p("%s = %s", expr, g.convertTo(types.Typ[types.Int64], t, fmt.Sprintf("int%d(x)", w*8)))
+	} else {
+		g.p("%s = %s", expr, g.convertTo(types.Typ[unsignedKind(w)], t, "x"))
+	}
+	g.p("i += %d", w)
+}
+
+func (g *generator) decodeVarint(t types.Type, u *types.Basic, expr string, n int) {
+	if isSigned(u) {
+		g.p("x, n := binary.Varint(buf[i:])")
+	} else {
+		g.p("x, n := binary.Uvarint(buf[i:])")
+	}
+	g.p("if n == 0 {")
+	g.p("return i, encoder.ErrBufferUnderflow")
+	g.p("}")
+
+	switch {
+	case n == 8:
+		g.p("if n < 0 {")
+	case isSigned(u):
+		g.p("if n < 0 || x < math.MinInt%d || x > math.MaxInt%d {", n*8, n*8)
+	default:
+		g.p("if n < 0 || x > math.MaxUint%d {", n*8)
+	}
+	g.p("return i, errors.New(\"%s: varint overflows %s\")", expr, u)
+	g.p("}")
+	g.nonMinimalVarint(expr)
+
+	if isSigned(u) {
+		g.p("%s = %s", expr, g.convertTo(types.Typ[types.Int64], t, "x"))
+	} else {
+		g.p("%s = %s", expr, g.convertTo(types.Typ[types.Uint64], t, "x"))
+	}
+	g.p("i += n")
+}
+
+// nonMinimalVarint rejects the varint of n bytes at buf[i:] if it ends with a zero continuation byte,
+// when generating a canonical decoder
+func (g *generator) nonMinimalVarint(expr string) {
+	if !g.canonical {
+		return
+	}
+	g.p("if n > 1 && buf[i+n-1] == 0 {")
+	g.p("return i, errors.New(\"%s: non-minimal varint\")", expr)
+	g.p("}")
+}
+
+// convertTo wraps expr of type from in a conversion to type to, if the types differ
+func (g *generator) convertTo(from, to types.Type, expr string) string {
+	if types.Identical(from, to) {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", g.typeString(to), expr)
+}
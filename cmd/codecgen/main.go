@@ -0,0 +1,101 @@
+/*
+codecgen generates encode and decode functions that operate directly on an existing Go struct,
+such as coin.SignedBlock, without requiring a parallel struct definition or a transform step.
+
+The struct is loaded with go/types from its package source. Four wire formats are supported:
+
+	fixed      The Skycoin encoder format: little-endian fixed-width integers, uint32 length prefixes
+	bigendian  The fixed format in network byte order: big-endian fixed-width integers
+	xdr        The bigendian format with integers widened to at least 4 bytes as in XDR, but byte arrays unpadded
+	varint     The gencode-varint.schema format: varint integers, varint length prefixes
+
+In all formats, fixed-size byte arrays are copied as raw bytes and fields are written in struct order.
+For the varint format, a Decode<Struct>VarintCanonical function is also generated, which rejects varints
+with redundant continuation bytes.
+
+Usage:
+
+	codecgen -struct SignedBlock -format varint -package serializebench -output signed_block_varint.go github.com/skycoin/skycoin/src/coin
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func main() {
+	structName := flag.String("struct", "", "struct name to generate a codec for")
+	formatName := flag.String("format", "", "wire format, one of: fixed, bigendian, xdr, varint")
+	packageName := flag.String("package", "", "package name of the output file, defaults to the struct's package name")
+	outputPath := flag.String("output", "", "output file, defaults to stdout")
+	maxLen := flag.Int("max-len", 65535, "maximum number of elements accepted for a slice when decoding")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <import path>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *structName == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	format, ok := formats[*formatName]
+	if !ok {
+		log.Fatalf("unknown format %q", *formatName)
+	}
+
+	importPath := flag.Arg(0)
+
+	fset := token.NewFileSet()
+	pkg, err := importer.ForCompiler(fset, "source", nil).Import(importPath)
+	if err != nil {
+		log.Fatalf("load package %s: %v", importPath, err)
+	}
+
+	obj := pkg.Scope().Lookup(*structName)
+	if obj == nil {
+		log.Fatalf("struct %s not found in package %s", *structName, importPath)
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		log.Fatalf("%s is not a named type", *structName)
+	}
+
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		log.Fatalf("%s is not a struct", *structName)
+	}
+
+	if *packageName == "" {
+		*packageName = pkg.Name()
+	}
+
+	src, err := Generate(Options{
+		Type:        named,
+		Format:      format,
+		PackageName: *packageName,
+		MaxLen:      *maxLen,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *outputPath == "" {
+		os.Stdout.Write(src)
+		return
+	}
+
+	if err := ioutil.WriteFile(*outputPath, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+serdump prints an annotated hexdump of an encoded coin.SignedBlock, with the offset, field path and decoded value
+of each field. If the block fails to decode, the bytes from the point where decoding stopped are marked with >>.
+
+Supported formats: sky, skyenc, xdr2, colfer, gencode, gencodevar, cgfixed, cgbe, cgxdr, cgvarint
+
+Usage:
+
+	serdump -format colfer block.bin
+*/
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	serializebench "github.com/gz-c/skycoin-serialization-benchmarks"
+)
+
+const bytesPerLine = 16
+
+func main() {
+	format := flag.String("format", "", "encoding format, one of: "+strings.Join(serializebench.DissectFormats, ", "))
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -format <format> <file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *format == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fields, err := serializebench.Dissect(*format, data)
+
+	var decodeErr *serializebench.DecodeError
+	if err != nil && !errors.As(err, &decodeErr) {
+		log.Fatal(err)
+	}
+
+	dump(os.Stdout, data, fields, decodeErr)
+
+	if decodeErr != nil {
+		os.Exit(1)
+	}
+}
+
+// dump writes the annotated hexdump of data. If decoding failed with err, the fields and bytes
+// from the failure, or from the end of the last field if its offset is unknown, are marked.
+func dump(w io.Writer, data []byte, fields []serializebench.Field, err *serializebench.DecodeError) {
+	fmt.Fprintf(w, "   %-8s  %-*s  %s\n", "offset", bytesPerLine*3-1, "bytes", "field")
+
+	stopped := false
+	stop := func(offset int) {
+		fmt.Fprintf(w, ">> decoding stopped at byte %d: %v\n", offset, err)
+		stopped = true
+	}
+
+	end := 0
+	for _, f := range fields {
+		mark := "   "
+		if err != nil && err.Offset >= 0 && f.Offset >= err.Offset {
+			if !stopped {
+				stop(err.Offset)
+			}
+			mark = ">> "
+		}
+
+		annotation := f.Path
+		if annotation == "" {
+			annotation = "SignedBlock"
+		}
+		annotation += " " + f.Kind
+		if f.Value != "" {
+			annotation += " = " + f.Value
+		}
+
+		lines(w, mark, f.Offset, data[f.Offset:f.Offset+f.Size], annotation)
+		end = f.Offset + f.Size
+	}
+
+	if err == nil {
+		fmt.Fprintf(w, "%d bytes, %d fields\n", len(data), len(fields))
+		return
+	}
+
+	if !stopped {
+		if err.Offset >= 0 {
+			stop(err.Offset)
+		} else {
+			stop(end)
+		}
+	}
+	if end < len(data) {
+		lines(w, ">> ", end, data[end:], "not decoded")
+	}
+}
+
+// lines writes b, which starts at offset, bytesPerLine bytes per line, annotating the first line
+func lines(w io.Writer, mark string, offset int, b []byte, annotation string) {
+	for i := 0; i == 0 || i < len(b); i += bytesPerLine {
+		j := i + bytesPerLine
+		if j > len(b) {
+			j = len(b)
+		}
+
+		line := fmt.Sprintf("%s%08x  %-*s", mark, offset+i, bytesPerLine*3-1, hexBytes(b[i:j]))
+		if i == 0 {
+			line += "  " + annotation
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
+
+// hexBytes formats b as space-separated hex bytes
+func hexBytes(b []byte) string {
+	var s strings.Builder
+	for i, c := range b {
+		if i != 0 {
+			s.WriteByte(' ')
+		}
+		s.WriteString(hex.EncodeToString([]byte{c}))
+	}
+	return s.String()
+}
@@ -0,0 +1,232 @@
+/*
+sertranscode converts blocks from one format to another, verifying each conversion by decoding
+the output and comparing it with the input block.
+
+By default the input and output are block streams, in which each binary block is prefixed with its
+length as a uvarint and JSON blocks are written one per line. Blocks are converted one at a time,
+so files too large to fit in memory can be converted. With -single, the input is one unframed block.
+Blocks are verified before they are written, and the output file is removed if the conversion fails.
+
+Every format in serializebench.Codecs is supported. Run sertranscode -help for the list.
+
+Usage:
+
+	sertranscode -from sky -to gencode -in blocks.sky -out blocks.gencode
+*/
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/skycoin/skycoin/src/coin"
+
+	serializebench "github.com/gz-c/skycoin-serialization-benchmarks"
+)
+
+func codecNames() string {
+	var names []string
+	for _, c := range serializebench.Codecs {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func main() {
+	fromName := flag.String("from", "", "input format, one of: "+codecNames())
+	toName := flag.String("to", "", "output format, one of: "+codecNames())
+	inPath := flag.String("in", "", "input file, defaults to stdin")
+	outPath := flag.String("out", "", "output file, defaults to stdout")
+	single := flag.Bool("single", false, "the input and output are a single unframed block")
+	noVerify := flag.Bool("no-verify", false, "do not verify the converted blocks")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -from <format> -to <format> [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *fromName == "" || *toName == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	o := options{
+		from:    *fromName,
+		to:      *toName,
+		inPath:  *inPath,
+		outPath: *outPath,
+		single:  *single,
+		verify:  !*noVerify,
+	}
+	n, err := run(o)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "converted %d blocks from %s to %s\n", n, o.from, o.to)
+}
+
+// options are the command line options
+type options struct {
+	from    string
+	to      string
+	inPath  string
+	outPath string
+	single  bool
+	verify  bool
+}
+
+// run converts the input and returns the number of blocks converted. The output file is only kept if every
+// block was converted and the file was closed without an error, so that a failed conversion leaves no
+// truncated output behind.
+func run(o options) (n int, err error) {
+	from, err := serializebench.CodecByName(o.from)
+	if err != nil {
+		return 0, err
+	}
+	to, err := serializebench.CodecByName(o.to)
+	if err != nil {
+		return 0, err
+	}
+
+	var in io.Reader = os.Stdin
+	if o.inPath != "" {
+		f, err := os.Open(o.inPath)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var out io.Writer = os.Stdout
+	if o.outPath != "" {
+		f, cerr := os.Create(o.outPath)
+		if cerr != nil {
+			return 0, cerr
+		}
+		// err is the result of run, set when the deferred function runs
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(o.outPath)
+			}
+		}()
+		out = f
+	}
+
+	t := transcoder{
+		from:   from,
+		to:     to,
+		verify: o.verify,
+		limits: serializebench.DefaultDecodeLimits,
+	}
+
+	if o.single {
+		if err := t.single(in, out); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	return t.stream(in, out)
+}
+
+// transcoder converts blocks between two codecs
+type transcoder struct {
+	from   serializebench.Codec
+	to     serializebench.Codec
+	verify bool
+	limits serializebench.DecodeLimits
+}
+
+// single converts one unframed block
+func (t transcoder) single(r io.Reader, w io.Writer) error {
+	// Read one byte past the limit to detect an oversized input
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(t.limits.MaxBytes)+1))
+	if err != nil {
+		return err
+	}
+
+	var block coin.SignedBlock
+	if _, err := t.from.Decode(data, &block, t.limits); err != nil {
+		return err
+	}
+
+	out, err := t.to.Encode(&block)
+	if err != nil {
+		return err
+	}
+
+	if err := t.check(0, &block, out); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(out); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// stream converts a block stream and returns the number of blocks converted
+func (t transcoder) stream(r io.Reader, w io.Writer) (int, error) {
+	br := serializebench.NewBlockReader(r, t.from, t.limits)
+	bw := serializebench.NewBlockWriter(w, t.to)
+
+	n := 0
+	for {
+		var block coin.SignedBlock
+		if _, err := br.Next(&block); err == io.EOF {
+			break
+		} else if err != nil {
+			return n, fmt.Errorf("block %d: %v", n, err)
+		}
+
+		// The block is verified before it is written, so that the output only holds verified blocks
+		out, err := t.to.Encode(&block)
+		if err != nil {
+			return n, fmt.Errorf("block %d: %v", n, err)
+		}
+		if err := t.check(n, &block, out); err != nil {
+			return n, err
+		}
+		if err := bw.WriteEncoded(out); err != nil {
+			return n, fmt.Errorf("block %d: %v", n, err)
+		}
+
+		n++
+	}
+
+	return n, bw.Flush()
+}
+
+// check decodes the converted block and compares it with the input block.
+// Nil and empty slices are equal, as the transformgen conversions leave empty lists nil.
+func (t transcoder) check(i int, block *coin.SignedBlock, out []byte) error {
+	if !t.verify {
+		return nil
+	}
+
+	var result coin.SignedBlock
+	if _, err := t.to.Decode(out, &result, t.limits); err != nil {
+		return fmt.Errorf("block %d: decode %s output: %v", i, t.to.Name, err)
+	}
+
+	if diff := cmp.Diff(*block, result, cmpopts.EquateEmpty()); diff != "" {
+		return fmt.Errorf("block %d: %s output differs from the input:\n%s", i, t.to.Name, diff)
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+
+	serializebench "github.com/gz-c/skycoin-serialization-benchmarks"
+)
+
+func mustCodec(t *testing.T, name string) serializebench.Codec {
+	c, err := serializebench.CodecByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func testBlocks() []coin.SignedBlock {
+	var blocks []coin.SignedBlock
+	for i := 0; i < 5; i++ {
+		var b coin.SignedBlock
+		b.Block.Head.BkSeq = uint64(i)
+		b.Block.Head.Time = uint64(1538036613 + i)
+		for j := 0; j < i; j++ {
+			b.Block.Body.Transactions = append(b.Block.Body.Transactions, coin.Transaction{
+				Length:    uint32(j),
+				InnerHash: cipher.SumSHA256([]byte{byte(i), byte(j)}),
+				Sigs:      []cipher.Sig{{byte(j)}},
+				In:        []cipher.SHA256{cipher.SumSHA256([]byte{byte(j)})},
+				Out: []coin.TransactionOutput{
+					{
+						Coins: uint64(j) * 1e6,
+						Hours: uint64(i),
+					},
+				},
+			})
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+func TestTranscodeStream(t *testing.T) {
+	blocks := testBlocks()
+
+	var sky bytes.Buffer
+	w := serializebench.NewBlockWriter(&sky, mustCodec(t, "sky"))
+	for i := range blocks {
+		if _, err := w.Write(&blocks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Convert through every format and back to sky
+	in := sky.Bytes()
+	from := mustCodec(t, "sky")
+	for _, to := range append(serializebench.Codecs, from) {
+		tc := transcoder{
+			from:   from,
+			to:     to,
+			verify: true,
+			limits: serializebench.DefaultDecodeLimits,
+		}
+
+		var out bytes.Buffer
+		n, err := tc.stream(bytes.NewReader(in), &out)
+		if err != nil {
+			t.Fatalf("%s to %s: %v", from.Name, to.Name, err)
+		}
+		if n != len(blocks) {
+			t.Fatalf("%s to %s: converted %d of %d blocks", from.Name, to.Name, n, len(blocks))
+		}
+
+		in = out.Bytes()
+		from = to
+	}
+
+	if !bytes.Equal(in, sky.Bytes()) {
+		t.Error("round trip through every format changed the sky stream")
+	}
+}
+
+func TestTranscodeSingle(t *testing.T) {
+	block := testBlocks()[3]
+
+	data, err := mustCodec(t, "colfer").Encode(&block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := transcoder{
+		from:   mustCodec(t, "colfer"),
+		to:     mustCodec(t, "gencodevar"),
+		verify: true,
+		limits: serializebench.DefaultDecodeLimits,
+	}
+
+	var out bytes.Buffer
+	if err := tc.single(bytes.NewReader(data), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := tc.to.Encode(&block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), expected) {
+		t.Error("unexpected output")
+	}
+
+	// The input is decoded strictly
+	err = tc.single(bytes.NewReader(data[:len(data)-1]), &out)
+	if err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("expected a truncated error, got %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	blocks := testBlocks()
+
+	var sky bytes.Buffer
+	w := serializebench.NewBlockWriter(&sky, mustCodec(t, "sky"))
+	for i := range blocks {
+		if _, err := w.Write(&blocks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "sertranscode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	o := options{
+		from:    "sky",
+		to:      "colfer",
+		inPath:  filepath.Join(dir, "blocks.sky"),
+		outPath: filepath.Join(dir, "blocks.colfer"),
+		verify:  true,
+	}
+	if err := ioutil.WriteFile(o.inPath, sky.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := run(o); err != nil || n != len(blocks) {
+		t.Fatalf("converted %d of %d blocks: %v", n, len(blocks), err)
+	}
+	if _, err := os.Stat(o.outPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed conversion removes the partial output
+	if err := ioutil.WriteFile(o.inPath, sky.Bytes()[:sky.Len()-1], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(o); err == nil {
+		t.Fatal("expected an error for a truncated input")
+	}
+	if _, err := os.Stat(o.outPath); !os.IsNotExist(err) {
+		t.Errorf("expected the output to be removed, got %v", err)
+	}
+}
+
+func TestTranscodeStreamVerifiesBeforeWriting(t *testing.T) {
+	// The second block is larger than the writer's buffer, so writing it reaches the output immediately
+	blocks := testBlocks()[:2]
+	for i := 0; i < 100; i++ {
+		blocks[1].Block.Body.Transactions = append(blocks[1].Block.Body.Transactions, testBlocks()[2].Block.Body.Transactions...)
+	}
+
+	var sky bytes.Buffer
+	w := serializebench.NewBlockWriter(&sky, mustCodec(t, "sky"))
+	for i := range blocks {
+		if _, err := w.Write(&blocks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An output codec whose encoding of the second block does not decode to the block
+	to := mustCodec(t, "sky")
+	encode := to.Encode
+	to.Encode = func(obj *coin.SignedBlock) ([]byte, error) {
+		b := *obj
+		if b.Block.Head.BkSeq == 1 {
+			b.Block.Head.Fee++
+		}
+		return encode(&b)
+	}
+
+	tc := transcoder{
+		from:   mustCodec(t, "sky"),
+		to:     to,
+		verify: true,
+		limits: serializebench.DefaultDecodeLimits,
+	}
+	var out bytes.Buffer
+	n, err := tc.stream(bytes.NewReader(sky.Bytes()), &out)
+	if err == nil || n != 1 {
+		t.Fatalf("expected a verification failure at block 1, converted %d blocks: %v", n, err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("%d bytes were written before verification failed", out.Len())
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Options configures Generate
+type Options struct {
+	// Source is the domain struct type
+	Source *types.Named
+	// Target is the serializer struct type
+	Target *types.Named
+	// ToFunc is the name of the function converting Source to Target
+	ToFunc string
+	// FromFunc is the name of the function converting Target to Source
+	FromFunc string
+	// PackageName is the package name of the generated file
+	PackageName string
+}
+
+type generator struct {
+	opts    Options
+	buf     bytes.Buffer
+	imports map[string]string
+
+	// fallible is set if the function being generated contains a conversion that can fail
+	fallible bool
+	// zero is the zero value returned with an error by the function being generated
+	zero string
+}
+
+// Generate returns the formatted source of a file with conversion functions
+// between opts.Source and opts.Target, in both directions
+func Generate(opts Options) ([]byte, error) {
+	g := &generator{
+		opts:    opts,
+		imports: make(map[string]string),
+	}
+
+	to, err := g.function(opts.ToFunc, opts.Source, opts.Target, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := g.function(opts.FromFunc, opts.Target, opts.Source, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(to, []byte("errors.")) || bytes.Contains(from, []byte("errors.")) {
+		g.imports["errors"] = "errors"
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by github.com/gz-c/skycoin-serialization-benchmarks/cmd/transformgen. DO NOT EDIT.\n")
+	fmt.Fprintf(&out, "package %s\n\n", opts.PackageName)
+	fmt.Fprintf(&out, "import (\n")
+	std, other := g.importPaths()
+	for _, path := range std {
+		fmt.Fprintf(&out, "%q\n", path)
+	}
+	fmt.Fprintf(&out, "\n")
+	for _, path := range other {
+		fmt.Fprintf(&out, "%q\n", path)
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	out.Write(to)
+	out.WriteByte('\n')
+	out.Write(from)
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, out.String())
+	}
+
+	return src, nil
+}
+
+// importPaths returns the sorted standard library and third party imports used by the generated code
+func (g *generator) importPaths() ([]string, []string) {
+	var std, other []string
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	return std, other
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg.Name() == g.opts.PackageName {
+		return ""
+	}
+	g.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func (g *generator) p(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+// function generates a conversion function from type from to type to.
+// fromPtr makes the argument a pointer, toPtr makes the result a pointer.
+func (g *generator) function(name string, from, to *types.Named, fromPtr, toPtr bool) ([]byte, error) {
+	g.buf.Reset()
+	g.fallible = false
+
+	fromName := g.typeString(from)
+	toName := g.typeString(to)
+
+	argType := fromName
+	if fromPtr {
+		argType = "*" + fromName
+	}
+
+	resultType := toName
+	result := "out"
+	g.zero = toName + "{}"
+	if toPtr {
+		resultType = "*" + toName
+		result = "&out"
+		g.zero = "nil"
+	}
+
+	g.p("var out %s\n", toName)
+	if err := g.assign("out", "obj", to, from, "", 0); err != nil {
+		return nil, err
+	}
+
+	body := append([]byte(nil), g.buf.Bytes()...)
+
+	var fn bytes.Buffer
+	fmt.Fprintf(&fn, "// %s converts a %s to a %s\n", name, fromName, toName)
+	if g.fallible {
+		fmt.Fprintf(&fn, "func %s(obj %s) (%s, error) {\n", name, argType, resultType)
+	} else {
+		fmt.Fprintf(&fn, "func %s(obj %s) %s {\n", name, argType, resultType)
+	}
+	fn.Write(body)
+	if g.fallible {
+		fmt.Fprintf(&fn, "return %s, nil\n", result)
+	} else {
+		fmt.Fprintf(&fn, "return %s\n", result)
+	}
+	fmt.Fprintf(&fn, "}\n")
+
+	return fn.Bytes(), nil
+}
+
+// fail emits a return of an error describing a failed conversion of the field at path
+func (g *generator) fail(path, reason string) {
+	g.fallible = true
+	g.p("return %s, errors.New(%q)", g.zero, fmt.Sprintf("%s: %s", strings.TrimPrefix(path, "."), reason))
+}
+
+func isByte(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
+
+func structElem(t types.Type) (*types.Pointer, bool) {
+	p, ok := t.(*types.Pointer)
+	if !ok {
+		return nil, false
+	}
+	_, ok = p.Elem().Underlying().(*types.Struct)
+	return p, ok
+}
+
+// assign emits code assigning src of type st to dst of type dt.
+// path is the field path used in error messages.
+func (g *generator) assign(dst, src string, dt, st types.Type, path string, depth int) error {
+	if types.AssignableTo(st, dt) {
+		g.p("%s = %s", dst, src)
+		return nil
+	}
+
+	// Wrap the destination in a pointer
+	if p, ok := structElem(dt); ok {
+		g.p("%s = &%s{}", dst, g.typeString(p.Elem()))
+		if types.Identical(st.Underlying(), p.Elem().Underlying()) {
+			g.p("*%s = %s(%s)", dst, g.typeString(p.Elem()), src)
+			return nil
+		}
+		return g.assign(dst, src, p.Elem(), st, path, depth)
+	}
+
+	// Unwrap a source pointer, leaving the destination as the zero value if it is nil
+	if p, ok := structElem(st); ok {
+		g.p("if %s != nil {", src)
+		var err error
+		switch {
+		case types.AssignableTo(p.Elem(), dt):
+			g.p("%s = *%s", dst, src)
+		case types.Identical(p.Elem().Underlying(), dt.Underlying()):
+			g.p("%s = %s(*%s)", dst, g.typeString(dt), src)
+		default:
+			err = g.assign(dst, src, dt, p.Elem(), path, depth)
+		}
+		g.p("}")
+		return err
+	}
+
+	if types.Identical(st.Underlying(), dt.Underlying()) {
+		g.p("%s = %s(%s)", dst, g.typeString(dt), src)
+		return nil
+	}
+
+	switch du := dt.Underlying().(type) {
+	case *types.Struct:
+		su, ok := st.Underlying().(*types.Struct)
+		if !ok {
+			break
+		}
+		return g.assignStruct(dst, src, du, su, path, depth)
+
+	case *types.Slice:
+		switch su := st.Underlying().(type) {
+		case *types.Array:
+			// Array to slice
+			if isByte(du.Elem()) && isByte(su.Elem()) {
+				g.p("%s = make(%s, len(%s))", dst, g.typeString(dt), src)
+				g.p("copy(%s, %s[:])", dst, src)
+				return nil
+			}
+
+			i := fmt.Sprintf("i%d", depth+1)
+			g.p("%s = make(%s, len(%s))", dst, g.typeString(dt), src)
+			g.p("for %s := range %s {", i, src)
+			if err := g.assign(dst+"["+i+"]", src+"["+i+"]", du.Elem(), su.Elem(), path, depth+1); err != nil {
+				return err
+			}
+			g.p("}")
+			return nil
+
+		case *types.Slice:
+			// Slice to slice, keeping empty slices nil
+			i := fmt.Sprintf("i%d", depth+1)
+			g.p("if len(%s) != 0 {", src)
+			g.p("%s = make(%s, len(%s))", dst, g.typeString(dt), src)
+			g.p("for %s := range %s {", i, src)
+			if err := g.assign(dst+"["+i+"]", src+"["+i+"]", du.Elem(), su.Elem(), path, depth+1); err != nil {
+				return err
+			}
+			g.p("}")
+			g.p("}")
+			return nil
+		}
+
+	case *types.Array:
+		su, ok := st.Underlying().(*types.Slice)
+		if !ok {
+			break
+		}
+
+		// Slice to array, which fails if the lengths differ
+		g.p("if len(%s) != len(%s) {", src, dst)
+		g.fail(path, "invalid length")
+		g.p("}")
+
+		if isByte(du.Elem()) && isByte(su.Elem()) {
+			g.p("copy(%s[:], %s)", dst, src)
+			return nil
+		}
+
+		i := fmt.Sprintf("i%d", depth+1)
+		g.p("for %s := range %s {", i, src)
+		if err := g.assign(dst+"["+i+"]", src+"["+i+"]", du.Elem(), su.Elem(), path, depth+1); err != nil {
+			return err
+		}
+		g.p("}")
+		return nil
+	}
+
+	return fmt.Errorf("%s: can not convert %s to %s", strings.TrimPrefix(path, "."), st, dt)
+}
+
+// assignStruct emits code assigning each field of src to the field of the same name in dst.
+// Every field must be mapped in both directions.
+func (g *generator) assignStruct(dst, src string, dt, st *types.Struct, path string, depth int) error {
+	srcFields := make(map[string]*types.Var)
+	for i := 0; i < st.NumFields(); i++ {
+		if reflect.StructTag(st.Tag(i)).Get("enc") == "-" {
+			continue
+		}
+		srcFields[st.Field(i).Name()] = st.Field(i)
+	}
+
+	var unmapped []string
+	for i := 0; i < dt.NumFields(); i++ {
+		f := dt.Field(i)
+		sf, ok := srcFields[f.Name()]
+		if !ok {
+			unmapped = append(unmapped, fmt.Sprintf("%s has no source field", strings.TrimPrefix(path+"."+f.Name(), ".")))
+			continue
+		}
+		delete(srcFields, f.Name())
+
+		if err := g.assign(dst+"."+f.Name(), src+"."+f.Name(), f.Type(), sf.Type(), path+"."+f.Name(), depth); err != nil {
+			return err
+		}
+	}
+
+	for name := range srcFields {
+		unmapped = append(unmapped, fmt.Sprintf("%s has no destination field", strings.TrimPrefix(path+"."+name, ".")))
+	}
+
+	if len(unmapped) != 0 {
+		sort.Strings(unmapped)
+		return fmt.Errorf("unmapped fields:\n\t%s", strings.Join(unmapped, "\n\t"))
+	}
+
+	return nil
+}
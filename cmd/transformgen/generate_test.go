@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func loadTypes(t *testing.T, src string) *types.Package {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "src.go", "package example\n"+src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("example", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func TestGenerate(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		err      string
+		contains []string
+	}{
+		{
+			name: "fields match",
+			src: `
+type Hash [32]byte
+type Source struct {
+	A uint64
+	H Hash
+	L []Hash
+	S SourceInner
+}
+type SourceInner struct {
+	B uint8
+}
+type Target struct {
+	S *TargetInner
+	L [][]byte
+	H []byte
+	A uint64
+}
+type TargetInner struct {
+	B uint8
+}`,
+			contains: []string{
+				"func toTarget(obj Source) *Target {",
+				"func fromTarget(obj *Target) (Source, error) {",
+				"out.S = &TargetInner{}",
+				"*out.S = TargetInner(obj.S)",
+				"out.S = SourceInner(*obj.S)",
+				`errors.New("L: invalid length")`,
+			},
+		},
+		{
+			name: "unmapped target field",
+			src: `
+type Source struct {
+	A uint64
+}
+type Target struct {
+	A uint64
+	B uint64
+}`,
+			err: "B has no source field",
+		},
+		{
+			name: "unmapped source field",
+			src: `
+type Source struct {
+	A uint64
+	B SourceInner
+}
+type SourceInner struct {
+	C uint64
+	D uint64
+}
+type Target struct {
+	A uint64
+	B TargetInner
+}
+type TargetInner struct {
+	C uint64
+}`,
+			err: "B.D has no destination field",
+		},
+		{
+			name: "incompatible field",
+			src: `
+type Source struct {
+	A uint64
+}
+type Target struct {
+	A string
+}`,
+			err: "A: can not convert uint64 to string",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg := loadTypes(t, tc.src)
+
+			src, err := Generate(Options{
+				Source:      pkg.Scope().Lookup("Source").Type().(*types.Named),
+				Target:      pkg.Scope().Lookup("Target").Type().(*types.Named),
+				ToFunc:      "toTarget",
+				FromFunc:    "fromTarget",
+				PackageName: "example",
+			})
+
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, s := range tc.contains {
+				if !strings.Contains(string(src), s) {
+					t.Errorf("generated code does not contain %q:\n%s", s, src)
+				}
+			}
+
+			// The generated code must compile with the structs
+			fset := token.NewFileSet()
+			files := make([]*ast.File, 2)
+			for i, code := range []string{"package example\n" + tc.src, string(src)} {
+				if files[i], err = parser.ParseFile(fset, fmt.Sprintf("src%d.go", i), code, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+			conf := types.Config{Importer: importer.Default()}
+			if _, err := conf.Check("example", fset, files, nil); err != nil {
+				t.Errorf("generated code does not compile: %v\n%s", err, src)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+transformgen generates conversion functions between a domain struct, such as coin.SignedBlock,
+and a struct generated by another serializer, such as GencodeSignedBlock or ColferSignedBlock.
+
+Fields are matched by name. Fixed-size arrays are adapted to and from slices, pointers to structs are
+wrapped and unwrapped, and slices are converted element by element. Every field on both sides must be
+mapped, otherwise generation fails, so a schema change can not silently drop a field.
+
+Empty lists are left nil in both directions, so a block converted back has nil slices where the original
+may have empty ones. Compare converted blocks with nil and empty slices equal.
+
+A relative package import path refers to a package on disk.
+
+Usage:
+
+	transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source SignedBlock -target ColferSignedBlock -to blockToColfer -from colferToBlock -output transform_colfer.go
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func main() {
+	sourcePkg := flag.String("source-pkg", "", "import path of the domain struct's package")
+	source := flag.String("source", "", "domain struct name")
+	targetPkg := flag.String("target-pkg", ".", "import path of the serializer struct's package")
+	target := flag.String("target", "", "serializer struct name")
+	toFunc := flag.String("to", "", "name of the function converting source to target")
+	fromFunc := flag.String("from", "", "name of the function converting target to source")
+	packageName := flag.String("package", "", "package name of the output file, defaults to the target's package name")
+	outputPath := flag.String("output", "", "output file, defaults to stdout")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *sourcePkg == "" || *source == "" || *target == "" || *toFunc == "" || *fromFunc == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	imp := importer.ForCompiler(token.NewFileSet(), "source", nil)
+
+	sourceType, err := lookup(imp, *sourcePkg, *source)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	targetType, err := lookup(imp, *targetPkg, *target)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *packageName == "" {
+		*packageName = targetType.Obj().Pkg().Name()
+	}
+
+	src, err := Generate(Options{
+		Source:      sourceType,
+		Target:      targetType,
+		ToFunc:      *toFunc,
+		FromFunc:    *fromFunc,
+		PackageName: *packageName,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *outputPath == "" {
+		os.Stdout.Write(src)
+		return
+	}
+
+	if err := ioutil.WriteFile(*outputPath, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// lookup loads a named struct type from a package
+func lookup(imp types.Importer, path, typeName string) (*types.Named, error) {
+	pkg, err := imp.Import(path)
+	if err != nil {
+		return nil, fmt.Errorf("load package %s: %v", path, err)
+	}
+
+	obj := pkg.Scope().Lookup(typeName)
+	if obj == nil {
+		return nil, fmt.Errorf("type %s not found in package %s", typeName, path)
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a named type", typeName)
+	}
+
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil, fmt.Errorf("%s is not a struct", typeName)
+	}
+
+	return named, nil
+}
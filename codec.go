@@ -0,0 +1,454 @@
+package serializebench
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	xdr "github.com/davecgh/go-xdr/xdr2"
+	"github.com/niubaoshu/gotiny"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// Codec encodes and decodes a coin.SignedBlock in one wire format
+type Codec struct {
+	// Name is the short name of the format
+	Name string
+	// Text is set for formats whose encoding is a single line of text
+	Text bool
+	// Encode returns the encoded block
+	Encode func(obj *coin.SignedBlock) ([]byte, error)
+	// Decode decodes a block from buf, which must contain exactly one block, and returns the number of bytes read.
+	// Errors are a *DecodeError. Payloads exceeding limits are rejected before they are decoded.
+	Decode func(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error)
+	// DecodeCanonical is Decode, except that it only accepts the encoding Encode writes, so that every block
+	// has exactly one encoding. Other encodings of the block are rejected with ErrNonCanonical.
+	// It is nil for formats without a canonical decoder.
+	DecodeCanonical func(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error)
+	// ReuseDecode is Decode into an obj which may hold a previously decoded block, reusing the capacity
+	// of its slices. Empty slices may be non-nil. It is nil for formats without a reuse decoder.
+	// It is not the decoder of Decode: the block view formats decode from a BlockView, and Colfer
+	// with a hand-written decoder.
+	ReuseDecode func(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error)
+}
+
+// Codecs are the serializers compared by this package
+var Codecs = []Codec{
+	{
+		Name:            "sky",
+		Encode:          encodeSky,
+		Decode:          decodeSky,
+		DecodeCanonical: decodeSky,
+	},
+	{
+		Name:            "skyenc",
+		Encode:          encodeSkyencoder,
+		Decode:          decodeSkyencoder,
+		DecodeCanonical: decodeSkyencoder,
+		ReuseDecode:     viewDecodeSkyencoder,
+	},
+	{
+		Name:            "xdr2",
+		Encode:          encodeXDR2,
+		Decode:          decodeXDR2,
+		DecodeCanonical: decodeXDR2,
+	},
+	{
+		Name:   "json",
+		Text:   true,
+		Encode: encodeJSON,
+		Decode: decodeJSON,
+	},
+	{
+		Name:   "gotiny",
+		Encode: encodeGotiny,
+		Decode: decodeGotiny,
+	},
+	{
+		Name:            "colfer",
+		Encode:          encodeColfer,
+		Decode:          decodeColfer,
+		DecodeCanonical: decodeColferCanonical,
+		ReuseDecode:     reuseDecodeColfer,
+	},
+	{
+		Name:            "gencode",
+		Encode:          encodeGencode,
+		Decode:          decodeGencode,
+		DecodeCanonical: decodeGencodeCanonical,
+		ReuseDecode:     viewDecodeGencode,
+	},
+	{
+		Name:            "gencodevar",
+		Encode:          encodeGencodeVarint,
+		Decode:          decodeGencodeVarint,
+		DecodeCanonical: decodeGencodeVarintCanonical,
+	},
+	{
+		Name:            "cgfixed",
+		Encode:          encodeCodecgenFixed,
+		Decode:          decodeCodecgenFixed,
+		DecodeCanonical: decodeCodecgenFixed,
+		ReuseDecode:     viewDecodeSkyencoder,
+	},
+	{
+		Name:            "cgbe",
+		Encode:          encodeCodecgenBigEndian,
+		Decode:          decodeCodecgenBigEndian,
+		DecodeCanonical: decodeCodecgenBigEndian,
+	},
+	{
+		Name:            "cgxdr",
+		Encode:          encodeCodecgenXDR,
+		Decode:          decodeCodecgenXDR,
+		DecodeCanonical: decodeCodecgenXDR,
+	},
+	{
+		Name:            "cgvarint",
+		Encode:          encodeCodecgenVarint,
+		Decode:          decodeCodecgenVarint,
+		DecodeCanonical: decodeCodecgenVarintCanonical,
+	},
+	{
+		Name:   "dict",
+		Encode: encodeDict,
+		Decode: decodeDict,
+	},
+	{
+		Name:   "avro",
+		Encode: encodeAvro,
+		Decode: decodeAvro,
+	},
+}
+
+// CodecByName returns the codec with the given name
+func CodecByName(name string) (Codec, error) {
+	for _, c := range Codecs {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Codec{}, fmt.Errorf("unknown codec %q", name)
+}
+
+// maxLenSkyencoder is the maximum slice length accepted by the skyencoder decoder,
+// which is also the -max-len the codecgen files are generated with
+const maxLenSkyencoder = 65535
+
+// capped returns a copy of the limits with the element counts capped to maxLen and the size capped to maxBytes,
+// for decoders which have their own fixed limits, so that those are reported with a *LimitError too.
+// A zero cap is ignored.
+func (l DecodeLimits) capped(maxLen, maxBytes int) DecodeLimits {
+	capTo := func(v, max int) int {
+		if max > 0 && (v <= 0 || v > max) {
+			return max
+		}
+		return v
+	}
+
+	l.MaxBytes = capTo(l.MaxBytes, maxBytes)
+	l.MaxTransactions = capTo(l.MaxTransactions, maxLen)
+	l.MaxSigs = capTo(l.MaxSigs, maxLen)
+	l.MaxInputs = capTo(l.MaxInputs, maxLen)
+	l.MaxOutputs = capTo(l.MaxOutputs, maxLen)
+	return l
+}
+
+func encodeSky(obj *coin.SignedBlock) ([]byte, error) {
+	return encoder.Serialize(*obj), nil
+}
+
+func decodeSky(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(skyLayout, buf, limits); err != nil {
+		return 0, err
+	}
+	n, err := encoder.DeserializeRawToValue(buf, reflect.ValueOf(obj))
+	return n, encoderError(err)
+}
+
+func encodeSkyencoder(obj *coin.SignedBlock) ([]byte, error) {
+	buf := make([]byte, EncodeSizeSignedBlock(obj))
+	if err := EncodeSignedBlock(buf, obj); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func decodeSkyencoder(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(skyLayout, buf, limits.capped(maxLenSkyencoder, 0)); err != nil {
+		return 0, err
+	}
+	n, err := DecodeSignedBlock(buf, obj)
+	return n, encoderError(err)
+}
+
+func encodeXDR2(obj *coin.SignedBlock) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := xdr.Marshal(&buf, obj); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeXDR2(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(xdr2Layout, buf, limits); err != nil {
+		return 0, err
+	}
+	n, err := xdr.Unmarshal(bytes.NewReader(buf), obj)
+	return n, xdrError(err)
+}
+
+func encodeJSON(obj *coin.SignedBlock) ([]byte, error) {
+	return json.Marshal(obj)
+}
+
+func decodeJSON(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkJSONLimits(buf, limits); err != nil {
+		return 0, err
+	}
+	d := json.NewDecoder(bytes.NewReader(buf))
+	if err := d.Decode(obj); err != nil {
+		return 0, jsonError(err, d.InputOffset())
+	}
+
+	// Anything but whitespace after the value is another token
+	n := int(d.InputOffset())
+	if _, err := d.Token(); err != io.EOF {
+		return 0, trailingBytesError(n)
+	}
+
+	if err := checkDecodedLimits(obj, limits); err != nil {
+		return 0, err
+	}
+
+	return len(buf), nil
+}
+
+func encodeGotiny(obj *coin.SignedBlock) ([]byte, error) {
+	return gotiny.NewEncoder(coin.SignedBlock{}).Encode(*obj), nil
+}
+
+func decodeGotiny(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (n int, err error) {
+	if err := checkLayoutLimits(gotinyLayout, buf, limits); err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			n = 0
+			err = panicError(r)
+		}
+	}()
+
+	n = gotiny.NewDecoder(coin.SignedBlock{}).Decode(buf, obj)
+	if n != len(buf) {
+		return 0, trailingBytesError(n)
+	}
+
+	return n, nil
+}
+
+func encodeColfer(obj *coin.SignedBlock) ([]byte, error) {
+	return blockToColfer(*obj).MarshalBinary()
+}
+
+func decodeColfer(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkColferLimits(buf, limits.capped(ColferListMax, ColferSizeMax)); err != nil {
+		return 0, err
+	}
+	return unmarshalColfer(buf, obj)
+}
+
+func decodeColferCanonical(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkCanonicalColfer(buf, limits.capped(ColferListMax, ColferSizeMax)); err != nil {
+		return 0, err
+	}
+	return unmarshalColfer(buf, obj)
+}
+
+// unmarshalColfer decodes a payload which has been scanned
+func unmarshalColfer(buf []byte, obj *coin.SignedBlock) (int, error) {
+	var c ColferSignedBlock
+	n, err := c.Unmarshal(buf)
+	if err != nil {
+		return 0, colferError(err)
+	}
+
+	*obj, err = colferToBlock(&c)
+	if err != nil {
+		return 0, &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: -1,
+			Err:    err,
+		}
+	}
+
+	return n, nil
+}
+
+func encodeGencode(obj *coin.SignedBlock) ([]byte, error) {
+	return blockToGencode(*obj).Marshal(nil)
+}
+
+func decodeGencode(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(gencodeLayout, buf, limits); err != nil {
+		return 0, err
+	}
+	return unmarshalGencode(buf, obj)
+}
+
+func decodeGencodeCanonical(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkCanonicalLayout(gencodeLayout, buf, limits); err != nil {
+		return 0, err
+	}
+	return unmarshalGencode(buf, obj)
+}
+
+// unmarshalGencode decodes a payload which has been scanned
+func unmarshalGencode(buf []byte, obj *coin.SignedBlock) (n int, err error) {
+	// gencode does not check bounds and panics on a truncated payload
+	defer func() {
+		if r := recover(); r != nil {
+			n = 0
+			err = panicError(r)
+		}
+	}()
+
+	var g GencodeSignedBlock
+	m, err := g.Unmarshal(buf)
+	if err != nil {
+		return 0, &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: -1,
+			Err:    err,
+		}
+	}
+
+	*obj = gencodeToBlock(&g)
+	return int(m), nil
+}
+
+func encodeGencodeVarint(obj *coin.SignedBlock) ([]byte, error) {
+	return blockToGencodeVarint(*obj).Marshal(nil)
+}
+
+func decodeGencodeVarint(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(gencodeVarintLayout, buf, limits); err != nil {
+		return 0, err
+	}
+	return unmarshalGencodeVarint(buf, obj)
+}
+
+func decodeGencodeVarintCanonical(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkCanonicalLayout(gencodeVarintLayout, buf, limits); err != nil {
+		return 0, err
+	}
+	return unmarshalGencodeVarint(buf, obj)
+}
+
+// unmarshalGencodeVarint decodes a payload which has been scanned
+func unmarshalGencodeVarint(buf []byte, obj *coin.SignedBlock) (n int, err error) {
+	// gencode does not check bounds and panics on a truncated payload
+	defer func() {
+		if r := recover(); r != nil {
+			n = 0
+			err = panicError(r)
+		}
+	}()
+
+	var g GencodeVarintSignedBlock
+	m, err := g.Unmarshal(buf)
+	if err != nil {
+		return 0, &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: -1,
+			Err:    err,
+		}
+	}
+
+	*obj = gencodeVarintToBlock(&g)
+	return int(m), nil
+}
+
+func encodeCodecgenFixed(obj *coin.SignedBlock) ([]byte, error) {
+	buf := make([]byte, EncodeSizeSignedBlockFixed(obj))
+	if err := EncodeSignedBlockFixed(buf, obj); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func decodeCodecgenFixed(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(skyLayout, buf, limits.capped(maxLenSkyencoder, 0)); err != nil {
+		return 0, err
+	}
+	n, err := DecodeSignedBlockFixed(buf, obj)
+	return n, encoderError(err)
+}
+
+func encodeCodecgenBigEndian(obj *coin.SignedBlock) ([]byte, error) {
+	buf := make([]byte, EncodeSizeSignedBlockBigEndian(obj))
+	if err := EncodeSignedBlockBigEndian(buf, obj); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func decodeCodecgenBigEndian(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(bigEndianLayout, buf, limits.capped(maxLenSkyencoder, 0)); err != nil {
+		return 0, err
+	}
+	n, err := DecodeSignedBlockBigEndian(buf, obj)
+	return n, encoderError(err)
+}
+
+func encodeCodecgenXDR(obj *coin.SignedBlock) ([]byte, error) {
+	buf := make([]byte, EncodeSizeSignedBlockXDR(obj))
+	if err := EncodeSignedBlockXDR(buf, obj); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func decodeCodecgenXDR(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(xdrUnpaddedLayout, buf, limits.capped(maxLenSkyencoder, 0)); err != nil {
+		return 0, err
+	}
+	n, err := DecodeSignedBlockXDR(buf, obj)
+	return n, encoderError(err)
+}
+
+func encodeCodecgenVarint(obj *coin.SignedBlock) ([]byte, error) {
+	buf := make([]byte, EncodeSizeSignedBlockVarint(obj))
+	if err := EncodeSignedBlockVarint(buf, obj); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func decodeCodecgenVarint(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkLayoutLimits(codecgenVarintLayout, buf, limits.capped(maxLenSkyencoder, 0)); err != nil {
+		return 0, err
+	}
+	n, err := DecodeSignedBlockVarint(buf, obj)
+	return n, encoderError(err)
+}
+
+func decodeCodecgenVarintCanonical(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkCanonicalLayout(codecgenVarintLayout, buf, limits.capped(maxLenSkyencoder, 0)); err != nil {
+		return 0, err
+	}
+	n, err := DecodeSignedBlockVarintCanonical(buf, obj)
+	return n, encoderError(err)
+}
+
+func encodeDict(obj *coin.SignedBlock) ([]byte, error) {
+	return NewDictEncoder(false).Encode(nil, obj), nil
+}
+
+func decodeDict(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	return NewDictDecoder(false).Decode(buf, obj, limits)
+}
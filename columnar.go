@@ -0,0 +1,417 @@
+package serializebench
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// A transaction column batch encodes a batch of coin.Transaction column by column, rather than transaction by transaction.
+// It is a uvarint transaction count followed by each column, prefixed with its size in bytes as a uvarint,
+// so that a reader can skip to the columns it needs:
+//
+//	Length       zigzag varint difference from the previous transaction's Length, per transaction
+//	Type         1 byte per transaction
+//	InnerHash    32 bytes per transaction
+//	SigCount     uvarint per transaction
+//	Sigs         65 bytes per signature
+//	InCount      uvarint per transaction
+//	In           32 bytes per input
+//	OutCount     uvarint per transaction
+//	Out.Address  21 bytes per output, the version followed by the key
+//	Out.Coins    uvarint per output
+//	Out.Hours    uvarint per output
+
+type txnColumn int
+
+const (
+	colLength txnColumn = iota
+	colType
+	colInnerHash
+	colSigCount
+	colSigs
+	colInCount
+	colIn
+	colOutCount
+	colOutAddress
+	colOutCoins
+	colOutHours
+
+	txnColumns
+)
+
+var txnColumnNames = [txnColumns]string{
+	colLength:     "Length",
+	colType:       "Type",
+	colInnerHash:  "InnerHash",
+	colSigCount:   "SigCount",
+	colSigs:       "Sigs",
+	colInCount:    "InCount",
+	colIn:         "In",
+	colOutCount:   "OutCount",
+	colOutAddress: "Out.Address",
+	colOutCoins:   "Out.Coins",
+	colOutHours:   "Out.Hours",
+}
+
+const addressSize = 1 + len(cipher.Ripemd160{})
+
+// EncodeTransactionColumns appends txns, encoded as a transaction column batch, to buf
+func EncodeTransactionColumns(buf []byte, txns []coin.Transaction) []byte {
+	var cols [txnColumns][]byte
+
+	var length uint32
+	for i := range txns {
+		txn := &txns[i]
+
+		cols[colLength] = appendVarint(cols[colLength], int64(int32(txn.Length-length)))
+		length = txn.Length
+		cols[colType] = append(cols[colType], txn.Type)
+		cols[colInnerHash] = append(cols[colInnerHash], txn.InnerHash[:]...)
+
+		cols[colSigCount] = appendUvarint(cols[colSigCount], uint64(len(txn.Sigs)))
+		for j := range txn.Sigs {
+			cols[colSigs] = append(cols[colSigs], txn.Sigs[j][:]...)
+		}
+
+		cols[colInCount] = appendUvarint(cols[colInCount], uint64(len(txn.In)))
+		for j := range txn.In {
+			cols[colIn] = append(cols[colIn], txn.In[j][:]...)
+		}
+
+		cols[colOutCount] = appendUvarint(cols[colOutCount], uint64(len(txn.Out)))
+		for j := range txn.Out {
+			o := &txn.Out[j]
+			cols[colOutAddress] = append(cols[colOutAddress], o.Address.Version)
+			cols[colOutAddress] = append(cols[colOutAddress], o.Address.Key[:]...)
+			cols[colOutCoins] = appendUvarint(cols[colOutCoins], o.Coins)
+			cols[colOutHours] = appendUvarint(cols[colOutHours], o.Hours)
+		}
+	}
+
+	buf = appendUvarint(buf, uint64(len(txns)))
+	for _, col := range cols {
+		buf = appendUvarint(buf, uint64(len(col)))
+		buf = append(buf, col...)
+	}
+
+	return buf
+}
+
+// TransactionColumns is a transaction column batch, whose columns can be decoded separately
+type TransactionColumns struct {
+	data   []byte
+	n      int
+	limits DecodeLimits
+	// cols are the start and end offsets of each column in data
+	cols [txnColumns][2]int
+}
+
+// OpenTransactionColumns locates the columns of the transaction column batch in buf, without decoding them.
+// limits.MaxTransactions bounds the number of transactions in the batch.
+// Errors are a *DecodeError, with the column name as the path.
+func OpenTransactionColumns(buf []byte, limits DecodeLimits) (*TransactionColumns, error) {
+	if err := checkLimit("MaxBytes", limits.MaxBytes, uint64(len(buf)), -1, ""); err != nil {
+		return nil, err
+	}
+
+	s := newLimitScanner(wireLayout{varintLength: true}, buf)
+	n, err := s.length("Transactions", "MaxTransactions", limits.MaxTransactions)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TransactionColumns{
+		data:   buf,
+		n:      int(n),
+		limits: limits,
+	}
+
+	for c := range t.cols {
+		name := txnColumnNames[c]
+		offset := s.offset()
+		size, err := s.uvarint(name)
+		if err != nil {
+			return nil, err
+		}
+		if size > uint64(len(s.buf)) {
+			return nil, s.fail(ErrTruncated, offset, name, fmt.Errorf("column size %d exceeds the remaining %d bytes", size, len(s.buf)))
+		}
+
+		t.cols[c] = [2]int{s.offset(), s.offset() + int(size)}
+		s.buf = s.buf[size:]
+	}
+
+	if err := s.end(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Len returns the number of transactions in the batch
+func (t *TransactionColumns) Len() int {
+	return t.n
+}
+
+// column returns a scanner over column c
+func (t *TransactionColumns) column(c txnColumn) *limitScanner {
+	s := newLimitScanner(wireLayout{}, t.data[:t.cols[c][1]])
+	s.buf = s.data[t.cols[c][0]:]
+	s.enter(txnColumnNames[c])
+	return &s
+}
+
+// fixed returns column c, whose values are size bytes each, and the number of values
+func (t *TransactionColumns) fixed(c txnColumn, size int) ([]byte, int, error) {
+	s := t.column(c)
+	if len(s.buf)%size != 0 {
+		return nil, 0, s.fail(ErrMalformed, s.offset(), "", fmt.Errorf("column size %d is not a multiple of %d", len(s.buf), size))
+	}
+	return s.buf, len(s.buf) / size, nil
+}
+
+// uvarints decodes column c. A column of uvarints holds at most one value per byte.
+func (t *TransactionColumns) uvarints(c txnColumn) ([]uint64, error) {
+	s := t.column(c)
+	values := make([]uint64, 0, len(s.buf))
+	for len(s.buf) != 0 {
+		s.at(uint64(len(values)))
+		x, err := s.uvarint("")
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, x)
+	}
+	return values, nil
+}
+
+// counts decodes the count column c, whose values are bounded by a limit, and returns the sum of the counts
+func (t *TransactionColumns) counts(c txnColumn, limit string) ([]uint64, int, error) {
+	s := t.column(c)
+	max := t.limits.max(limit)
+
+	counts := make([]uint64, t.n)
+	total := 0
+	for i := range counts {
+		s.at(uint64(i))
+		offset := s.offset()
+		x, err := s.uvarint("")
+		if err != nil {
+			return nil, 0, err
+		}
+		if max > 0 && x > uint64(max) {
+			return nil, 0, checkLimit(limit, max, x, offset, s.fieldPath(""))
+		}
+		// Every element takes at least one byte in another column
+		if x > uint64(len(t.data)) {
+			return nil, 0, s.fail(ErrMalformed, offset, "", fmt.Errorf("count %d exceeds the batch size", x))
+		}
+		counts[i] = x
+		total += int(x)
+	}
+
+	if err := s.end(); err != nil {
+		return nil, 0, err
+	}
+
+	return counts, total, nil
+}
+
+// checkCount returns a *DecodeError if column c does not have the expected number of values
+func (t *TransactionColumns) checkCount(c txnColumn, got, expected int) error {
+	if got == expected {
+		return nil
+	}
+	s := t.column(c)
+	return s.fail(ErrMalformed, t.cols[c][0], "", fmt.Errorf("column has %d values, expected %d", got, expected))
+}
+
+// Lengths decodes the Length column
+func (t *TransactionColumns) Lengths() ([]uint32, error) {
+	s := t.column(colLength)
+	lengths := make([]uint32, t.n)
+
+	var length uint32
+	for i := range lengths {
+		s.at(uint64(i))
+		offset := s.offset()
+		d, err := s.varint("")
+		if err != nil {
+			return nil, err
+		}
+		if d != int64(int32(d)) {
+			return nil, s.fail(ErrMalformed, offset, "", fmt.Errorf("difference %d overflows int32", d))
+		}
+		length += uint32(d)
+		lengths[i] = length
+	}
+
+	if err := s.end(); err != nil {
+		return nil, err
+	}
+
+	return lengths, nil
+}
+
+// Types decodes the Type column
+func (t *TransactionColumns) Types() ([]uint8, error) {
+	col, n, err := t.fixed(colType, 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkCount(colType, n, t.n); err != nil {
+		return nil, err
+	}
+	return append([]uint8(nil), col...), nil
+}
+
+// InnerHashes decodes the InnerHash column
+func (t *TransactionColumns) InnerHashes() ([]cipher.SHA256, error) {
+	col, n, err := t.fixed(colInnerHash, len(cipher.SHA256{}))
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkCount(colInnerHash, n, t.n); err != nil {
+		return nil, err
+	}
+	return hashColumn(col, n), nil
+}
+
+func hashColumn(col []byte, n int) []cipher.SHA256 {
+	hashes := make([]cipher.SHA256, n)
+	for i := range hashes {
+		copy(hashes[i][:], col[i*len(hashes[i]):])
+	}
+	return hashes
+}
+
+// Addresses decodes the Out.Address column, the addresses of the outputs of all transactions
+func (t *TransactionColumns) Addresses() ([]cipher.Address, error) {
+	col, n, err := t.fixed(colOutAddress, addressSize)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]cipher.Address, n)
+	for i := range addresses {
+		v := col[i*addressSize:]
+		addresses[i].Version = v[0]
+		copy(addresses[i].Key[:], v[1:])
+	}
+	return addresses, nil
+}
+
+// Coins decodes the Out.Coins column, the coins of the outputs of all transactions
+func (t *TransactionColumns) Coins() ([]uint64, error) {
+	return t.uvarints(colOutCoins)
+}
+
+// Hours decodes the Out.Hours column, the hours of the outputs of all transactions
+func (t *TransactionColumns) Hours() ([]uint64, error) {
+	return t.uvarints(colOutHours)
+}
+
+// Transactions decodes every column and returns the transactions of the batch
+func (t *TransactionColumns) Transactions() ([]coin.Transaction, error) {
+	lengths, err := t.Lengths()
+	if err != nil {
+		return nil, err
+	}
+	types, err := t.Types()
+	if err != nil {
+		return nil, err
+	}
+	innerHashes, err := t.InnerHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	sigCounts, nSigs, err := t.counts(colSigCount, "MaxSigs")
+	if err != nil {
+		return nil, err
+	}
+	sigs, n, err := t.fixed(colSigs, len(cipher.Sig{}))
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkCount(colSigs, n, nSigs); err != nil {
+		return nil, err
+	}
+
+	inCounts, nIn, err := t.counts(colInCount, "MaxInputs")
+	if err != nil {
+		return nil, err
+	}
+	inCol, n, err := t.fixed(colIn, len(cipher.SHA256{}))
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkCount(colIn, n, nIn); err != nil {
+		return nil, err
+	}
+	in := hashColumn(inCol, n)
+
+	outCounts, nOut, err := t.counts(colOutCount, "MaxOutputs")
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := t.Addresses()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkCount(colOutAddress, len(addresses), nOut); err != nil {
+		return nil, err
+	}
+	coins, err := t.Coins()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkCount(colOutCoins, len(coins), nOut); err != nil {
+		return nil, err
+	}
+	hours, err := t.Hours()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkCount(colOutHours, len(hours), nOut); err != nil {
+		return nil, err
+	}
+
+	txns := make([]coin.Transaction, t.n)
+	for i := range txns {
+		txn := &txns[i]
+		txn.Length = lengths[i]
+		txn.Type = types[i]
+		txn.InnerHash = innerHashes[i]
+
+		if n := sigCounts[i]; n != 0 {
+			txn.Sigs = make([]cipher.Sig, n)
+			for j := range txn.Sigs {
+				sigs = sigs[copy(txn.Sigs[j][:], sigs):]
+			}
+		}
+
+		if n := inCounts[i]; n != 0 {
+			txn.In = in[:n:n]
+			in = in[n:]
+		}
+
+		if n := outCounts[i]; n != 0 {
+			txn.Out = make([]coin.TransactionOutput, n)
+			for j := range txn.Out {
+				txn.Out[j] = coin.TransactionOutput{
+					Address: addresses[j],
+					Coins:   coins[j],
+					Hours:   hours[j],
+				}
+			}
+			addresses = addresses[n:]
+			coins = coins[n:]
+			hours = hours[n:]
+		}
+	}
+
+	return txns, nil
+}
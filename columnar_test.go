@@ -0,0 +1,358 @@
+package serializebench
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// columnChainSize is the number of generated blocks whose transactions make a column batch
+const columnChainSize = 1000
+
+func chainTransactions(n int) []coin.Transaction {
+	var txns []coin.Transaction
+	for _, b := range GenerateChain(n, 1) {
+		txns = append(txns, b.Block.Body.Transactions...)
+	}
+	return txns
+}
+
+// encodeGencodeVarintTransactions encodes txns row by row, as a gencode varint block body
+func encodeGencodeVarintTransactions(buf []byte, txns []coin.Transaction) ([]byte, error) {
+	var block coin.SignedBlock
+	block.Block.Body.Transactions = txns
+	return blockToGencodeVarint(block).Block.Body.Marshal(buf)
+}
+
+func decodeGencodeVarintTransactions(buf []byte) ([]coin.Transaction, error) {
+	var g GencodeVarintSignedBlock
+	if _, err := g.Block.Body.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	return gencodeVarintToBlock(&g).Block.Body.Transactions, nil
+}
+
+func TestTransactionColumnsRoundTrip(t *testing.T) {
+	block := getBlock()
+
+	cases := map[string][]coin.Transaction{
+		"empty": nil,
+		"block": block.Block.Body.Transactions,
+		"zero":  make([]coin.Transaction, 3),
+		"chain": chainTransactions(100),
+		"mixed": append(append([]coin.Transaction{{Length: 1 << 31}}, block.Block.Body.Transactions...), coin.Transaction{}),
+	}
+
+	for name, txns := range cases {
+		t.Run(name, func(t *testing.T) {
+			data := EncodeTransactionColumns(nil, txns)
+
+			cols, err := OpenTransactionColumns(data, DefaultDecodeLimits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cols.Len() != len(txns) {
+				t.Errorf("expected %d transactions, got %d", len(txns), cols.Len())
+			}
+
+			result, err := cols.Transactions()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(txns, result, cmpopts.EquateEmpty()) {
+				t.Error(cmp.Diff(txns, result, cmpopts.EquateEmpty()))
+			}
+		})
+	}
+}
+
+func TestTransactionColumnsProjection(t *testing.T) {
+	txns := chainTransactions(100)
+
+	var coins, hours []uint64
+	var lengths []uint32
+	for _, txn := range txns {
+		lengths = append(lengths, txn.Length)
+		for _, o := range txn.Out {
+			coins = append(coins, o.Coins)
+			hours = append(hours, o.Hours)
+		}
+	}
+
+	cols, err := OpenTransactionColumns(EncodeTransactionColumns(nil, txns), DefaultDecodeLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resultCoins, err := cols.Coins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(coins, resultCoins) {
+		t.Error(cmp.Diff(coins, resultCoins))
+	}
+
+	resultHours, err := cols.Hours()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(hours, resultHours) {
+		t.Error(cmp.Diff(hours, resultHours))
+	}
+
+	resultLengths, err := cols.Lengths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(lengths, resultLengths) {
+		t.Error(cmp.Diff(lengths, resultLengths))
+	}
+}
+
+func TestTransactionColumnsErrors(t *testing.T) {
+	block := getBlock()
+	data := EncodeTransactionColumns(nil, block.Block.Body.Transactions)
+
+	t.Run("truncated", func(t *testing.T) {
+		for i := 0; i < len(data); i++ {
+			_, err := OpenTransactionColumns(data[:i], DefaultDecodeLimits)
+			if !errors.Is(err, ErrTruncated) {
+				t.Fatalf("prefix %d: expected ErrTruncated, got %v", i, err)
+			}
+		}
+	})
+
+	t.Run("trailing bytes", func(t *testing.T) {
+		_, err := OpenTransactionColumns(append(data[:len(data):len(data)], 0), DefaultDecodeLimits)
+		if !errors.Is(err, ErrTrailingBytes) {
+			t.Fatalf("expected ErrTrailingBytes, got %v", err)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		limits := DefaultDecodeLimits
+		limits.MaxOutputs = 1
+
+		cols, err := OpenTransactionColumns(data, limits)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Projections do not check the per-transaction limits
+		if _, err := cols.Coins(); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = cols.Transactions()
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("expected ErrLimitExceeded, got %v", err)
+		}
+		if decodeErr.Path != "OutCount[0]" {
+			t.Errorf("expected path OutCount[0], got %q", decodeErr.Path)
+		}
+	})
+
+	t.Run("count mismatch", func(t *testing.T) {
+		// Encode a batch whose Out.Hours column is missing the last value
+		txns := append([]coin.Transaction(nil), block.Block.Body.Transactions...)
+		last := &txns[len(txns)-1]
+		last.Out = last.Out[:len(last.Out)-1]
+		short := EncodeTransactionColumns(nil, txns)
+
+		cols, err := OpenTransactionColumns(data, DefaultDecodeLimits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shortCols, err := OpenTransactionColumns(short, DefaultDecodeLimits)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Splice the short Out.Hours column into the full batch
+		var spliced []byte
+		spliced = appendUvarint(spliced, uint64(cols.Len()))
+		for c := range cols.cols {
+			src := cols
+			if txnColumn(c) == colOutHours {
+				src = shortCols
+			}
+			col := src.data[src.cols[c][0]:src.cols[c][1]]
+			spliced = appendUvarint(spliced, uint64(len(col)))
+			spliced = append(spliced, col...)
+		}
+
+		splicedCols, err := OpenTransactionColumns(spliced, DefaultDecodeLimits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = splicedCols.Transactions()
+		if !errors.Is(err, ErrMalformed) {
+			t.Fatalf("expected ErrMalformed, got %v", err)
+		}
+	})
+}
+
+// TestTransactionColumnsSize prints the size of the transactions of a generated chain
+// as a column batch and as gencode varint rows
+func TestTransactionColumnsSize(t *testing.T) {
+	txns := chainTransactions(columnChainSize)
+
+	rows, err := encodeGencodeVarintTransactions(nil, txns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := EncodeTransactionColumns(nil, txns)
+
+	fmt.Printf("%-12s %-8s %12s %8s\n", "format", "algo", "bytes", "ratio")
+	for _, z := range append([]*Compressor{nil}, Compressors...) {
+		zRows, zColumns := rows, columns
+		name := "none"
+		if z != nil {
+			name = z.Name
+			if zRows, err = z.Compress(rows); err != nil {
+				t.Fatal(err)
+			}
+			if zColumns, err = z.Compress(columns); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		fmt.Printf("%-12s %-8s %12d %8.3f\n", "gencodevar", name, len(zRows), float64(len(zRows))/float64(len(rows)))
+		fmt.Printf("%-12s %-8s %12d %8.3f\n", "columns", name, len(zColumns), float64(len(zColumns))/float64(len(rows)))
+	}
+}
+
+/* transaction columns
+
+- Batches of transactions from a generated chain
+*/
+
+func BenchmarkMarshalTransactionsByGencodeVarint(b *testing.B) {
+	txns := chainTransactions(compressBatchSize)
+	buf := make([]byte, 0, 1024*1024)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = encodeGencodeVarintTransactions(buf[:0], txns); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalTransactionsByColumns(b *testing.B) {
+	txns := chainTransactions(compressBatchSize)
+	buf := make([]byte, 0, 1024*1024)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		buf = EncodeTransactionColumns(buf[:0], txns)
+	}
+}
+
+func BenchmarkUnmarshalTransactionsByGencodeVarint(b *testing.B) {
+	txns := chainTransactions(compressBatchSize)
+	data, err := encodeGencodeVarintTransactions(nil, txns)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		result, err := decodeGencodeVarintTransactions(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if validate {
+			if !cmp.Equal(result, txns) {
+				b.Fatal("gencode varint unmarshal result differs")
+			}
+		}
+	}
+}
+
+func BenchmarkUnmarshalTransactionsByColumns(b *testing.B) {
+	txns := chainTransactions(compressBatchSize)
+	data := EncodeTransactionColumns(nil, txns)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		cols, err := OpenTransactionColumns(data, DefaultDecodeLimits)
+		if err != nil {
+			b.Fatal(err)
+		}
+		result, err := cols.Transactions()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if validate {
+			if !cmp.Equal(result, txns) {
+				b.Fatal("columns unmarshal result differs")
+			}
+		}
+	}
+}
+
+// The Coins benchmarks sum the coins of every output. The gencode varint rows must be decoded entirely.
+
+func BenchmarkUnmarshalCoinsByGencodeVarint(b *testing.B) {
+	data, err := encodeGencodeVarintTransactions(nil, chainTransactions(compressBatchSize))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var body GencodeVarintBlockBody
+		if _, err := body.Unmarshal(data); err != nil {
+			b.Fatal(err)
+		}
+
+		var sum uint64
+		for _, txn := range body.Transactions {
+			for _, o := range txn.Out {
+				sum += o.Coins
+			}
+		}
+	}
+}
+
+func BenchmarkUnmarshalCoinsByColumns(b *testing.B) {
+	data := EncodeTransactionColumns(nil, chainTransactions(compressBatchSize))
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		cols, err := OpenTransactionColumns(data, DefaultDecodeLimits)
+		if err != nil {
+			b.Fatal(err)
+		}
+		coins, err := cols.Coins()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		var sum uint64
+		for _, c := range coins {
+			sum += c
+		}
+	}
+}
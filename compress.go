@@ -0,0 +1,172 @@
+package serializebench
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// compressWriter is a compressing writer which can be reused for another output
+type compressWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// Compressor is a compression algorithm from the standard library which can be layered on a Codec
+type Compressor struct {
+	// Name is the name of the algorithm
+	Name string
+
+	newWriter func(w io.Writer) compressWriter
+	newReader func(r io.Reader) (io.ReadCloser, error)
+
+	// writers reuses compressors, which allocate large tables
+	writers sync.Pool
+}
+
+// lzwWriter adapts *lzw.Writer to compressWriter
+type lzwWriter struct {
+	*lzw.Writer
+}
+
+func (w lzwWriter) Reset(dst io.Writer) {
+	w.Writer.Reset(dst, lzw.LSB, 8)
+}
+
+// Compressors are the compression algorithms from the standard library, at their default levels
+var Compressors = []*Compressor{
+	{
+		Name: "flate",
+		newWriter: func(w io.Writer) compressWriter {
+			fw, err := flate.NewWriter(w, flate.DefaultCompression)
+			if err != nil {
+				panic(err)
+			}
+			return fw
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(r), nil
+		},
+	},
+	{
+		Name: "gzip",
+		newWriter: func(w io.Writer) compressWriter {
+			return gzip.NewWriter(w)
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	{
+		Name: "zlib",
+		newWriter: func(w io.Writer) compressWriter {
+			return zlib.NewWriter(w)
+		},
+		newReader: zlib.NewReader,
+	},
+	{
+		Name: "lzw",
+		newWriter: func(w io.Writer) compressWriter {
+			return lzwWriter{lzw.NewWriter(w, lzw.LSB, 8).(*lzw.Writer)}
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return lzw.NewReader(r, lzw.LSB, 8), nil
+		},
+	},
+}
+
+// Compress returns data compressed
+func (c *Compressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, ok := c.writers.Get().(compressWriter)
+	if ok {
+		w.Reset(&buf)
+	} else {
+		w = c.newWriter(&buf)
+	}
+	defer c.writers.Put(w)
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress returns data decompressed. If the decompressed data exceeds maxBytes,
+// a *DecodeError of kind ErrLimitExceeded is returned before more is decompressed.
+// A zero maxBytes means no limit.
+func (c *Compressor) Decompress(data []byte, maxBytes int) ([]byte, error) {
+	r, err := c.newReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, decompressError(err)
+	}
+	defer r.Close()
+
+	var lr io.Reader = r
+	if maxBytes > 0 {
+		// Read one byte past the limit to detect an oversized payload
+		lr = io.LimitReader(r, int64(maxBytes)+1)
+	}
+
+	out, err := ioutil.ReadAll(lr)
+	if err != nil {
+		return nil, decompressError(err)
+	}
+
+	if err := checkLimit("MaxBytes", maxBytes, uint64(len(out)), -1, ""); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// decompressError maps an error from a decompressor
+func decompressError(err error) error {
+	kind := ErrMalformed
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		kind = ErrTruncated
+	}
+
+	return &DecodeError{
+		Kind:   kind,
+		Offset: -1,
+		Err:    err,
+	}
+}
+
+// Compressed returns a Codec which compresses the encoding of codec with c.
+// The offsets of decode errors from codec are offsets in the decompressed payload.
+func Compressed(codec Codec, c *Compressor) Codec {
+	return Codec{
+		Name: codec.Name + "+" + c.Name,
+		Encode: func(obj *coin.SignedBlock) ([]byte, error) {
+			data, err := codec.Encode(obj)
+			if err != nil {
+				return nil, err
+			}
+			return c.Compress(data)
+		},
+		Decode: func(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+			data, err := c.Decompress(buf, limits.MaxBytes)
+			if err != nil {
+				return 0, err
+			}
+			if _, err := codec.Decode(data, obj, limits); err != nil {
+				return 0, err
+			}
+			return len(buf), nil
+		},
+	}
+}
@@ -0,0 +1,246 @@
+package serializebench
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// compressBatchSize is the number of blocks in a compressed batch
+const compressBatchSize = 100
+
+func encodeBatch(c Codec, blocks []coin.SignedBlock) ([]byte, error) {
+	var buf bytes.Buffer
+	w := NewBlockWriter(&buf, c)
+	for i := range blocks {
+		if _, err := w.Write(&blocks[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeBatch(c Codec, data []byte, blocks []coin.SignedBlock) error {
+	r := NewBlockReader(bytes.NewReader(data), c, DefaultDecodeLimits)
+	for i := range blocks {
+		if _, err := r.Next(&blocks[i]); err != nil {
+			return err
+		}
+	}
+	if _, err := r.Next(&coin.SignedBlock{}); err != io.EOF {
+		return fmt.Errorf("expected the end of the batch, got %v", err)
+	}
+	return nil
+}
+
+func TestCompressedRoundTrip(t *testing.T) {
+	block := getBlock()
+
+	for _, c := range Codecs {
+		for _, z := range Compressors {
+			codec := Compressed(c, z)
+			t.Run(codec.Name, func(t *testing.T) {
+				data, err := codec.Encode(&block)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var result coin.SignedBlock
+				if _, err := codec.Decode(data, &result, DefaultDecodeLimits); err != nil {
+					t.Fatal(err)
+				}
+				if !cmp.Equal(block, result) {
+					t.Error(cmp.Diff(block, result))
+				}
+
+				_, err = codec.Decode(data[:len(data)/2], &result, DefaultDecodeLimits)
+				if !errors.Is(err, ErrTruncated) {
+					t.Errorf("expected ErrTruncated, got %v", err)
+				}
+
+				// The decompressed size is limited
+				limits := DefaultDecodeLimits
+				limits.MaxBytes = 100
+				_, err = codec.Decode(data, &result, limits)
+				if !errors.Is(err, ErrLimitExceeded) {
+					t.Errorf("expected ErrLimitExceeded, got %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestCompressedBatchRoundTrip(t *testing.T) {
+	blocks := GenerateChain(compressBatchSize, 1)
+
+	for _, c := range Codecs {
+		for _, z := range Compressors {
+			t.Run(c.Name+"+"+z.Name, func(t *testing.T) {
+				data, err := encodeBatch(c, blocks)
+				if err != nil {
+					t.Fatal(err)
+				}
+				compressed, err := z.Compress(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				decompressed, err := z.Decompress(compressed, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				result := make([]coin.SignedBlock, len(blocks))
+				if err := decodeBatch(c, decompressed, result); err != nil {
+					t.Fatal(err)
+				}
+
+				// Comparing the re-encoded batch is much faster than comparing the blocks with cmp
+				reencoded, err := encodeBatch(c, result)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(data, reencoded) {
+					t.Error("decoded batch differs")
+				}
+			})
+		}
+	}
+}
+
+// TestCompressedSize prints the compressed size and compression ratio of each format
+// for the benchmarked block and for a batch of generated blocks
+func TestCompressedSize(t *testing.T) {
+	block := getBlock()
+	blocks := GenerateChain(compressBatchSize, 1)
+
+	fmt.Printf("%-12s %-8s %12s %8s %12s %8s\n", "format", "algo", "block bytes", "ratio", "batch bytes", "ratio")
+
+	for _, c := range Codecs {
+		single, err := c.Encode(&block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch, err := encodeBatch(c, blocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fmt.Printf("%-12s %-8s %12d %8.3f %12d %8.3f\n", c.Name, "none", len(single), 1.0, len(batch), 1.0)
+
+		for _, z := range Compressors {
+			zSingle, err := z.Compress(single)
+			if err != nil {
+				t.Fatal(err)
+			}
+			zBatch, err := z.Compress(batch)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fmt.Printf("%-12s %-8s %12d %8.3f %12d %8.3f\n", c.Name, z.Name,
+				len(zSingle), float64(len(zSingle))/float64(len(single)),
+				len(zBatch), float64(len(zBatch))/float64(len(batch)))
+		}
+	}
+}
+
+/* compression */
+
+func benchmarkCompressMarshal(b *testing.B, blocks []coin.SignedBlock) {
+	for _, c := range Codecs {
+		for _, z := range append([]*Compressor{nil}, Compressors...) {
+			name := c.Name + "/none"
+			if z != nil {
+				name = c.Name + "/" + z.Name
+			}
+
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+
+				var raw, size int
+				for i := 0; i < b.N; i++ {
+					data, err := encodeBatch(c, blocks)
+					if err != nil {
+						b.Fatal(err)
+					}
+					raw = len(data)
+
+					if z != nil {
+						if data, err = z.Compress(data); err != nil {
+							b.Fatal(err)
+						}
+					}
+					size = len(data)
+				}
+
+				b.ReportMetric(float64(size), "bytes")
+				b.ReportMetric(float64(size)/float64(raw), "ratio")
+			})
+		}
+	}
+}
+
+func benchmarkCompressUnmarshal(b *testing.B, blocks []coin.SignedBlock) {
+	for _, c := range Codecs {
+		for _, z := range append([]*Compressor{nil}, Compressors...) {
+			name := c.Name + "/none"
+			if z != nil {
+				name = c.Name + "/" + z.Name
+			}
+
+			b.Run(name, func(b *testing.B) {
+				data, err := encodeBatch(c, blocks)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if z != nil {
+					if data, err = z.Compress(data); err != nil {
+						b.Fatal(err)
+					}
+				}
+				result := make([]coin.SignedBlock, len(blocks))
+
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					raw := data
+					if z != nil {
+						if raw, err = z.Decompress(data, 0); err != nil {
+							b.Fatal(err)
+						}
+					}
+					if err := decodeBatch(c, raw, result); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// The single block benchmarks frame the block like a batch of one, which adds a length prefix
+
+func BenchmarkMarshalCompressedBlock(b *testing.B) {
+	benchmarkCompressMarshal(b, []coin.SignedBlock{getBlock()})
+}
+
+func BenchmarkUnmarshalCompressedBlock(b *testing.B) {
+	benchmarkCompressUnmarshal(b, []coin.SignedBlock{getBlock()})
+}
+
+func BenchmarkMarshalCompressedBatch(b *testing.B) {
+	benchmarkCompressMarshal(b, GenerateChain(compressBatchSize, 1))
+}
+
+func BenchmarkUnmarshalCompressedBatch(b *testing.B) {
+	benchmarkCompressUnmarshal(b, GenerateChain(compressBatchSize, 1))
+}
@@ -0,0 +1,468 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// The dict format is the codecgen varint format, except that each cipher.Address and cipher.SHA256 is
+// interned into a dictionary and written as a reference to an earlier occurrence when there is one.
+// A reference is a uvarint: 0 is followed by the literal value, which is added to the dictionary,
+// and n > 0 refers to the nth most recently used value. Signatures are always literal.
+//
+// In per-block mode the dictionaries are reset for each block. In stream mode they are kept across blocks,
+// and the hash of each block's header is added to the hash dictionary after the block,
+// so the next block's PrevHash is a one byte reference.
+
+var (
+	errDictReference   = errors.New("reference beyond the dictionary")
+	errDictIntOverflow = errors.New("varint overflows the field")
+)
+
+// dictWindow is the number of most recently used values a dictionary refers to
+const dictWindow = 4096
+
+// dictValue is an interned value: a cipher.SHA256, or a cipher.Address as its version followed by its key
+type dictValue [32]byte
+
+func addressDictValue(a cipher.Address) dictValue {
+	var v dictValue
+	v[0] = a.Version
+	copy(v[1:], a.Key[:])
+	return v
+}
+
+func (v dictValue) address() cipher.Address {
+	var a cipher.Address
+	a.Version = v[0]
+	copy(a.Key[:], v[1:])
+	return a
+}
+
+// dict is a window of the most recently used values. Only the encoder needs to look values up.
+type dict struct {
+	ring []dictValue
+	// pos is the position of the most recent use of each value, or nil in a decoder
+	pos map[dictValue]int
+	n   int
+}
+
+func newDict(lookup bool) dict {
+	var d dict
+	if lookup {
+		d.pos = make(map[dictValue]int)
+	}
+	return d
+}
+
+func (d *dict) reset() {
+	d.ring = d.ring[:0]
+	d.n = 0
+	for v := range d.pos {
+		delete(d.pos, v)
+	}
+}
+
+// add makes v the most recently used value
+func (d *dict) add(v dictValue) {
+	i := d.n % dictWindow
+	if len(d.ring) < dictWindow {
+		d.ring = append(d.ring, v)
+	} else {
+		if old := d.ring[i]; d.pos != nil && d.pos[old] == d.n-dictWindow {
+			delete(d.pos, old)
+		}
+		d.ring[i] = v
+	}
+
+	if d.pos != nil {
+		d.pos[v] = d.n
+	}
+	d.n++
+}
+
+// ref returns the reference to v, or 0 if v is not in the window, and makes v the most recently used value
+func (d *dict) ref(v dictValue) uint64 {
+	var r uint64
+	if p, ok := d.pos[v]; ok {
+		r = uint64(d.n - p)
+	}
+	d.add(v)
+	return r
+}
+
+// get returns the value referred to by r and makes it the most recently used value
+func (d *dict) get(r uint64) (dictValue, bool) {
+	if r == 0 || r > uint64(len(d.ring)) {
+		return dictValue{}, false
+	}
+	v := d.ring[(d.n-int(r))%dictWindow]
+	d.add(v)
+	return v, true
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], x)]...)
+}
+
+// DictEncoder encodes blocks in the dict format
+type DictEncoder struct {
+	stream    bool
+	addresses dict
+	hashes    dict
+}
+
+// NewDictEncoder returns a DictEncoder. If stream is set, the dictionaries are kept across blocks
+// and the blocks must be decoded in the same order by a stream mode DictDecoder.
+func NewDictEncoder(stream bool) *DictEncoder {
+	return &DictEncoder{
+		stream:    stream,
+		addresses: newDict(true),
+		hashes:    newDict(true),
+	}
+}
+
+func (e *DictEncoder) hash(buf []byte, h cipher.SHA256) []byte {
+	r := e.hashes.ref(dictValue(h))
+	buf = appendUvarint(buf, r)
+	if r == 0 {
+		buf = append(buf, h[:]...)
+	}
+	return buf
+}
+
+func (e *DictEncoder) address(buf []byte, a cipher.Address) []byte {
+	r := e.addresses.ref(addressDictValue(a))
+	buf = appendUvarint(buf, r)
+	if r == 0 {
+		buf = append(buf, a.Version)
+		buf = append(buf, a.Key[:]...)
+	}
+	return buf
+}
+
+// Encode appends the encoded block to buf
+func (e *DictEncoder) Encode(buf []byte, obj *coin.SignedBlock) []byte {
+	if !e.stream {
+		e.addresses.reset()
+		e.hashes.reset()
+	}
+
+	head := &obj.Block.Head
+	buf = appendUvarint(buf, uint64(head.Version))
+	buf = appendUvarint(buf, head.Time)
+	buf = appendUvarint(buf, head.BkSeq)
+	buf = appendUvarint(buf, head.Fee)
+	buf = e.hash(buf, head.PrevHash)
+	buf = e.hash(buf, head.BodyHash)
+	buf = e.hash(buf, head.UxHash)
+
+	buf = appendUvarint(buf, uint64(len(obj.Block.Body.Transactions)))
+	for i := range obj.Block.Body.Transactions {
+		txn := &obj.Block.Body.Transactions[i]
+
+		buf = appendUvarint(buf, uint64(txn.Length))
+		buf = appendUvarint(buf, uint64(txn.Type))
+		buf = e.hash(buf, txn.InnerHash)
+
+		buf = appendUvarint(buf, uint64(len(txn.Sigs)))
+		for j := range txn.Sigs {
+			buf = append(buf, txn.Sigs[j][:]...)
+		}
+
+		buf = appendUvarint(buf, uint64(len(txn.In)))
+		for _, h := range txn.In {
+			buf = e.hash(buf, h)
+		}
+
+		buf = appendUvarint(buf, uint64(len(txn.Out)))
+		for j := range txn.Out {
+			o := &txn.Out[j]
+			buf = e.address(buf, o.Address)
+			buf = appendUvarint(buf, o.Coins)
+			buf = appendUvarint(buf, o.Hours)
+		}
+	}
+
+	buf = append(buf, obj.Sig[:]...)
+
+	if e.stream {
+		e.hashes.add(dictValue(headerHash(head)))
+	}
+
+	return buf
+}
+
+// DictDecoder decodes blocks in the dict format
+type DictDecoder struct {
+	stream    bool
+	addresses dict
+	hashes    dict
+}
+
+// NewDictDecoder returns a DictDecoder. If stream is set, the dictionaries are kept across blocks.
+// A failed decode leaves a stream mode DictDecoder unusable.
+func NewDictDecoder(stream bool) *DictDecoder {
+	return &DictDecoder{
+		stream:    stream,
+		addresses: newDict(false),
+		hashes:    newDict(false),
+	}
+}
+
+// dictReader reads the dict format, with the field paths of limitScanner for errors
+type dictReader struct {
+	limitScanner
+	d *DictDecoder
+}
+
+func (r *dictReader) ref(d *dict, size int, field string) (dictValue, error) {
+	offset := r.offset()
+
+	n, err := r.uvarint(field)
+	if err != nil {
+		return dictValue{}, err
+	}
+
+	if n != 0 {
+		v, ok := d.get(n)
+		if !ok {
+			return dictValue{}, r.fail(ErrMalformed, offset, field, errDictReference)
+		}
+		return v, nil
+	}
+
+	b, err := r.take(size, field)
+	if err != nil {
+		return dictValue{}, err
+	}
+
+	var v dictValue
+	copy(v[:], b)
+	d.add(v)
+	return v, nil
+}
+
+func (r *dictReader) hash(field string) (cipher.SHA256, error) {
+	v, err := r.ref(&r.d.hashes, len(cipher.SHA256{}), field)
+	return cipher.SHA256(v), err
+}
+
+func (r *dictReader) address(field string) (cipher.Address, error) {
+	v, err := r.ref(&r.d.addresses, 1+len(cipher.Ripemd160{}), field)
+	return v.address(), err
+}
+
+// count reads a length prefix, checking it against a limit and against the remaining bytes
+// before anything is allocated for it
+func (r *dictReader) count(field, limit string, max int) (int, error) {
+	offset := r.offset()
+
+	n, err := r.uvarint(field)
+	if err != nil {
+		return 0, err
+	}
+
+	// The path is only built for the error, since this is on the decoding path
+	if max > 0 && n > uint64(max) {
+		return 0, checkLimit(limit, max, n, offset, r.fieldPath(field))
+	}
+
+	if n > uint64(len(r.buf)) {
+		return 0, r.fail(ErrTruncated, offset, field, nil)
+	}
+
+	return int(n), nil
+}
+
+// int reads a uvarint which must fit in bits
+func (r *dictReader) int(bits uint, field string) (uint64, error) {
+	offset := r.offset()
+
+	x, err := r.uvarint(field)
+	if err != nil {
+		return 0, err
+	}
+	if bits < 64 && x>>bits != 0 {
+		return 0, r.fail(ErrMalformed, offset, field, errDictIntOverflow)
+	}
+	return x, nil
+}
+
+// Decode decodes a block from buf, which must contain exactly one block, and returns the number of bytes read.
+// Errors are a *DecodeError. Payloads exceeding limits are rejected before they are decoded.
+func (d *DictDecoder) Decode(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkCommonLimits(buf, limits); err != nil {
+		return 0, err
+	}
+
+	if !d.stream {
+		d.addresses.reset()
+		d.hashes.reset()
+	}
+
+	r := &dictReader{
+		limitScanner: newLimitScanner(wireLayout{}, buf),
+		d:            d,
+	}
+
+	if err := r.signedBlock(obj, limits); err != nil {
+		return 0, err
+	}
+
+	if err := r.end(); err != nil {
+		return 0, err
+	}
+
+	if d.stream {
+		d.hashes.add(dictValue(headerHash(&obj.Block.Head)))
+	}
+
+	return len(buf), nil
+}
+
+func (r *dictReader) signedBlock(obj *coin.SignedBlock, limits DecodeLimits) error {
+	r.enter("Block")
+	r.enter("Head")
+
+	head := &obj.Block.Head
+	version, err := r.int(32, "Version")
+	if err != nil {
+		return err
+	}
+	head.Version = uint32(version)
+
+	if head.Time, err = r.int(64, "Time"); err != nil {
+		return err
+	}
+	if head.BkSeq, err = r.int(64, "BkSeq"); err != nil {
+		return err
+	}
+	if head.Fee, err = r.int(64, "Fee"); err != nil {
+		return err
+	}
+	if head.PrevHash, err = r.hash("PrevHash"); err != nil {
+		return err
+	}
+	if head.BodyHash, err = r.hash("BodyHash"); err != nil {
+		return err
+	}
+	if head.UxHash, err = r.hash("UxHash"); err != nil {
+		return err
+	}
+	r.leave()
+
+	r.enter("Body")
+	nTxns, err := r.count("Transactions", "MaxTransactions", limits.MaxTransactions)
+	if err != nil {
+		return err
+	}
+
+	obj.Block.Body.Transactions = nil
+	if nTxns != 0 {
+		obj.Block.Body.Transactions = make(coin.Transactions, nTxns)
+	}
+
+	r.enter("Transactions")
+	for i := range obj.Block.Body.Transactions {
+		r.at(uint64(i))
+		if err := r.transaction(&obj.Block.Body.Transactions[i], limits); err != nil {
+			return err
+		}
+	}
+	r.leave()
+	r.leave()
+	r.leave()
+
+	sig, err := r.take(len(obj.Sig), "Sig")
+	if err != nil {
+		return err
+	}
+	copy(obj.Sig[:], sig)
+
+	return nil
+}
+
+func (r *dictReader) transaction(txn *coin.Transaction, limits DecodeLimits) error {
+	length, err := r.int(32, "Length")
+	if err != nil {
+		return err
+	}
+	txn.Length = uint32(length)
+
+	typ, err := r.int(8, "Type")
+	if err != nil {
+		return err
+	}
+	txn.Type = uint8(typ)
+
+	if txn.InnerHash, err = r.hash("InnerHash"); err != nil {
+		return err
+	}
+
+	nSigs, err := r.count("Sigs", "MaxSigs", limits.MaxSigs)
+	if err != nil {
+		return err
+	}
+	txn.Sigs = nil
+	if nSigs != 0 {
+		txn.Sigs = make([]cipher.Sig, nSigs)
+	}
+	r.enter("Sigs")
+	for i := range txn.Sigs {
+		r.at(uint64(i))
+		sig, err := r.take(len(txn.Sigs[i]), "")
+		if err != nil {
+			return err
+		}
+		copy(txn.Sigs[i][:], sig)
+	}
+	r.leave()
+
+	nIn, err := r.count("In", "MaxInputs", limits.MaxInputs)
+	if err != nil {
+		return err
+	}
+	txn.In = nil
+	if nIn != 0 {
+		txn.In = make([]cipher.SHA256, nIn)
+	}
+	r.enter("In")
+	for i := range txn.In {
+		r.at(uint64(i))
+		if txn.In[i], err = r.hash(""); err != nil {
+			return err
+		}
+	}
+	r.leave()
+
+	nOut, err := r.count("Out", "MaxOutputs", limits.MaxOutputs)
+	if err != nil {
+		return err
+	}
+	txn.Out = nil
+	if nOut != 0 {
+		txn.Out = make([]coin.TransactionOutput, nOut)
+	}
+	r.enter("Out")
+	for i := range txn.Out {
+		r.at(uint64(i))
+		o := &txn.Out[i]
+		if o.Address, err = r.address("Address"); err != nil {
+			return err
+		}
+		if o.Coins, err = r.int(64, "Coins"); err != nil {
+			return err
+		}
+		if o.Hours, err = r.int(64, "Hours"); err != nil {
+			return err
+		}
+	}
+	r.leave()
+
+	return nil
+}
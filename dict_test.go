@@ -0,0 +1,237 @@
+package serializebench
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// dictChainSize is the number of blocks of the generated chain used to compare the dict format
+const dictChainSize = 1000
+
+func TestDictStreamRoundTrip(t *testing.T) {
+	blocks := GenerateChain(100, 1)
+
+	enc := NewDictEncoder(true)
+	dec := NewDictDecoder(true)
+
+	for i := range blocks {
+		data := enc.Encode(nil, &blocks[i])
+
+		var result coin.SignedBlock
+		n, err := dec.Decode(data, &result, DefaultDecodeLimits)
+		if err != nil {
+			t.Fatalf("block %d: %v", i, err)
+		}
+		if n != len(data) {
+			t.Errorf("block %d: read %d bytes of %d", i, n, len(data))
+		}
+		if !cmp.Equal(blocks[i], result) {
+			t.Fatalf("block %d: %s", i, cmp.Diff(blocks[i], result))
+		}
+	}
+}
+
+func TestDictWindow(t *testing.T) {
+	// A transaction with more distinct inputs than the window, each spent twice,
+	// so that references are made across the whole window and to evicted values
+	var txn coin.Transaction
+	for i := 0; i < dictWindow+10; i++ {
+		txn.In = append(txn.In, headerHash(&coin.BlockHeader{BkSeq: uint64(i)}))
+	}
+	txn.In = append(txn.In, txn.In...)
+
+	block := coin.SignedBlock{
+		Block: coin.Block{
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{txn},
+			},
+		},
+	}
+
+	limits := DefaultDecodeLimits
+	limits.MaxInputs = len(txn.In)
+
+	data := NewDictEncoder(false).Encode(nil, &block)
+
+	var result coin.SignedBlock
+	if _, err := NewDictDecoder(false).Decode(data, &result, limits); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(block, result) {
+		t.Error(cmp.Diff(block, result))
+	}
+}
+
+func TestDictBadReference(t *testing.T) {
+	block := getBlock()
+	data := NewDictEncoder(false).Encode(nil, &block)
+
+	// The header's integers are followed by the PrevHash literal, which is the first value in the dictionary.
+	// Replace it with a reference to the previous value.
+	offset := uvarintSize(uint64(block.Block.Head.Version)) + uvarintSize(block.Block.Head.Time) +
+		uvarintSize(block.Block.Head.BkSeq) + uvarintSize(block.Block.Head.Fee)
+	if data[offset] != 0 {
+		t.Fatalf("expected a literal at offset %d", offset)
+	}
+	data[offset] = 1
+
+	var result coin.SignedBlock
+	_, err := NewDictDecoder(false).Decode(data, &result, DefaultDecodeLimits)
+	if !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed, got %v", err)
+	}
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected *DecodeError, got %T", err)
+	}
+	if decodeErr.Offset != offset || decodeErr.Path != "Block.Head.PrevHash" {
+		t.Errorf("unexpected error location %d %q", decodeErr.Offset, decodeErr.Path)
+	}
+}
+
+// TestDictSize prints the size of a generated chain in the gencode varint format
+// and in the dict format, per block and as a stream
+func TestDictSize(t *testing.T) {
+	blocks := GenerateChain(dictChainSize, 1)
+
+	var gencodeVarint, perBlock, stream int
+	enc := NewDictEncoder(true)
+	for i := range blocks {
+		data, err := encodeGencodeVarint(&blocks[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		gencodeVarint += len(data)
+
+		perBlock += len(NewDictEncoder(false).Encode(nil, &blocks[i]))
+		stream += len(enc.Encode(nil, &blocks[i]))
+	}
+
+	fmt.Printf("%-12s %12s %8s\n", "format", "chain bytes", "ratio")
+	for _, s := range []struct {
+		name string
+		size int
+	}{
+		{"gencodevar", gencodeVarint},
+		{"dict", perBlock},
+		{"dict stream", stream},
+	} {
+		fmt.Printf("%-12s %12d %8.3f\n", s.name, s.size, float64(s.size)/float64(gencodeVarint))
+	}
+
+	// Few values repeat within a block, so only the stream mode is expected to be smaller than gencodevar
+	if stream >= perBlock || stream >= gencodeVarint {
+		t.Errorf("expected dict stream to be the smallest, got %d, %d, %d", gencodeVarint, perBlock, stream)
+	}
+}
+
+/* dict
+
+- Generated chains, encoded block by block
+*/
+
+func BenchmarkMarshalChainByGencodeVarint(b *testing.B) {
+	blocks := GenerateChain(compressBatchSize, 1)
+	buf := make([]byte, 0, 64*1024)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for j := range blocks {
+			gb := blockToGencodeVarint(blocks[j])
+			if _, err := gb.Marshal(buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkUnmarshalChainByGencodeVarint(b *testing.B) {
+	blocks := GenerateChain(compressBatchSize, 1)
+	raw := make([][]byte, len(blocks))
+	for i := range blocks {
+		data, err := encodeGencodeVarint(&blocks[i])
+		if err != nil {
+			b.Fatal(err)
+		}
+		raw[i] = data
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for j := range raw {
+			var gb GencodeVarintSignedBlock
+			if _, err := gb.Unmarshal(raw[j]); err != nil {
+				b.Fatal(err)
+			}
+			gencodeVarintToBlock(&gb)
+		}
+	}
+}
+
+func benchmarkMarshalChainByDict(b *testing.B, stream bool) {
+	blocks := GenerateChain(compressBatchSize, 1)
+	buf := make([]byte, 0, 64*1024)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		enc := NewDictEncoder(stream)
+		for j := range blocks {
+			buf = enc.Encode(buf[:0], &blocks[j])
+		}
+	}
+}
+
+func benchmarkUnmarshalChainByDict(b *testing.B, stream bool) {
+	blocks := GenerateChain(compressBatchSize, 1)
+	raw := make([][]byte, len(blocks))
+	enc := NewDictEncoder(stream)
+	for i := range blocks {
+		raw[i] = enc.Encode(nil, &blocks[i])
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		dec := NewDictDecoder(stream)
+		for j := range raw {
+			var result coin.SignedBlock
+			if _, err := dec.Decode(raw[j], &result, DefaultDecodeLimits); err != nil {
+				b.Fatal(err)
+			}
+
+			if validate {
+				if !cmp.Equal(result, blocks[j]) {
+					b.Fatal("dict unmarshal result differs")
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkMarshalChainByDict(b *testing.B) {
+	benchmarkMarshalChainByDict(b, false)
+}
+
+func BenchmarkUnmarshalChainByDict(b *testing.B) {
+	benchmarkUnmarshalChainByDict(b, false)
+}
+
+func BenchmarkMarshalChainByDictStream(b *testing.B) {
+	benchmarkMarshalChainByDict(b, true)
+}
+
+func BenchmarkUnmarshalChainByDictStream(b *testing.B) {
+	benchmarkUnmarshalChainByDict(b, true)
+}
@@ -0,0 +1,88 @@
+package serializebench
+
+import "fmt"
+
+// Field is a span of an encoded coin.SignedBlock found by Dissect
+type Field struct {
+	// Offset is the offset of the first byte of the field
+	Offset int
+	// Size is the number of bytes of the field
+	Size int
+	// Path is the field path, such as Block.Body.Transactions[1].Out[2].Coins
+	Path string
+	// Kind is one of int, length, bytes, padding, header, fixed header or end.
+	// Colfer prefixes each field with a header byte holding its index, flagged if the integer is fixed-width,
+	// and terminates each struct with an end byte.
+	Kind string
+	// Value is the decoded value of an int, length or header
+	Value string
+}
+
+// DissectFormats are the formats supported by Dissect
+var DissectFormats = []string{
+	"sky",
+	"skyenc",
+	"xdr2",
+	"colfer",
+	"gencode",
+	"gencodevar",
+	"cgfixed",
+	"cgbe",
+	"cgxdr",
+	"cgvarint",
+}
+
+// Dissect walks a coin.SignedBlock encoded in the named format and returns its fields in order.
+// No limits are applied. If the payload is invalid, the fields scanned before the failure
+// are returned with a *DecodeError locating it.
+func Dissect(format string, data []byte) ([]Field, error) {
+	var fields []Field
+	visit := func(f Field) {
+		fields = append(fields, f)
+	}
+
+	if format == "colfer" {
+		s := &colferScanner{
+			limitScanner: newLimitScanner(wireLayout{}, data),
+		}
+		s.visit = visit
+
+		if err := s.scanStruct(colferSignedBlockFields); err != nil {
+			return fields, err
+		}
+		return fields, s.end()
+	}
+
+	layout, ok := dissectLayout(format)
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	s := newLimitScanner(layout, data)
+	s.visit = visit
+
+	err := scanLayout(&s, DecodeLimits{})
+	return fields, err
+}
+
+// dissectLayout returns the wire layout of a format supported by Dissect other than colfer
+func dissectLayout(format string) (wireLayout, bool) {
+	switch format {
+	case "sky", "skyenc", "cgfixed":
+		return skyLayout, true
+	case "xdr2":
+		return xdr2Layout, true
+	case "cgbe":
+		return bigEndianLayout, true
+	case "cgxdr":
+		return xdrUnpaddedLayout, true
+	case "gencode":
+		return gencodeLayout, true
+	case "gencodevar":
+		return gencodeVarintLayout, true
+	case "cgvarint":
+		return codecgenVarintLayout, true
+	default:
+		return wireLayout{}, false
+	}
+}
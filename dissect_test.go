@@ -0,0 +1,69 @@
+package serializebench
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestDissect(t *testing.T) {
+	block := getBlock()
+	coins := strconv.FormatUint(block.Block.Body.Transactions[1].Out[2].Coins, 10)
+
+	for _, format := range DissectFormats {
+		t.Run(format, func(t *testing.T) {
+			c, err := CodecByName(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fields, err := Dissect(format, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The fields cover the payload without gaps
+			offset := 0
+			for _, f := range fields {
+				if f.Offset != offset {
+					t.Fatalf("field %s at offset %d, expected %d", f.Path, f.Offset, offset)
+				}
+				offset += f.Size
+			}
+			if offset != len(data) {
+				t.Fatalf("fields cover %d of %d bytes", offset, len(data))
+			}
+
+			found := false
+			for _, f := range fields {
+				if f.Path == "Block.Body.Transactions[1].Out[2].Coins" && f.Kind == "int" {
+					found = true
+					if f.Value != coins {
+						t.Errorf("expected coins %s, got %s", coins, f.Value)
+					}
+				}
+			}
+			if !found {
+				t.Error("Block.Body.Transactions[1].Out[2].Coins not found")
+			}
+
+			// A truncated payload returns the fields before the failure
+			fields, err = Dissect(format, data[:len(data)/2])
+			if !errors.Is(err, ErrTruncated) {
+				t.Fatalf("expected ErrTruncated, got %v", err)
+			}
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected *DecodeError, got %T", err)
+			}
+			if last := fields[len(fields)-1]; last.Offset > decodeErr.Offset {
+				t.Errorf("field %s at %d is after the failure at %d", last.Path, last.Offset, decodeErr.Offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package serializebench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	xdr "github.com/davecgh/go-xdr/xdr2"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// Error categories of a failed decode. Every error returned by a Codec's Decode is a *DecodeError
+// which matches one of these with errors.Is.
+var (
+	// ErrTruncated is returned when the payload ends before the block is complete
+	ErrTruncated = errors.New("truncated payload")
+	// ErrTrailingBytes is returned when bytes remain after the block
+	ErrTrailingBytes = errors.New("trailing bytes after payload")
+	// ErrLimitExceeded is returned when the payload exceeds a DecodeLimits bound
+	ErrLimitExceeded = errors.New("decode limit exceeded")
+	// ErrMalformed is returned when the payload is invalid in the format
+	ErrMalformed = errors.New("malformed payload")
+	// ErrNonCanonical is returned by a Codec's DecodeCanonical when the payload decodes to a block
+	// whose encoding is different, such as a varint with redundant continuation bytes
+	ErrNonCanonical = errors.New("non-canonical encoding")
+)
+
+// DecodeError describes a failed decode
+type DecodeError struct {
+	// Kind is ErrTruncated, ErrTrailingBytes, ErrLimitExceeded, ErrMalformed or ErrNonCanonical
+	Kind error
+	// Offset is the byte offset of the failure in the payload, or -1 if unknown
+	Offset int
+	// Path is the path of the field being decoded, such as Block.Body.Transactions[0].Sigs, or empty if unknown
+	Path string
+	// Err is the error returned by the serializer, if any
+	Err error
+}
+
+// Error implements the error interface
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Kind.Error())
+	if e.Path != "" {
+		fmt.Fprintf(&b, " in %s", e.Path)
+	}
+	if e.Offset >= 0 {
+		fmt.Fprintf(&b, " at byte %d", e.Offset)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+	return b.String()
+}
+
+// Is reports whether target is the error category of e
+func (e *DecodeError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the error returned by the serializer
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes a *LimitError match ErrLimitExceeded
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// encoderError maps an error from the Skycoin encoder, skyencoder or codecgen decoders
+func encoderError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	kind := ErrMalformed
+	switch err {
+	case encoder.ErrBufferUnderflow:
+		kind = ErrTruncated
+	case encoder.ErrMaxLenExceeded:
+		kind = ErrLimitExceeded
+	case encoder.ErrRemainingBytes:
+		kind = ErrTrailingBytes
+	}
+
+	return &DecodeError{
+		Kind:   kind,
+		Offset: -1,
+		Err:    err,
+	}
+}
+
+// xdrError maps an error from the XDR decoder
+func xdrError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	kind := ErrMalformed
+	if e, ok := err.(*xdr.UnmarshalError); ok && e.ErrorCode == xdr.ErrIO {
+		if e.Err == io.EOF || e.Err == io.ErrUnexpectedEOF {
+			kind = ErrTruncated
+		}
+	}
+
+	return &DecodeError{
+		Kind:   kind,
+		Offset: -1,
+		Err:    err,
+	}
+}
+
+// jsonError maps an error from a json.Decoder which has read offset bytes
+func jsonError(err error, offset int64) error {
+	if err == nil {
+		return nil
+	}
+
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: int(e.Offset),
+			Err:    err,
+		}
+	case *json.UnmarshalTypeError:
+		return &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: int(e.Offset),
+			Path:   e.Field,
+			Err:    err,
+		}
+	}
+
+	kind := ErrMalformed
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		kind = ErrTruncated
+	}
+
+	return &DecodeError{
+		Kind:   kind,
+		Offset: int(offset),
+		Err:    err,
+	}
+}
+
+// colferError maps an error from the Colfer decoder
+func colferError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch e := err.(type) {
+	case ColferError:
+		return &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: int(e),
+			Err:    err,
+		}
+	case ColferTail:
+		return &DecodeError{
+			Kind:   ErrTrailingBytes,
+			Offset: int(e),
+			Err:    err,
+		}
+	case ColferMax:
+		return &DecodeError{
+			Kind:   ErrLimitExceeded,
+			Offset: -1,
+			Err:    err,
+		}
+	}
+
+	kind := ErrMalformed
+	if err == io.EOF {
+		kind = ErrTruncated
+	}
+
+	return &DecodeError{
+		Kind:   kind,
+		Offset: -1,
+		Err:    err,
+	}
+}
+
+// panicError maps a value recovered from a decoder panic. gencode and gotiny do not check bounds,
+// so reading past the end of the payload panics with an index out of range.
+func panicError(r interface{}) error {
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+
+	kind := ErrMalformed
+	if re, ok := r.(runtime.Error); ok && strings.Contains(re.Error(), "out of range") {
+		kind = ErrTruncated
+	}
+
+	return &DecodeError{
+		Kind:   kind,
+		Offset: -1,
+		Err:    err,
+	}
+}
+
+// trailingBytesError is returned when a decoder read n bytes of a longer payload
+func trailingBytesError(n int) error {
+	return &DecodeError{
+		Kind:   ErrTrailingBytes,
+		Offset: n,
+	}
+}
@@ -0,0 +1,343 @@
+package serializebench
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	xdr "github.com/davecgh/go-xdr/xdr2"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+func TestDecodeErrorsTruncated(t *testing.T) {
+	block := getBlock()
+
+	for _, c := range Codecs {
+		t.Run(c.Name, func(t *testing.T) {
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < len(data); i++ {
+				var result coin.SignedBlock
+				_, err := c.Decode(data[:i], &result, DefaultDecodeLimits)
+				if !errors.Is(err, ErrTruncated) {
+					t.Fatalf("decoding %d of %d bytes: expected ErrTruncated, got %v", i, len(data), err)
+				}
+
+				var decodeErr *DecodeError
+				if !errors.As(err, &decodeErr) {
+					t.Fatalf("decoding %d of %d bytes: expected *DecodeError, got %T", i, len(data), err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeErrorsTrailingBytes(t *testing.T) {
+	block := getBlock()
+
+	for _, c := range Codecs {
+		t.Run(c.Name, func(t *testing.T) {
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := len(data)
+			data = append(data, '0')
+
+			var result coin.SignedBlock
+			_, err = c.Decode(data, &result, DefaultDecodeLimits)
+			if !errors.Is(err, ErrTrailingBytes) {
+				t.Fatalf("expected ErrTrailingBytes, got %v", err)
+			}
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected *DecodeError, got %T", err)
+			}
+			if decodeErr.Offset != n {
+				t.Errorf("expected offset %d, got %d", n, decodeErr.Offset)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorsCorrupted(t *testing.T) {
+	// Most of the binary formats have no redundancy, so the only corruption they can detect
+	// is a length prefix claiming more elements than remain, which is reported as truncation.
+	overflowVarint := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+
+	// sigsCount is the offset of the first transaction's signature count in the Skycoin encoder format:
+	// the 124 byte header, the transaction count, and the transaction's Length, Type and InnerHash
+	const sigsCount = 124 + 4 + 4 + 1 + 32
+
+	cases := []struct {
+		codec   string
+		corrupt func(data []byte) []byte
+		kind    error
+		offset  int
+		path    string
+	}{
+		{
+			codec: "sky",
+			corrupt: func(data []byte) []byte {
+				data[sigsCount] = 0xff
+				return data
+			},
+			kind:   ErrTruncated,
+			offset: -1,
+			path:   "Block.Body.Transactions[0].Sigs[",
+		},
+		{
+			codec: "skyenc",
+			corrupt: func(data []byte) []byte {
+				data[sigsCount] = 0xff
+				return data
+			},
+			kind:   ErrTruncated,
+			offset: -1,
+			path:   "Block.Body.Transactions[0].Sigs[",
+		},
+		{
+			codec: "cgfixed",
+			corrupt: func(data []byte) []byte {
+				data[sigsCount] = 0xff
+				return data
+			},
+			kind:   ErrTruncated,
+			offset: -1,
+			path:   "Block.Body.Transactions[0].Sigs[",
+		},
+		{
+			// The 65 byte signature is padded with 3 zero bytes
+			codec: "xdr2",
+			corrupt: func(data []byte) []byte {
+				data[len(data)-1] = 1
+				return data
+			},
+			kind:   ErrMalformed,
+			offset: 1612 - 1,
+			path:   "Sig",
+		},
+		{
+			codec: "cgvarint",
+			corrupt: func(data []byte) []byte {
+				return append(append([]byte(nil), overflowVarint...), data[1:]...)
+			},
+			kind:   ErrMalformed,
+			offset: 0,
+			path:   "Block.Head.Version",
+		},
+		{
+			codec: "gotiny",
+			corrupt: func(data []byte) []byte {
+				return append(append([]byte(nil), overflowVarint...), data[1:]...)
+			},
+			kind:   ErrMalformed,
+			offset: 0,
+			path:   "Block.Head.Version",
+		},
+		{
+			codec: "dict",
+			corrupt: func(data []byte) []byte {
+				return append(append([]byte(nil), overflowVarint...), data[1:]...)
+			},
+			kind:   ErrMalformed,
+			offset: 0,
+			path:   "Block.Head.Version",
+		},
+		{
+			codec: "avro",
+			corrupt: func(data []byte) []byte {
+				return append(append([]byte(nil), overflowVarint...), data[1:]...)
+			},
+			kind:   ErrMalformed,
+			offset: 0,
+			path:   "Block.Head.Version",
+		},
+		{
+			// gencode only uses varints for length prefixes. Corrupt the transaction count,
+			// which follows the signature and the 124 byte header.
+			codec: "gencode",
+			corrupt: func(data []byte) []byte {
+				return append(append(data[:65+124:65+124], overflowVarint...), data[65+124+1:]...)
+			},
+			kind:   ErrMalformed,
+			offset: 65 + 124,
+			path:   "Block.Body.Transactions",
+		},
+		{
+			codec: "gencodevar",
+			corrupt: func(data []byte) []byte {
+				return append(append(data[:65:65], overflowVarint...), data[66:]...)
+			},
+			kind:   ErrMalformed,
+			offset: 65,
+			path:   "Block.Head.Version",
+		},
+		{
+			// Replace the Sig field header with an unknown field index
+			codec: "colfer",
+			corrupt: func(data []byte) []byte {
+				data[0] = 0x05
+				return data
+			},
+			kind:   ErrMalformed,
+			offset: 0,
+			path:   "",
+		},
+		{
+			// coin.Block is embedded in coin.SignedBlock, so its fields are promoted in JSON
+			codec: "json",
+			corrupt: func(data []byte) []byte {
+				return bytes.Replace(data, []byte(`"Version":1`), []byte(`"Version":"1"`), 1)
+			},
+			kind:   ErrMalformed,
+			offset: -1,
+			path:   "Head.Version",
+		},
+	}
+
+	block := getBlock()
+
+	for _, tc := range cases {
+		t.Run(tc.codec, func(t *testing.T) {
+			c, err := CodecByName(tc.codec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data = tc.corrupt(data)
+
+			var result coin.SignedBlock
+			_, err = c.Decode(data, &result, DefaultDecodeLimits)
+			if !errors.Is(err, tc.kind) {
+				t.Fatalf("expected %v, got %v", tc.kind, err)
+			}
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected *DecodeError, got %T", err)
+			}
+			if tc.offset >= 0 && decodeErr.Offset != tc.offset {
+				t.Errorf("expected offset %d, got %d", tc.offset, decodeErr.Offset)
+			}
+			if !strings.HasPrefix(decodeErr.Path, tc.path) {
+				t.Errorf("expected path %q, got %q", tc.path, decodeErr.Path)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorsLimitExceeded(t *testing.T) {
+	block := getBlock()
+
+	limits := DefaultDecodeLimits
+	limits.MaxOutputs = 1
+
+	for _, c := range Codecs {
+		t.Run(c.Name, func(t *testing.T) {
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var result coin.SignedBlock
+			_, err = c.Decode(data, &result, limits)
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("expected ErrLimitExceeded, got %v", err)
+			}
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected *DecodeError, got %T", err)
+			}
+			if !strings.HasSuffix(decodeErr.Path, ".Out") {
+				t.Errorf("expected the path of an Out field, got %q", decodeErr.Path)
+			}
+		})
+	}
+}
+
+// recoverIndexPanic returns the value recovered from indexing past the end of a slice
+func recoverIndexPanic() (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	var s []byte
+	i := 1
+	_ = s[i]
+	return nil
+}
+
+// TestDecodeErrorAdapters passes the native errors of each serializer to its adapter. The limit scanner
+// rejects invalid payloads before the serializers see them, so the adapters are not reached by decoding.
+func TestDecodeErrorAdapters(t *testing.T) {
+	xdrEOF := &xdr.UnmarshalError{ErrorCode: xdr.ErrIO, Err: io.ErrUnexpectedEOF}
+	xdrOther := &xdr.UnmarshalError{Err: errors.New("overflow")}
+	indexPanic := recoverIndexPanic()
+
+	cases := []struct {
+		name   string
+		err    error
+		kind   error
+		offset int
+		// unwrap is the error returned by Unwrap
+		unwrap error
+	}{
+		{"encoder underflow", encoderError(encoder.ErrBufferUnderflow), ErrTruncated, -1, encoder.ErrBufferUnderflow},
+		{"encoder max len", encoderError(encoder.ErrMaxLenExceeded), ErrLimitExceeded, -1, encoder.ErrMaxLenExceeded},
+		{"encoder remaining bytes", encoderError(encoder.ErrRemainingBytes), ErrTrailingBytes, -1, encoder.ErrRemainingBytes},
+		{"encoder other", encoderError(io.ErrClosedPipe), ErrMalformed, -1, io.ErrClosedPipe},
+		{"xdr EOF", xdrError(xdrEOF), ErrTruncated, -1, xdrEOF},
+		{"xdr other", xdrError(xdrOther), ErrMalformed, -1, xdrOther},
+		{"colfer error", colferError(ColferError(12)), ErrMalformed, 12, ColferError(12)},
+		{"colfer tail", colferError(ColferTail(34)), ErrTrailingBytes, 34, ColferTail(34)},
+		{"colfer max", colferError(ColferMax("too long")), ErrLimitExceeded, -1, ColferMax("too long")},
+		{"colfer EOF", colferError(io.EOF), ErrTruncated, -1, io.EOF},
+		{"index out of range", panicError(indexPanic), ErrTruncated, -1, indexPanic.(error)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var decodeErr *DecodeError
+			if !errors.As(tc.err, &decodeErr) {
+				t.Fatalf("expected *DecodeError, got %T", tc.err)
+			}
+			if decodeErr.Kind != tc.kind {
+				t.Errorf("expected %v, got %v", tc.kind, decodeErr.Kind)
+			}
+			if decodeErr.Offset != tc.offset {
+				t.Errorf("expected offset %d, got %d", tc.offset, decodeErr.Offset)
+			}
+			if unwrapped := errors.Unwrap(tc.err); unwrapped != tc.unwrap {
+				t.Errorf("expected Unwrap to return %v, got %v", tc.unwrap, unwrapped)
+			}
+		})
+	}
+
+	// A panic which is not an index out of range is malformed
+	err := panicError("bad varint")
+	if !errors.Is(err, ErrMalformed) || err.(*DecodeError).Err.Error() != "bad varint" {
+		t.Errorf("expected ErrMalformed wrapping the panic value, got %v", err)
+	}
+
+	for name, adapt := range map[string]func(error) error{
+		"encoderError": encoderError,
+		"xdrError":     xdrError,
+		"colferError":  colferError,
+	} {
+		if err := adapt(nil); err != nil {
+			t.Errorf("%s(nil) returned %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,662 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// The schema evolution harness encodes a struct with one version of its schema and decodes it with another.
+// The generated code of block.colf, gencode.schema and gencode-varint.schema is the "v1" side.
+// The "v2" side is a schema-driven encoder and decoder of the same wire formats, which is checked
+// against the generated code with the v1 schema before it is used with a v2 schema.
+
+// evolutionKind is the type of a field in an evolutionField schema
+type evolutionKind int
+
+const (
+	evolutionUint8 evolutionKind = iota
+	evolutionUint32
+	evolutionUint64
+	evolutionBytes
+	evolutionBytesList
+	evolutionStruct
+	evolutionStructList
+)
+
+// evolutionField is a field of a schema. Colfer numbers the fields by their position;
+// gencode writes them in order.
+type evolutionField struct {
+	name string
+	kind evolutionKind
+	// size is the size of a gencode fixed-size byte array, or of each element of a list of them
+	size int
+	// fields are the fields of a struct or struct list element
+	fields []evolutionField
+}
+
+// evolutionRecord holds the values of a struct by field name:
+// uint64 for integers, []byte, [][]byte, evolutionRecord and []evolutionRecord
+type evolutionRecord map[string]interface{}
+
+var (
+	evolutionAddressFields = []evolutionField{
+		{name: "Version", kind: evolutionUint8},
+		{name: "Key", kind: evolutionBytes, size: 20},
+	}
+
+	evolutionTransactionOutputFields = []evolutionField{
+		{name: "Address", kind: evolutionStruct, fields: evolutionAddressFields},
+		{name: "Coins", kind: evolutionUint64},
+		{name: "Hours", kind: evolutionUint64},
+	}
+
+	evolutionTransactionFields = []evolutionField{
+		{name: "Length", kind: evolutionUint32},
+		{name: "Type", kind: evolutionUint8},
+		{name: "InnerHash", kind: evolutionBytes, size: 32},
+		{name: "Sigs", kind: evolutionBytesList, size: 65},
+		{name: "In", kind: evolutionBytesList, size: 32},
+		{name: "Out", kind: evolutionStructList, fields: evolutionTransactionOutputFields},
+	}
+
+	evolutionBlockHeaderFields = []evolutionField{
+		{name: "Version", kind: evolutionUint32},
+		{name: "Time", kind: evolutionUint64},
+		{name: "BkSeq", kind: evolutionUint64},
+		{name: "Fee", kind: evolutionUint64},
+		{name: "PrevHash", kind: evolutionBytes, size: 32},
+		{name: "BodyHash", kind: evolutionBytes, size: 32},
+		{name: "UxHash", kind: evolutionBytes, size: 32},
+	}
+)
+
+// evolutionChange is a change from a v1 schema to a v2 schema
+type evolutionChange struct {
+	name string
+	// apply returns the v2 schema and the v2 value of a v1 value
+	apply func(fields []evolutionField, rec evolutionRecord) ([]evolutionField, evolutionRecord)
+}
+
+// evolutionAddedField is the field added by the "add" change, and its value
+const (
+	evolutionAddedField = "Extra"
+	evolutionAddedValue = 7
+)
+
+// evolutionChanges returns the changes tested on a struct: appending a field,
+// removing the field removed and swapping the fields swapped
+func evolutionChanges(removed string, swapped [2]string) []evolutionChange {
+	return []evolutionChange{
+		{
+			name: "add " + evolutionAddedField,
+			apply: func(fields []evolutionField, rec evolutionRecord) ([]evolutionField, evolutionRecord) {
+				v2 := append(fields[:len(fields):len(fields)], evolutionField{name: evolutionAddedField, kind: evolutionUint64})
+				rec2 := copyRecord(rec)
+				rec2[evolutionAddedField] = uint64(evolutionAddedValue)
+				return v2, rec2
+			},
+		},
+		{
+			name: "remove " + removed,
+			apply: func(fields []evolutionField, rec evolutionRecord) ([]evolutionField, evolutionRecord) {
+				var v2 []evolutionField
+				for _, f := range fields {
+					if f.name != removed {
+						v2 = append(v2, f)
+					}
+				}
+				rec2 := copyRecord(rec)
+				delete(rec2, removed)
+				return v2, rec2
+			},
+		},
+		{
+			name: "swap " + swapped[0] + " " + swapped[1],
+			apply: func(fields []evolutionField, rec evolutionRecord) ([]evolutionField, evolutionRecord) {
+				v2 := append([]evolutionField(nil), fields...)
+				var i, j int
+				for k, f := range v2 {
+					switch f.name {
+					case swapped[0]:
+						i = k
+					case swapped[1]:
+						j = k
+					}
+				}
+				v2[i], v2[j] = v2[j], v2[i]
+				return v2, rec
+			},
+		},
+	}
+}
+
+func copyRecord(rec evolutionRecord) evolutionRecord {
+	c := make(evolutionRecord, len(rec))
+	for k, v := range rec {
+		c[k] = v
+	}
+	return c
+}
+
+// recordOf reads the fields of a generated struct into a record
+func recordOf(v reflect.Value, fields []evolutionField) evolutionRecord {
+	v = reflect.Indirect(v)
+	rec := make(evolutionRecord, len(fields))
+
+	bytesOf := func(v reflect.Value) []byte {
+		if v.Len() == 0 {
+			return nil
+		}
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b
+	}
+
+	for _, f := range fields {
+		fv := v.FieldByName(f.name)
+		switch f.kind {
+		case evolutionUint8, evolutionUint32, evolutionUint64:
+			rec[f.name] = fv.Uint()
+		case evolutionBytes:
+			rec[f.name] = bytesOf(fv)
+		case evolutionBytesList:
+			var list [][]byte
+			for i := 0; i < fv.Len(); i++ {
+				list = append(list, bytesOf(fv.Index(i)))
+			}
+			rec[f.name] = list
+		case evolutionStruct:
+			rec[f.name] = recordOf(fv, f.fields)
+		case evolutionStructList:
+			var list []evolutionRecord
+			for i := 0; i < fv.Len(); i++ {
+				list = append(list, recordOf(fv.Index(i), f.fields))
+			}
+			rec[f.name] = list
+		}
+	}
+
+	return rec
+}
+
+var errEvolutionTruncated = errors.New("truncated")
+
+// evolutionReader reads a wire format for the schema-driven decoders
+type evolutionReader struct {
+	buf []byte
+	i   int
+}
+
+func (r *evolutionReader) take(n uint64) ([]byte, error) {
+	if n > uint64(len(r.buf)-r.i) {
+		return nil, errEvolutionTruncated
+	}
+	b := r.buf[r.i : r.i+int(n)]
+	r.i += int(n)
+	return b, nil
+}
+
+func (r *evolutionReader) byte() (byte, error) {
+	b, err := r.take(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *evolutionReader) uvarint() (uint64, error) {
+	x, n := binary.Uvarint(r.buf[r.i:])
+	if n <= 0 {
+		return 0, errEvolutionTruncated
+	}
+	r.i += n
+	return x, nil
+}
+
+// evolutionFormat is a wire format with a schema-driven encoder and decoder
+type evolutionFormat struct {
+	name   string
+	encode func(buf []byte, fields []evolutionField, rec evolutionRecord) []byte
+	decode func(r *evolutionReader, fields []evolutionField) (evolutionRecord, error)
+}
+
+var colferEvolutionFormat = evolutionFormat{
+	name:   "colfer",
+	encode: encodeColferRecord,
+	decode: decodeColferRecord,
+}
+
+// encodeColferRecord encodes a record like colf's generated MarshalTo:
+// zero fields are omitted, and large integers are written as fixed-width big-endian integers
+func encodeColferRecord(buf []byte, fields []evolutionField, rec evolutionRecord) []byte {
+	for i, f := range fields {
+		header := byte(i)
+		switch v := rec[f.name].(type) {
+		case uint64:
+			switch {
+			case v == 0:
+			case f.kind == evolutionUint8:
+				buf = append(buf, header, byte(v))
+			case f.kind == evolutionUint32 && v >= 1<<21:
+				buf = append(buf, header|0x80, 0, 0, 0, 0)
+				binary.BigEndian.PutUint32(buf[len(buf)-4:], uint32(v))
+			case f.kind == evolutionUint64 && v >= 1<<49:
+				buf = append(buf, header|0x80, 0, 0, 0, 0, 0, 0, 0, 0)
+				binary.BigEndian.PutUint64(buf[len(buf)-8:], v)
+			default:
+				buf = appendUvarint(append(buf, header), v)
+			}
+		case []byte:
+			if len(v) != 0 {
+				buf = appendUvarint(append(buf, header), uint64(len(v)))
+				buf = append(buf, v...)
+			}
+		case [][]byte:
+			if len(v) != 0 {
+				buf = appendUvarint(append(buf, header), uint64(len(v)))
+				for _, b := range v {
+					buf = appendUvarint(buf, uint64(len(b)))
+					buf = append(buf, b...)
+				}
+			}
+		case evolutionRecord:
+			buf = encodeColferRecord(append(buf, header), f.fields, v)
+		case []evolutionRecord:
+			if len(v) != 0 {
+				buf = appendUvarint(append(buf, header), uint64(len(v)))
+				for _, e := range v {
+					buf = encodeColferRecord(buf, f.fields, e)
+				}
+			}
+		}
+	}
+	return append(buf, 0x7f)
+}
+
+// decodeColferRecord decodes a record like colf's generated Unmarshal:
+// fields must appear in schema order, and any header after the last field other than 0x7f is an error
+func decodeColferRecord(r *evolutionReader, fields []evolutionField) (evolutionRecord, error) {
+	rec := make(evolutionRecord, len(fields))
+	for _, f := range fields {
+		switch f.kind {
+		case evolutionUint8, evolutionUint32, evolutionUint64:
+			rec[f.name] = uint64(0)
+		}
+	}
+
+	header, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, f := range fields {
+		fixed := header == byte(i)|0x80 && (f.kind == evolutionUint32 || f.kind == evolutionUint64)
+		if header != byte(i) && !fixed {
+			continue
+		}
+
+		switch f.kind {
+		case evolutionUint8:
+			var b byte
+			b, err = r.byte()
+			rec[f.name] = uint64(b)
+
+		case evolutionUint32, evolutionUint64:
+			if fixed {
+				size := uint64(4)
+				if f.kind == evolutionUint64 {
+					size = 8
+				}
+				var b []byte
+				if b, err = r.take(size); err == nil {
+					rec[f.name] = readUint(binary.BigEndian, b)
+				}
+			} else {
+				var x uint64
+				x, err = r.uvarint()
+				rec[f.name] = x
+			}
+
+		case evolutionBytes:
+			var n uint64
+			if n, err = r.uvarint(); err == nil {
+				var b []byte
+				b, err = r.take(n)
+				rec[f.name] = append([]byte(nil), b...)
+			}
+
+		case evolutionBytesList:
+			var n uint64
+			n, err = r.uvarint()
+			var list [][]byte
+			for j := uint64(0); err == nil && j < n; j++ {
+				var size uint64
+				if size, err = r.uvarint(); err == nil {
+					var b []byte
+					b, err = r.take(size)
+					list = append(list, append([]byte(nil), b...))
+				}
+			}
+			rec[f.name] = list
+
+		case evolutionStruct:
+			rec[f.name], err = decodeColferRecord(r, f.fields)
+
+		case evolutionStructList:
+			var n uint64
+			n, err = r.uvarint()
+			var list []evolutionRecord
+			for j := uint64(0); err == nil && j < n; j++ {
+				var e evolutionRecord
+				e, err = decodeColferRecord(r, f.fields)
+				list = append(list, e)
+			}
+			rec[f.name] = list
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if header, err = r.byte(); err != nil {
+			return nil, err
+		}
+	}
+
+	if header != 0x7f {
+		return nil, fmt.Errorf("unknown header %#x at byte %d", header, r.i-1)
+	}
+	return rec, nil
+}
+
+func gencodeEvolutionFormat(name string, varint bool) evolutionFormat {
+	return evolutionFormat{
+		name: name,
+		encode: func(buf []byte, fields []evolutionField, rec evolutionRecord) []byte {
+			return encodeGencodeRecord(buf, varint, fields, rec)
+		},
+		decode: func(r *evolutionReader, fields []evolutionField) (evolutionRecord, error) {
+			return decodeGencodeRecord(r, varint, fields)
+		},
+	}
+}
+
+// encodeGencodeRecord encodes a record like gencode: every field in schema order,
+// integers little-endian or as varints, and byte arrays at their fixed size
+func encodeGencodeRecord(buf []byte, varint bool, fields []evolutionField, rec evolutionRecord) []byte {
+	for _, f := range fields {
+		switch v := rec[f.name].(type) {
+		case uint64:
+			if varint {
+				buf = appendUvarint(buf, v)
+				break
+			}
+			switch f.kind {
+			case evolutionUint8:
+				buf = append(buf, byte(v))
+			case evolutionUint32:
+				buf = append(buf, 0, 0, 0, 0)
+				binary.LittleEndian.PutUint32(buf[len(buf)-4:], uint32(v))
+			default:
+				buf = append(buf, 0, 0, 0, 0, 0, 0, 0, 0)
+				binary.LittleEndian.PutUint64(buf[len(buf)-8:], v)
+			}
+		case []byte:
+			buf = append(buf, make([]byte, f.size)...)
+			copy(buf[len(buf)-f.size:], v)
+		case [][]byte:
+			buf = appendUvarint(buf, uint64(len(v)))
+			for _, b := range v {
+				buf = append(buf, make([]byte, f.size)...)
+				copy(buf[len(buf)-f.size:], b)
+			}
+		case evolutionRecord:
+			buf = encodeGencodeRecord(buf, varint, f.fields, v)
+		case []evolutionRecord:
+			buf = appendUvarint(buf, uint64(len(v)))
+			for _, e := range v {
+				buf = encodeGencodeRecord(buf, varint, f.fields, e)
+			}
+		}
+	}
+	return buf
+}
+
+func decodeGencodeRecord(r *evolutionReader, varint bool, fields []evolutionField) (evolutionRecord, error) {
+	rec := make(evolutionRecord, len(fields))
+	for _, f := range fields {
+		var err error
+		switch f.kind {
+		case evolutionUint8, evolutionUint32, evolutionUint64:
+			if varint {
+				rec[f.name], err = r.uvarint()
+				break
+			}
+			size := map[evolutionKind]uint64{evolutionUint8: 1, evolutionUint32: 4, evolutionUint64: 8}[f.kind]
+			var b []byte
+			if b, err = r.take(size); err == nil {
+				rec[f.name] = readUint(binary.LittleEndian, b)
+			}
+
+		case evolutionBytes:
+			var b []byte
+			b, err = r.take(uint64(f.size))
+			rec[f.name] = append([]byte(nil), b...)
+
+		case evolutionBytesList:
+			var n uint64
+			n, err = r.uvarint()
+			var list [][]byte
+			for j := uint64(0); err == nil && j < n; j++ {
+				var b []byte
+				b, err = r.take(uint64(f.size))
+				list = append(list, append([]byte(nil), b...))
+			}
+			rec[f.name] = list
+
+		case evolutionStruct:
+			rec[f.name], err = decodeGencodeRecord(r, varint, f.fields)
+
+		case evolutionStructList:
+			var n uint64
+			n, err = r.uvarint()
+			var list []evolutionRecord
+			for j := uint64(0); err == nil && j < n; j++ {
+				var e evolutionRecord
+				e, err = decodeGencodeRecord(r, varint, f.fields)
+				list = append(list, e)
+			}
+			rec[f.name] = list
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// generatedMarshal encodes a generated Colfer or gencode struct
+func generatedMarshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case interface{ MarshalBinary() ([]byte, error) }:
+		return m.MarshalBinary()
+	case interface{ Marshal([]byte) ([]byte, error) }:
+		return m.Marshal(nil)
+	default:
+		panic(fmt.Sprintf("%T is not a generated struct", v))
+	}
+}
+
+// generatedUnmarshal decodes a generated Colfer or gencode struct of the type of v from the start of buf
+func generatedUnmarshal(v interface{}, buf []byte) (interface{}, int, error) {
+	result := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	switch u := result.(type) {
+	case interface{ Unmarshal([]byte) (int, error) }:
+		n, err := u.Unmarshal(buf)
+		return result, n, err
+	case interface{ Unmarshal([]byte) (uint64, error) }:
+		n, err := u.Unmarshal(buf)
+		return result, int(n), err
+	default:
+		panic(fmt.Sprintf("%T is not a generated struct", v))
+	}
+}
+
+// evolutionCase is a struct of the test block in one format
+type evolutionCase struct {
+	format evolutionFormat
+	name   string
+	fields []evolutionField
+	// value is a pointer to the generated struct
+	value   interface{}
+	changes []evolutionChange
+}
+
+func evolutionCases() []evolutionCase {
+	block := getBlock()
+	colfer := blockToColfer(block)
+	gencode := blockToGencode(block)
+	gencodeVarint := blockToGencodeVarint(block)
+
+	txnChanges := evolutionChanges("InnerHash", [2]string{"Sigs", "In"})
+	headerChanges := evolutionChanges("Time", [2]string{"Time", "BkSeq"})
+
+	gencodeFormat := gencodeEvolutionFormat("gencode", false)
+	gencodeVarintFormat := gencodeEvolutionFormat("gencodevar", true)
+
+	return []evolutionCase{
+		{colferEvolutionFormat, "Transaction", evolutionTransactionFields, colfer.Block.Body.Transactions[0], txnChanges},
+		{colferEvolutionFormat, "BlockHeader", evolutionBlockHeaderFields, colfer.Block.Head, headerChanges},
+		{gencodeFormat, "Transaction", evolutionTransactionFields, &gencode.Block.Body.Transactions[0], txnChanges},
+		{gencodeFormat, "BlockHeader", evolutionBlockHeaderFields, &gencode.Block.Head, headerChanges},
+		{gencodeVarintFormat, "Transaction", evolutionTransactionFields, &gencodeVarint.Block.Body.Transactions[0], txnChanges},
+		{gencodeVarintFormat, "BlockHeader", evolutionBlockHeaderFields, &gencodeVarint.Block.Head, headerChanges},
+	}
+}
+
+// Outcomes of decoding data written with another version of the schema
+const (
+	// evolutionOK means every field known to both versions was read correctly,
+	// and every field only known to the reader is zero
+	evolutionOK = "ok"
+	// evolutionError means the decoder returned an error
+	evolutionError = "error"
+	// evolutionMisread means the decoder returned a wrong value without an error
+	evolutionMisread = "misread"
+)
+
+// evolutionOutcome decodes two consecutive values written as rec, as they would appear in a list or a stream.
+// A decoder which consumes the wrong number of bytes misreads the second value.
+func evolutionOutcome(data []byte, rec evolutionRecord, readerFields []evolutionField, decode func([]byte) (evolutionRecord, int, error)) string {
+	data = append(data[:len(data):len(data)], data...)
+	for i := 0; i < 2; i++ {
+		result, n, err := decode(data)
+		if err != nil {
+			return evolutionError
+		}
+		data = data[n:]
+
+		for _, f := range readerFields {
+			want, ok := rec[f.name]
+			if !ok {
+				want = reflect.Zero(reflect.TypeOf(result[f.name])).Interface()
+			}
+			if !cmp.Equal(want, result[f.name], cmpopts.EquateEmpty()) {
+				return evolutionMisread
+			}
+		}
+	}
+
+	if len(data) != 0 {
+		return evolutionMisread
+	}
+	return evolutionOK
+}
+
+// evolutionExpected are the outcomes of each format, struct and change,
+// reading v2 data with the v1 generated code and v1 data with a v2 reader
+var evolutionExpected = map[string][2]string{
+	"colfer Transaction add Extra":            {evolutionError, evolutionOK},
+	"colfer Transaction remove InnerHash":     {evolutionError, evolutionError},
+	"colfer Transaction swap Sigs In":         {evolutionMisread, evolutionMisread},
+	"colfer BlockHeader add Extra":            {evolutionError, evolutionOK},
+	"colfer BlockHeader remove Time":          {evolutionError, evolutionError},
+	"colfer BlockHeader swap Time BkSeq":      {evolutionMisread, evolutionMisread},
+	"gencode Transaction add Extra":           {evolutionError, evolutionMisread},
+	"gencode Transaction remove InnerHash":    {evolutionError, evolutionError},
+	"gencode Transaction swap Sigs In":        {evolutionError, evolutionError},
+	"gencode BlockHeader add Extra":           {evolutionMisread, evolutionMisread},
+	"gencode BlockHeader remove Time":         {evolutionMisread, evolutionMisread},
+	"gencode BlockHeader swap Time BkSeq":     {evolutionMisread, evolutionMisread},
+	"gencodevar Transaction add Extra":        {evolutionError, evolutionMisread},
+	"gencodevar Transaction remove InnerHash": {evolutionError, evolutionError},
+	"gencodevar Transaction swap Sigs In":     {evolutionError, evolutionError},
+	"gencodevar BlockHeader add Extra":        {evolutionMisread, evolutionMisread},
+	"gencodevar BlockHeader remove Time":      {evolutionMisread, evolutionMisread},
+	"gencodevar BlockHeader swap Time BkSeq":  {evolutionMisread, evolutionMisread},
+}
+
+// TestSchemaEvolution prints which combinations of old and new readers and writers succeed,
+// fail cleanly or silently misread, and checks them against evolutionExpected
+func TestSchemaEvolution(t *testing.T) {
+	fmt.Printf("%-40s %-14s %-14s\n", "format and change", "new -> old", "old -> new")
+
+	for _, c := range evolutionCases() {
+		data, err := generatedMarshal(c.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := recordOf(reflect.ValueOf(c.value), c.fields)
+
+		// Like the Codecs, the payload is scanned before the generated code decodes it,
+		// since gencode does not check lengths against the payload
+		decodeGenerated := func(buf []byte) (evolutionRecord, int, error) {
+			if _, err := c.format.decode(&evolutionReader{buf: buf}, c.fields); err != nil {
+				return nil, 0, err
+			}
+			v, n, err := generatedUnmarshal(c.value, buf)
+			if err != nil {
+				return nil, 0, err
+			}
+			return recordOf(reflect.ValueOf(v), c.fields), n, nil
+		}
+		decodeWith := func(fields []evolutionField) func([]byte) (evolutionRecord, int, error) {
+			return func(buf []byte) (evolutionRecord, int, error) {
+				r := &evolutionReader{buf: buf}
+				result, err := c.format.decode(r, fields)
+				return result, r.i, err
+			}
+		}
+
+		// The schema-driven codec must match the generated code with the v1 schema
+		if v1 := c.format.encode(nil, c.fields, rec); !cmp.Equal(data, v1) {
+			t.Fatalf("%s %s: the v1 schema encoding differs from the generated code", c.format.name, c.name)
+		}
+		if outcome := evolutionOutcome(data, rec, c.fields, decodeWith(c.fields)); outcome != evolutionOK {
+			t.Fatalf("%s %s: the v1 schema decoder can not read the generated code: %s", c.format.name, c.name, outcome)
+		}
+
+		for _, change := range c.changes {
+			name := fmt.Sprintf("%s %s %s", c.format.name, c.name, change.name)
+			v2Fields, v2Rec := change.apply(c.fields, rec)
+
+			v2Data := c.format.encode(nil, v2Fields, v2Rec)
+			outcomes := [2]string{
+				evolutionOutcome(v2Data, v2Rec, c.fields, decodeGenerated),
+				evolutionOutcome(data, rec, v2Fields, decodeWith(v2Fields)),
+			}
+			fmt.Printf("%-40s %-14s %-14s\n", name, outcomes[0], outcomes[1])
+
+			if expected, ok := evolutionExpected[name]; !ok {
+				t.Errorf("%s: no expected outcome", name)
+			} else if outcomes != expected {
+				t.Errorf("%s: expected %v, got %v", name, expected, outcomes)
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package serializebench
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"time"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// GCProfileOptions configures ProfileGC
+type GCProfileOptions struct {
+	// Duration is how long blocks are decoded for
+	Duration time.Duration
+	// Retain is the number of most recently decoded blocks kept alive, as a node keeps recent blocks
+	Retain int
+	// ProfileDir, if set, is the directory <codec>.cpu.pprof and <codec>.heap.pprof are written to
+	ProfileDir string
+}
+
+// GCProfile is the activity of the garbage collector during a sustained decode workload, read from runtime/metrics
+type GCProfile struct {
+	// Decodes is the number of blocks decoded
+	Decodes int
+	// Cycles is the number of completed GC cycles
+	Cycles uint64
+	// PauseTotal is the total stop-the-world pause time of the GC, estimated from the pause histogram
+	PauseTotal time.Duration
+	// PauseMax is the upper bound of the pause histogram bucket of the longest pause
+	PauseMax time.Duration
+	// PeakHeap is the largest heap object size sampled during the workload
+	PeakHeap uint64
+	// LiveHeap is the heap marked live by the last GC cycle, which includes the retained blocks
+	LiveHeap uint64
+	// GCCPUFraction is the fraction of the CPU time used by the process which was spent in the GC.
+	// The runtime updates the CPU metrics at each GC cycle, so it is an estimate.
+	GCCPUFraction float64
+}
+
+const (
+	gcCyclesMetric   = "/gc/cycles/total:gc-cycles"
+	heapObjectMetric = "/memory/classes/heap/objects:bytes"
+	liveHeapMetric   = "/gc/heap/live:bytes"
+	gcCPUMetric      = "/cpu/classes/gc/total:cpu-seconds"
+	totalCPUMetric   = "/cpu/classes/total:cpu-seconds"
+	idleCPUMetric    = "/cpu/classes/idle:cpu-seconds"
+
+	// gcProfileSampleEvery is the number of decodes between samples of the heap size
+	gcProfileSampleEvery = 64
+)
+
+// The indexes of the metrics in a gcSamples
+const (
+	sampleCycles = iota
+	samplePauses
+	sampleHeapObjects
+	sampleLiveHeap
+	sampleGCCPU
+	sampleTotalCPU
+	sampleIdleCPU
+)
+
+// gcPauseMetric returns the name of the GC pause histogram, which was renamed in Go 1.22
+func gcPauseMetric() string {
+	for _, d := range metrics.All() {
+		if d.Name == "/sched/pauses/total/gc:seconds" {
+			return d.Name
+		}
+	}
+	return "/gc/pauses:seconds"
+}
+
+// gcSamples reads the metrics used by ProfileGC
+type gcSamples struct {
+	samples []metrics.Sample
+}
+
+func newGCSamples() *gcSamples {
+	names := []string{
+		sampleCycles:      gcCyclesMetric,
+		samplePauses:      gcPauseMetric(),
+		sampleHeapObjects: heapObjectMetric,
+		sampleLiveHeap:    liveHeapMetric,
+		sampleGCCPU:       gcCPUMetric,
+		sampleTotalCPU:    totalCPUMetric,
+		sampleIdleCPU:     idleCPUMetric,
+	}
+	s := &gcSamples{
+		samples: make([]metrics.Sample, len(names)),
+	}
+	for i, name := range names {
+		s.samples[i].Name = name
+	}
+	return s
+}
+
+func (s *gcSamples) read() {
+	metrics.Read(s.samples)
+}
+
+// uint64 returns the value of sample i, or 0 if the runtime does not support it
+func (s *gcSamples) uint64(i int) uint64 {
+	if s.samples[i].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s.samples[i].Value.Uint64()
+}
+
+// float64 returns the value of sample i, or 0 if the runtime does not support it
+func (s *gcSamples) float64(i int) float64 {
+	if s.samples[i].Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	return s.samples[i].Value.Float64()
+}
+
+// pauses returns a copy of the pause histogram's counts and its bucket boundaries
+func (s *gcSamples) pauses() ([]uint64, []float64) {
+	if s.samples[samplePauses].Value.Kind() != metrics.KindFloat64Histogram {
+		return nil, nil
+	}
+	h := s.samples[samplePauses].Value.Float64Histogram()
+	return append([]uint64(nil), h.Counts...), h.Buckets
+}
+
+// ProfileGC decodes blocks encoded with c over and over for opts.Duration, keeping the last opts.Retain
+// decoded blocks alive, and reports the activity of the garbage collector.
+// If opts.ProfileDir is set, a CPU profile of the workload and a heap profile at its end are written to it.
+func ProfileGC(c Codec, blocks []coin.SignedBlock, opts GCProfileOptions) (GCProfile, error) {
+	data := make([][]byte, len(blocks))
+	for i := range blocks {
+		var err error
+		if data[i], err = c.Encode(&blocks[i]); err != nil {
+			return GCProfile{}, err
+		}
+	}
+
+	retain := opts.Retain
+	if retain <= 0 {
+		retain = 1
+	}
+	retained := make([]coin.SignedBlock, retain)
+
+	if opts.ProfileDir != "" {
+		f, err := os.Create(filepath.Join(opts.ProfileDir, c.Name+".cpu.pprof"))
+		if err != nil {
+			return GCProfile{}, err
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return GCProfile{}, fmt.Errorf("%s: %v", c.Name, err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	// Start from a collected heap, so that earlier workloads are not counted
+	runtime.GC()
+	before := newGCSamples()
+	before.read()
+	sample := newGCSamples()
+
+	var p GCProfile
+	start := time.Now()
+	for time.Since(start) < opts.Duration {
+		for j := 0; j < gcProfileSampleEvery; j++ {
+			obj := &retained[p.Decodes%retain]
+			*obj = coin.SignedBlock{}
+			if _, err := c.Decode(data[p.Decodes%len(data)], obj, DefaultDecodeLimits); err != nil {
+				return GCProfile{}, err
+			}
+			p.Decodes++
+		}
+
+		sample.read()
+		if heap := sample.uint64(sampleHeapObjects); heap > p.PeakHeap {
+			p.PeakHeap = heap
+		}
+	}
+
+	after := newGCSamples()
+	after.read()
+
+	p.Cycles = after.uint64(sampleCycles) - before.uint64(sampleCycles)
+	p.LiveHeap = after.uint64(sampleLiveHeap)
+
+	counts, buckets := after.pauses()
+	beforeCounts, _ := before.pauses()
+	var pauses float64
+	for i, n := range counts {
+		n -= beforeCounts[i]
+		if n == 0 {
+			continue
+		}
+		// Bucket i is [buckets[i], buckets[i+1]); the outer buckets are unbounded
+		lo, hi := buckets[i], buckets[i+1]
+		if math.IsInf(lo, -1) {
+			lo = 0
+		}
+		if math.IsInf(hi, 1) {
+			hi = lo
+		}
+		pauses += float64(n) * (lo + hi) / 2
+		p.PauseMax = time.Duration(hi * float64(time.Second))
+	}
+	p.PauseTotal = time.Duration(pauses * float64(time.Second))
+
+	gcCPU := after.float64(sampleGCCPU) - before.float64(sampleGCCPU)
+	usedCPU := (after.float64(sampleTotalCPU) - after.float64(sampleIdleCPU)) -
+		(before.float64(sampleTotalCPU) - before.float64(sampleIdleCPU))
+	if usedCPU > 0 {
+		p.GCCPUFraction = gcCPU / usedCPU
+	}
+
+	if opts.ProfileDir != "" {
+		pprof.StopCPUProfile()
+		if err := writeHeapProfile(filepath.Join(opts.ProfileDir, c.Name+".heap.pprof")); err != nil {
+			return GCProfile{}, err
+		}
+	}
+
+	// The retained blocks are alive until the heap profile is written
+	runtime.KeepAlive(retained)
+	return p, nil
+}
+
+// writeHeapProfile writes a heap profile as of the last GC cycle to path
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,89 @@
+package serializebench
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	gcProfile  = flag.Duration("gcprofile", 0, "run TestGCProfile, decoding with each serializer for this long")
+	profileDir = flag.String("profiledir", "", "write CPU and heap profiles of each serializer's TestGCProfile workload to this directory")
+)
+
+// TestGCProfile runs a sustained decode workload with each serializer and prints the activity of the GC.
+// It is skipped unless -gcprofile is set:
+//
+//	go test -run TestGCProfile -gcprofile 10s -profiledir profiles
+func TestGCProfile(t *testing.T) {
+	if *gcProfile == 0 {
+		t.Skip("set -gcprofile to profile the GC")
+	}
+	if *profileDir != "" {
+		if err := os.MkdirAll(*profileDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	blocks := GenerateChain(storeBlocks, 1)
+
+	fmt.Printf("%-11s %10s %8s %11s %11s %11s %10s %10s %7s\n",
+		"format", "decodes", "cycles", "cycles/1k", "pause", "max pause", "peak MiB", "live MiB", "GC CPU")
+	for _, c := range Codecs {
+		p, err := ProfileGC(c, blocks, GCProfileOptions{
+			Duration:   *gcProfile,
+			Retain:     storeBlocks,
+			ProfileDir: *profileDir,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fmt.Printf("%-11s %10d %8d %11.2f %11v %11v %10.1f %10.1f %6.1f%%\n",
+			c.Name, p.Decodes, p.Cycles, float64(p.Cycles)*1000/float64(p.Decodes),
+			p.PauseTotal.Round(time.Microsecond), p.PauseMax, float64(p.PeakHeap)/(1<<20), float64(p.LiveHeap)/(1<<20),
+			100*p.GCCPUFraction)
+	}
+}
+
+func TestProfileGC(t *testing.T) {
+	c, err := CodecByName("skyenc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	p, err := ProfileGC(c, GenerateChain(100, 1), GCProfileOptions{
+		Duration:   50 * time.Millisecond,
+		Retain:     100,
+		ProfileDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Decodes == 0 {
+		t.Error("expected blocks to be decoded")
+	}
+	if p.PeakHeap == 0 {
+		t.Error("expected the heap to be sampled")
+	}
+	if p.GCCPUFraction < 0 || p.GCCPUFraction > 1 {
+		t.Errorf("GC CPU fraction %v is out of range", p.GCCPUFraction)
+	}
+
+	for _, name := range []string{"skyenc.cpu.pprof", "skyenc.heap.pprof"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("%s is empty", name)
+		}
+	}
+}
@@ -1018,3 +1018,302 @@ func (d *GencodeVarintAddress) Unmarshal(buf []byte) (uint64, error) {
 	}
 	return i + 0, nil
 }
+
+type GencodeVarintUxOut struct {
+	Head GencodeVarintUxHead
+	Body GencodeVarintUxBody
+}
+
+func (d *GencodeVarintUxOut) Size() (s uint64) {
+
+	{
+		s += d.Head.Size()
+	}
+	{
+		s += d.Body.Size()
+	}
+	return
+}
+func (d *GencodeVarintUxOut) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		nbuf, err := d.Head.Marshal(buf[0:])
+		if err != nil {
+			return nil, err
+		}
+		i += uint64(len(nbuf))
+	}
+	{
+		nbuf, err := d.Body.Marshal(buf[i+0:])
+		if err != nil {
+			return nil, err
+		}
+		i += uint64(len(nbuf))
+	}
+	return buf[:i+0], nil
+}
+
+func (d *GencodeVarintUxOut) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		ni, err := d.Head.Unmarshal(buf[i+0:])
+		if err != nil {
+			return 0, err
+		}
+		i += ni
+	}
+	{
+		ni, err := d.Body.Unmarshal(buf[i+0:])
+		if err != nil {
+			return 0, err
+		}
+		i += ni
+	}
+	return i + 0, nil
+}
+
+type GencodeVarintUxHead struct {
+	Time  uint64
+	BkSeq uint64
+}
+
+func (d *GencodeVarintUxHead) Size() (s uint64) {
+
+	{
+
+		t := d.Time
+		for t >= 0x80 {
+			t >>= 7
+			s++
+		}
+		s++
+
+	}
+	{
+
+		t := d.BkSeq
+		for t >= 0x80 {
+			t >>= 7
+			s++
+		}
+		s++
+
+	}
+	return
+}
+func (d *GencodeVarintUxHead) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+
+		t := uint64(d.Time)
+
+		for t >= 0x80 {
+			buf[i+0] = byte(t) | 0x80
+			t >>= 7
+			i++
+		}
+		buf[i+0] = byte(t)
+		i++
+
+	}
+	{
+
+		t := uint64(d.BkSeq)
+
+		for t >= 0x80 {
+			buf[i+0] = byte(t) | 0x80
+			t >>= 7
+			i++
+		}
+		buf[i+0] = byte(t)
+		i++
+
+	}
+	return buf[:i+0], nil
+}
+
+func (d *GencodeVarintUxHead) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+
+		bs := uint8(7)
+		t := uint64(buf[i+0] & 0x7F)
+		for buf[i+0]&0x80 == 0x80 {
+			i++
+			t |= uint64(buf[i+0]&0x7F) << bs
+			bs += 7
+		}
+		i++
+
+		d.Time = t
+
+	}
+	{
+
+		bs := uint8(7)
+		t := uint64(buf[i+0] & 0x7F)
+		for buf[i+0]&0x80 == 0x80 {
+			i++
+			t |= uint64(buf[i+0]&0x7F) << bs
+			bs += 7
+		}
+		i++
+
+		d.BkSeq = t
+
+	}
+	return i + 0, nil
+}
+
+type GencodeVarintUxBody struct {
+	SrcTransaction [32]byte
+	Address        GencodeVarintAddress
+	Coins          uint64
+	Hours          uint64
+}
+
+func (d *GencodeVarintUxBody) Size() (s uint64) {
+
+	{
+		s += 32
+	}
+	{
+		s += d.Address.Size()
+	}
+	{
+
+		t := d.Coins
+		for t >= 0x80 {
+			t >>= 7
+			s++
+		}
+		s++
+
+	}
+	{
+
+		t := d.Hours
+		for t >= 0x80 {
+			t >>= 7
+			s++
+		}
+		s++
+
+	}
+	return
+}
+func (d *GencodeVarintUxBody) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		copy(buf[i+0:], d.SrcTransaction[:])
+		i += 32
+	}
+	{
+		nbuf, err := d.Address.Marshal(buf[i+0:])
+		if err != nil {
+			return nil, err
+		}
+		i += uint64(len(nbuf))
+	}
+	{
+
+		t := uint64(d.Coins)
+
+		for t >= 0x80 {
+			buf[i+0] = byte(t) | 0x80
+			t >>= 7
+			i++
+		}
+		buf[i+0] = byte(t)
+		i++
+
+	}
+	{
+
+		t := uint64(d.Hours)
+
+		for t >= 0x80 {
+			buf[i+0] = byte(t) | 0x80
+			t >>= 7
+			i++
+		}
+		buf[i+0] = byte(t)
+		i++
+
+	}
+	return buf[:i+0], nil
+}
+
+func (d *GencodeVarintUxBody) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		copy(d.SrcTransaction[:], buf[i+0:])
+		i += 32
+	}
+	{
+		ni, err := d.Address.Unmarshal(buf[i+0:])
+		if err != nil {
+			return 0, err
+		}
+		i += ni
+	}
+	{
+
+		bs := uint8(7)
+		t := uint64(buf[i+0] & 0x7F)
+		for buf[i+0]&0x80 == 0x80 {
+			i++
+			t |= uint64(buf[i+0]&0x7F) << bs
+			bs += 7
+		}
+		i++
+
+		d.Coins = t
+
+	}
+	{
+
+		bs := uint8(7)
+		t := uint64(buf[i+0] & 0x7F)
+		for buf[i+0]&0x80 == 0x80 {
+			i++
+			t |= uint64(buf[i+0]&0x7F) << bs
+			bs += 7
+		}
+		i++
+
+		d.Hours = t
+
+	}
+	return i + 0, nil
+}
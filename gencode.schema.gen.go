@@ -861,3 +861,252 @@ func (d *GencodeAddress) Unmarshal(buf []byte) (uint64, error) {
 	}
 	return i + 1, nil
 }
+
+type GencodeUxOut struct {
+	Head GencodeUxHead
+	Body GencodeUxBody
+}
+
+func (d *GencodeUxOut) Size() (s uint64) {
+
+	{
+		s += d.Head.Size()
+	}
+	{
+		s += d.Body.Size()
+	}
+	return
+}
+func (d *GencodeUxOut) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		nbuf, err := d.Head.Marshal(buf[0:])
+		if err != nil {
+			return nil, err
+		}
+		i += uint64(len(nbuf))
+	}
+	{
+		nbuf, err := d.Body.Marshal(buf[i+0:])
+		if err != nil {
+			return nil, err
+		}
+		i += uint64(len(nbuf))
+	}
+	return buf[:i+0], nil
+}
+
+func (d *GencodeUxOut) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		ni, err := d.Head.Unmarshal(buf[i+0:])
+		if err != nil {
+			return 0, err
+		}
+		i += ni
+	}
+	{
+		ni, err := d.Body.Unmarshal(buf[i+0:])
+		if err != nil {
+			return 0, err
+		}
+		i += ni
+	}
+	return i + 0, nil
+}
+
+type GencodeUxHead struct {
+	Time  uint64
+	BkSeq uint64
+}
+
+func (d *GencodeUxHead) Size() (s uint64) {
+
+	s += 16
+	return
+}
+func (d *GencodeUxHead) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+
+		buf[0+0] = byte(d.Time >> 0)
+
+		buf[1+0] = byte(d.Time >> 8)
+
+		buf[2+0] = byte(d.Time >> 16)
+
+		buf[3+0] = byte(d.Time >> 24)
+
+		buf[4+0] = byte(d.Time >> 32)
+
+		buf[5+0] = byte(d.Time >> 40)
+
+		buf[6+0] = byte(d.Time >> 48)
+
+		buf[7+0] = byte(d.Time >> 56)
+
+	}
+	{
+
+		buf[0+8] = byte(d.BkSeq >> 0)
+
+		buf[1+8] = byte(d.BkSeq >> 8)
+
+		buf[2+8] = byte(d.BkSeq >> 16)
+
+		buf[3+8] = byte(d.BkSeq >> 24)
+
+		buf[4+8] = byte(d.BkSeq >> 32)
+
+		buf[5+8] = byte(d.BkSeq >> 40)
+
+		buf[6+8] = byte(d.BkSeq >> 48)
+
+		buf[7+8] = byte(d.BkSeq >> 56)
+
+	}
+	return buf[:i+16], nil
+}
+
+func (d *GencodeUxHead) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+
+		d.Time = 0 | (uint64(buf[i+0+0]) << 0) | (uint64(buf[i+1+0]) << 8) | (uint64(buf[i+2+0]) << 16) | (uint64(buf[i+3+0]) << 24) | (uint64(buf[i+4+0]) << 32) | (uint64(buf[i+5+0]) << 40) | (uint64(buf[i+6+0]) << 48) | (uint64(buf[i+7+0]) << 56)
+
+	}
+	{
+
+		d.BkSeq = 0 | (uint64(buf[i+0+8]) << 0) | (uint64(buf[i+1+8]) << 8) | (uint64(buf[i+2+8]) << 16) | (uint64(buf[i+3+8]) << 24) | (uint64(buf[i+4+8]) << 32) | (uint64(buf[i+5+8]) << 40) | (uint64(buf[i+6+8]) << 48) | (uint64(buf[i+7+8]) << 56)
+
+	}
+	return i + 16, nil
+}
+
+type GencodeUxBody struct {
+	SrcTransaction [32]byte
+	Address        GencodeAddress
+	Coins          uint64
+	Hours          uint64
+}
+
+func (d *GencodeUxBody) Size() (s uint64) {
+
+	{
+		s += 32
+	}
+	{
+		s += d.Address.Size()
+	}
+	s += 16
+	return
+}
+func (d *GencodeUxBody) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		copy(buf[i+0:], d.SrcTransaction[:])
+		i += 32
+	}
+	{
+		nbuf, err := d.Address.Marshal(buf[i+0:])
+		if err != nil {
+			return nil, err
+		}
+		i += uint64(len(nbuf))
+	}
+	{
+
+		buf[i+0+0] = byte(d.Coins >> 0)
+
+		buf[i+1+0] = byte(d.Coins >> 8)
+
+		buf[i+2+0] = byte(d.Coins >> 16)
+
+		buf[i+3+0] = byte(d.Coins >> 24)
+
+		buf[i+4+0] = byte(d.Coins >> 32)
+
+		buf[i+5+0] = byte(d.Coins >> 40)
+
+		buf[i+6+0] = byte(d.Coins >> 48)
+
+		buf[i+7+0] = byte(d.Coins >> 56)
+
+	}
+	{
+
+		buf[i+0+8] = byte(d.Hours >> 0)
+
+		buf[i+1+8] = byte(d.Hours >> 8)
+
+		buf[i+2+8] = byte(d.Hours >> 16)
+
+		buf[i+3+8] = byte(d.Hours >> 24)
+
+		buf[i+4+8] = byte(d.Hours >> 32)
+
+		buf[i+5+8] = byte(d.Hours >> 40)
+
+		buf[i+6+8] = byte(d.Hours >> 48)
+
+		buf[i+7+8] = byte(d.Hours >> 56)
+
+	}
+	return buf[:i+16], nil
+}
+
+func (d *GencodeUxBody) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		copy(d.SrcTransaction[:], buf[i+0:])
+		i += 32
+	}
+	{
+		ni, err := d.Address.Unmarshal(buf[i+0:])
+		if err != nil {
+			return 0, err
+		}
+		i += ni
+	}
+	{
+
+		d.Coins = 0 | (uint64(buf[i+0+0]) << 0) | (uint64(buf[i+1+0]) << 8) | (uint64(buf[i+2+0]) << 16) | (uint64(buf[i+3+0]) << 24) | (uint64(buf[i+4+0]) << 32) | (uint64(buf[i+5+0]) << 40) | (uint64(buf[i+6+0]) << 48) | (uint64(buf[i+7+0]) << 56)
+
+	}
+	{
+
+		d.Hours = 0 | (uint64(buf[i+0+8]) << 0) | (uint64(buf[i+1+8]) << 8) | (uint64(buf[i+2+8]) << 16) | (uint64(buf[i+3+8]) << 24) | (uint64(buf[i+4+8]) << 32) | (uint64(buf[i+5+8]) << 40) | (uint64(buf[i+6+8]) << 48) | (uint64(buf[i+7+8]) << 56)
+
+	}
+	return i + 16, nil
+}
@@ -0,0 +1,117 @@
+package serializebench
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+type goldenFixture struct {
+	name  string
+	block coin.SignedBlock
+}
+
+func goldenFixtures() []goldenFixture {
+	maxValues := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				Version: math.MaxUint32,
+				Time:    math.MaxUint64,
+				BkSeq:   math.MaxUint64,
+				Fee:     math.MaxUint64,
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{
+					{
+						Length: math.MaxUint32,
+						Type:   math.MaxUint8,
+						Sigs:   []cipher.Sig{{}},
+						In:     []cipher.SHA256{{}},
+						Out: []coin.TransactionOutput{
+							{
+								Address: cipher.Address{
+									Version: math.MaxUint8,
+								},
+								Coins: math.MaxUint64,
+								Hours: math.MaxUint64,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i := range maxValues.Sig {
+		maxValues.Sig[i] = 0xff
+	}
+
+	empty := getBlock()
+	empty.Block.Body.Transactions = nil
+
+	return []goldenFixture{
+		{"signed_block", getBlock()},
+		{"no_transactions", empty},
+		{"zero", coin.SignedBlock{}},
+		{"max_values", maxValues},
+	}
+}
+
+// TestGolden compares the encoding of each fixture with testdata/golden/<format>/<fixture>.bin,
+// and checks that the golden file decodes to the fixture.
+// gotiny is not covered: its wire format is undocumented and changes between gotiny versions.
+// Run with -update to regenerate the golden files.
+func TestGolden(t *testing.T) {
+	for _, c := range Codecs {
+		if c.Name == "gotiny" {
+			continue
+		}
+
+		for _, f := range goldenFixtures() {
+			t.Run(c.Name+"/"+f.name, func(t *testing.T) {
+				path := filepath.Join("testdata", "golden", c.Name, f.name+".bin")
+
+				data, err := c.Encode(&f.block)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if *update {
+					if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+						t.Fatal(err)
+					}
+					if err := ioutil.WriteFile(path, data, 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				golden, err := ioutil.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.Equal(data, golden) {
+					t.Errorf("encoding differs from %s:\n got: %x\nwant: %x", path, data, golden)
+				}
+
+				var result coin.SignedBlock
+				if _, err := c.Decode(golden, &result, DefaultDecodeLimits); err != nil {
+					t.Fatal(err)
+				}
+
+				if diff := diffBlocks(f.block, result); diff != "" {
+					t.Errorf("%s decodes to a different block:\n%s", path, diff)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// A header stream encodes a sequence of coin.BlockHeader, each relative to the previous header.
+// Each header starts with a flags byte, followed by:
+//
+//	Version   uvarint, if headerVersion is set, otherwise the previous header's Version
+//	BkSeq     zigzag varint difference from the previous BkSeq + 1, if headerSeq is set, otherwise the previous BkSeq + 1
+//	Time      zigzag varint difference from the previous Time
+//	Fee       zigzag varint difference from the previous Fee
+//	PrevHash  32 bytes, if headerPrevHash is set, otherwise the hash of the previous header
+//	BodyHash  32 bytes
+//	UxHash    32 bytes
+//
+// The first header is relative to a zero header whose BkSeq is -1 and whose hash is zero,
+// so a genesis header needs neither BkSeq nor PrevHash. Differences wrap around, so any header is encoded exactly.
+
+// Header stream flags
+const (
+	headerVersion  = 1 << 0
+	headerSeq      = 1 << 1
+	headerPrevHash = 1 << 2
+
+	headerFlags = headerVersion | headerSeq | headerPrevHash
+)
+
+// headerSize is the size of a coin.BlockHeader in the Skycoin encoder format
+const headerSize = 124
+
+// putHeader writes h in the Skycoin encoder format to b, which must be headerSize bytes
+func putHeader(b []byte, h *coin.BlockHeader) {
+	binary.LittleEndian.PutUint32(b[0:], h.Version)
+	binary.LittleEndian.PutUint64(b[4:], h.Time)
+	binary.LittleEndian.PutUint64(b[12:], h.BkSeq)
+	binary.LittleEndian.PutUint64(b[20:], h.Fee)
+	copy(b[28:], h.PrevHash[:])
+	copy(b[60:], h.BodyHash[:])
+	copy(b[92:], h.UxHash[:])
+}
+
+// headerHash returns the same hash as coin.BlockHeader.Hash, the SHA256 of the header
+// in the Skycoin encoder format, without the reflect-based encoder
+func headerHash(h *coin.BlockHeader) cipher.SHA256 {
+	var b [headerSize]byte
+	putHeader(b[:], h)
+	return cipher.SumSHA256(b[:])
+}
+
+func appendVarint(buf []byte, x int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutVarint(b[:], x)]...)
+}
+
+// headerState is the previous header of a stream, which the next header is encoded relative to
+type headerState struct {
+	version uint32
+	seq     uint64
+	time    uint64
+	fee     uint64
+	hash    cipher.SHA256
+}
+
+func newHeaderState() headerState {
+	return headerState{
+		seq: ^uint64(0),
+	}
+}
+
+func (s *headerState) update(h *coin.BlockHeader) {
+	s.version = h.Version
+	s.seq = h.BkSeq
+	s.time = h.Time
+	s.fee = h.Fee
+	s.hash = headerHash(h)
+}
+
+// HeaderEncoder encodes a header stream
+type HeaderEncoder struct {
+	prev headerState
+}
+
+// NewHeaderEncoder returns a HeaderEncoder for a new stream
+func NewHeaderEncoder() *HeaderEncoder {
+	return &HeaderEncoder{
+		prev: newHeaderState(),
+	}
+}
+
+// Encode appends h, encoded relative to the previously encoded header, to buf
+func (e *HeaderEncoder) Encode(buf []byte, h *coin.BlockHeader) []byte {
+	var flags byte
+	if h.Version != e.prev.version {
+		flags |= headerVersion
+	}
+	if h.BkSeq != e.prev.seq+1 {
+		flags |= headerSeq
+	}
+	if h.PrevHash != e.prev.hash {
+		flags |= headerPrevHash
+	}
+
+	buf = append(buf, flags)
+	if flags&headerVersion != 0 {
+		buf = appendUvarint(buf, uint64(h.Version))
+	}
+	if flags&headerSeq != 0 {
+		buf = appendVarint(buf, int64(h.BkSeq-(e.prev.seq+1)))
+	}
+	buf = appendVarint(buf, int64(h.Time-e.prev.time))
+	buf = appendVarint(buf, int64(h.Fee-e.prev.fee))
+	if flags&headerPrevHash != 0 {
+		buf = append(buf, h.PrevHash[:]...)
+	}
+	buf = append(buf, h.BodyHash[:]...)
+	buf = append(buf, h.UxHash[:]...)
+
+	e.prev.update(h)
+
+	return buf
+}
+
+// HeaderDecoder decodes a header stream
+type HeaderDecoder struct {
+	prev headerState
+}
+
+// NewHeaderDecoder returns a HeaderDecoder for a new stream
+func NewHeaderDecoder() *HeaderDecoder {
+	return &HeaderDecoder{
+		prev: newHeaderState(),
+	}
+}
+
+// headerReader reads a header, with the field paths of limitScanner for errors
+type headerReader struct {
+	limitScanner
+}
+
+func (r *headerReader) hash(field string) (cipher.SHA256, error) {
+	var h cipher.SHA256
+	b, err := r.take(len(h), field)
+	if err != nil {
+		return h, err
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// Decode decodes the next header of the stream from the start of buf into h and returns the number of bytes read.
+// Errors are a *DecodeError with an offset in buf. A failed decode leaves the HeaderDecoder unusable.
+func (d *HeaderDecoder) Decode(buf []byte, h *coin.BlockHeader) (int, error) {
+	r := &headerReader{
+		limitScanner: newLimitScanner(wireLayout{}, buf),
+	}
+
+	if len(buf) == 0 {
+		return 0, r.fail(ErrTruncated, 0, "", nil)
+	}
+	flags := buf[0]
+	if flags&^headerFlags != 0 {
+		return 0, r.fail(ErrMalformed, 0, "", fmt.Errorf("unknown header flags %#x", flags))
+	}
+	r.buf = buf[1:]
+
+	h.Version = d.prev.version
+	if flags&headerVersion != 0 {
+		offset := r.offset()
+		v, err := r.uvarint("Version")
+		if err != nil {
+			return 0, err
+		}
+		if v>>32 != 0 {
+			return 0, r.fail(ErrMalformed, offset, "Version", fmt.Errorf("version %d overflows uint32", v))
+		}
+		h.Version = uint32(v)
+	}
+
+	h.BkSeq = d.prev.seq + 1
+	if flags&headerSeq != 0 {
+		delta, err := r.varint("BkSeq")
+		if err != nil {
+			return 0, err
+		}
+		h.BkSeq += uint64(delta)
+	}
+
+	delta, err := r.varint("Time")
+	if err != nil {
+		return 0, err
+	}
+	h.Time = d.prev.time + uint64(delta)
+
+	if delta, err = r.varint("Fee"); err != nil {
+		return 0, err
+	}
+	h.Fee = d.prev.fee + uint64(delta)
+
+	h.PrevHash = d.prev.hash
+	if flags&headerPrevHash != 0 {
+		if h.PrevHash, err = r.hash("PrevHash"); err != nil {
+			return 0, err
+		}
+	}
+	if h.BodyHash, err = r.hash("BodyHash"); err != nil {
+		return 0, err
+	}
+	if h.UxHash, err = r.hash("UxHash"); err != nil {
+		return 0, err
+	}
+
+	d.prev.update(h)
+
+	return r.offset(), nil
+}
+
+// EncodeHeaders encodes headers as a header stream
+func EncodeHeaders(headers []coin.BlockHeader) []byte {
+	e := NewHeaderEncoder()
+	var buf []byte
+	for i := range headers {
+		buf = e.Encode(buf, &headers[i])
+	}
+	return buf
+}
+
+// DecodeHeaders decodes a header stream. The offsets of errors are offsets in buf,
+// and their paths start with the index of the header, such as [3].Time.
+func DecodeHeaders(buf []byte) ([]coin.BlockHeader, error) {
+	d := NewHeaderDecoder()
+	var headers []coin.BlockHeader
+	for offset := 0; offset < len(buf); {
+		var h coin.BlockHeader
+		n, err := d.Decode(buf[offset:], &h)
+		if err != nil {
+			e := err.(*DecodeError)
+			e.Offset += offset
+			e.Path = fieldIndexPath(len(headers), e.Path)
+			return nil, e
+		}
+		headers = append(headers, h)
+		offset += n
+	}
+	return headers, nil
+}
+
+func fieldIndexPath(i int, path string) string {
+	if path == "" {
+		return fmt.Sprintf("[%d]", i)
+	}
+	return fmt.Sprintf("[%d].%s", i, path)
+}
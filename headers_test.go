@@ -0,0 +1,225 @@
+package serializebench
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// headerChainSize is the number of generated headers the header stream is measured with
+const headerChainSize = 100000
+
+func chainHeaders(n int) []coin.BlockHeader {
+	blocks := GenerateChain(n, 1)
+	headers := make([]coin.BlockHeader, len(blocks))
+	for i := range blocks {
+		headers[i] = blocks[i].Block.Head
+	}
+	return headers
+}
+
+func TestHeaderHash(t *testing.T) {
+	block := getBlock()
+	if h := headerHash(&block.Block.Head); h != block.Block.Head.Hash() {
+		t.Errorf("expected %s, got %s", block.Block.Head.Hash().Hex(), h.Hex())
+	}
+}
+
+func TestHeaderStreamRoundTrip(t *testing.T) {
+	block := getBlock()
+	chain := chainHeaders(100)
+
+	// Headers which do not follow the previous header
+	unlinked := []coin.BlockHeader{
+		block.Block.Head,
+		{},
+		{
+			Version:  math.MaxUint32,
+			Time:     math.MaxUint64,
+			BkSeq:    math.MaxUint64,
+			Fee:      math.MaxUint64,
+			PrevHash: block.Block.Head.UxHash,
+		},
+		{},
+		block.Block.Head,
+		block.Block.Head,
+	}
+
+	cases := map[string][]coin.BlockHeader{
+		"chain":    chain,
+		"unlinked": unlinked,
+		"mixed":    append(append(append([]coin.BlockHeader(nil), chain[:50]...), unlinked...), chain[50:]...),
+	}
+
+	for name, headers := range cases {
+		t.Run(name, func(t *testing.T) {
+			data := EncodeHeaders(headers)
+
+			result, err := DecodeHeaders(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(headers, result) {
+				t.Error(cmp.Diff(headers, result))
+			}
+		})
+	}
+}
+
+func TestHeaderStreamLinked(t *testing.T) {
+	// In a generated chain the genesis header has BkSeq 0 and no PrevHash, and every following header
+	// links to the previous one, so no header needs a Version, BkSeq or PrevHash
+	headers := chainHeaders(10)
+	e := NewHeaderEncoder()
+	for i := range headers {
+		if flags := e.Encode(nil, &headers[i])[0]; flags != 0 {
+			t.Errorf("header %d has flags %#x", i, flags)
+		}
+	}
+}
+
+func TestHeaderStreamErrors(t *testing.T) {
+	headers := chainHeaders(3)
+	data := EncodeHeaders(headers)
+
+	t.Run("truncated", func(t *testing.T) {
+		// Every prefix which ends inside a header is truncated
+		e := NewHeaderEncoder()
+		ends := map[int]bool{}
+		n := 0
+		for i := range headers {
+			n += len(e.Encode(nil, &headers[i]))
+			ends[n] = true
+		}
+
+		for i := 1; i < len(data); i++ {
+			_, err := DecodeHeaders(data[:i])
+			if ends[i] {
+				if err != nil {
+					t.Errorf("prefix %d: %v", i, err)
+				}
+				continue
+			}
+			if !errors.Is(err, ErrTruncated) {
+				t.Fatalf("prefix %d: expected ErrTruncated, got %v", i, err)
+			}
+		}
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		second := len(NewHeaderEncoder().Encode(nil, &headers[0]))
+		corrupt := append([]byte(nil), data...)
+		corrupt[second] = 0x80
+
+		_, err := DecodeHeaders(corrupt)
+		if !errors.Is(err, ErrMalformed) {
+			t.Fatalf("expected ErrMalformed, got %v", err)
+		}
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("expected *DecodeError, got %T", err)
+		}
+		if decodeErr.Offset != second || decodeErr.Path != "[1]" {
+			t.Errorf("unexpected error location %d %q", decodeErr.Offset, decodeErr.Path)
+		}
+	})
+}
+
+// TestHeaderStreamSize prints the size of the headers of a generated chain in the header stream format
+// and in the 124 byte Skycoin header layout
+func TestHeaderStreamSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates a long chain")
+	}
+
+	headers := chainHeaders(headerChainSize)
+	data := EncodeHeaders(headers)
+	fixed := len(headers) * headerSize
+
+	fmt.Printf("%-14s %12s %12s %8s\n", "format", "bytes", "per header", "ratio")
+	fmt.Printf("%-14s %12d %12.1f %8.3f\n", "sky header", fixed, float64(fixed)/float64(len(headers)), 1.0)
+	fmt.Printf("%-14s %12d %12.1f %8.3f\n", "header stream", len(data),
+		float64(len(data))/float64(len(headers)), float64(len(data))/float64(fixed))
+
+	result, err := DecodeHeaders(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != len(headers) {
+		t.Fatalf("decoded %d headers of %d", len(result), len(headers))
+	}
+	for i := range headers {
+		if result[i] != headers[i] {
+			t.Fatalf("header %d differs: %s", i, cmp.Diff(headers[i], result[i]))
+		}
+	}
+}
+
+/* header stream
+
+- Headers encoded relative to the previous header
+*/
+
+func BenchmarkMarshalHeadersBySkyLayout(b *testing.B) {
+	headers := chainHeaders(compressBatchSize)
+	buf := make([]byte, len(headers)*headerSize)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for j := range headers {
+			putHeader(buf[j*headerSize:], &headers[j])
+		}
+	}
+}
+
+func BenchmarkMarshalHeadersByHeaderStream(b *testing.B) {
+	headers := chainHeaders(compressBatchSize)
+	buf := make([]byte, 0, len(headers)*headerSize)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		e := NewHeaderEncoder()
+		buf = buf[:0]
+		for j := range headers {
+			buf = e.Encode(buf, &headers[j])
+		}
+	}
+
+	b.ReportMetric(float64(len(buf)), "bytes")
+}
+
+func BenchmarkUnmarshalHeadersByHeaderStream(b *testing.B) {
+	headers := chainHeaders(compressBatchSize)
+	data := EncodeHeaders(headers)
+	result := make([]coin.BlockHeader, len(headers))
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		d := NewHeaderDecoder()
+		buf := data
+		for j := range result {
+			n, err := d.Decode(buf, &result[j])
+			if err != nil {
+				b.Fatal(err)
+			}
+			buf = buf[n:]
+		}
+
+		if validate {
+			if !cmp.Equal(result, headers) {
+				b.Fatal("header stream unmarshal result differs")
+			}
+		}
+	}
+}
@@ -0,0 +1,942 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// DecodeLimits bounds the resources a decoder may use for an untrusted coin.SignedBlock payload.
+// A zero field means no limit.
+type DecodeLimits struct {
+	// MaxBytes is the maximum size of the encoded payload
+	MaxBytes int
+	// MaxTransactions is the maximum number of transactions in a block
+	MaxTransactions int
+	// MaxSigs is the maximum number of signatures in a transaction
+	MaxSigs int
+	// MaxInputs is the maximum number of inputs in a transaction
+	MaxInputs int
+	// MaxOutputs is the maximum number of outputs in a transaction
+	MaxOutputs int
+	// MaxDepth is the maximum nesting of structs, lists and arrays.
+	// Schema-based formats have the fixed depth of coin.SignedBlock (signedBlockDepth);
+	// JSON's depth is measured from the payload.
+	MaxDepth int
+}
+
+// DefaultDecodeLimits are the limits used when none are configured.
+// They match the 65535 element limit of the Skycoin encoder and the 16MiB size limit of Colfer.
+var DefaultDecodeLimits = DecodeLimits{
+	MaxBytes:        16 * 1024 * 1024,
+	MaxTransactions: 65535,
+	MaxSigs:         65535,
+	MaxInputs:       65535,
+	MaxOutputs:      65535,
+	MaxDepth:        32,
+}
+
+// signedBlockDepth is the nesting depth of coin.SignedBlock:
+// SignedBlock.Block.Body.Transactions[i].Out[j].Address.Key[k]
+const signedBlockDepth = 9
+
+// LimitError describes the DecodeLimits bound exceeded by a payload.
+// It is wrapped by a *DecodeError of kind ErrLimitExceeded, which is returned
+// before any allocation is made for the offending element count.
+type LimitError struct {
+	// Limit is the name of the exceeded limit, usually a DecodeLimits field name
+	Limit string
+	// Max is the configured maximum
+	Max int
+	// Value is the value claimed by the payload
+	Value uint64
+}
+
+// Error implements the error interface
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("decode limit %s exceeded: %d > %d", e.Limit, e.Value, e.Max)
+}
+
+// max returns the value of the limit with the given DecodeLimits field name
+func (l DecodeLimits) max(name string) int {
+	switch name {
+	case "MaxBytes":
+		return l.MaxBytes
+	case "MaxTransactions":
+		return l.MaxTransactions
+	case "MaxSigs":
+		return l.MaxSigs
+	case "MaxInputs":
+		return l.MaxInputs
+	case "MaxOutputs":
+		return l.MaxOutputs
+	case "MaxDepth":
+		return l.MaxDepth
+	default:
+		panic("unknown decode limit " + name)
+	}
+}
+
+// checkLimit returns a *DecodeError wrapping a *LimitError if max is configured and value exceeds it
+func checkLimit(name string, max int, value uint64, offset int, path string) error {
+	if max > 0 && value > uint64(max) {
+		return &DecodeError{
+			Kind:   ErrLimitExceeded,
+			Offset: offset,
+			Path:   path,
+			Err: &LimitError{
+				Limit: name,
+				Max:   max,
+				Value: value,
+			},
+		}
+	}
+	return nil
+}
+
+// checkCommonLimits checks the limits that do not require scanning the payload
+func checkCommonLimits(buf []byte, limits DecodeLimits) error {
+	if err := checkLimit("MaxBytes", limits.MaxBytes, uint64(len(buf)), -1, ""); err != nil {
+		return err
+	}
+	return checkLimit("MaxDepth", limits.MaxDepth, signedBlockDepth, -1, "")
+}
+
+// checkDecodedLimits checks the element counts of an already decoded block.
+// It is used by decoders whose wire format can not be scanned in advance.
+func checkDecodedLimits(obj *coin.SignedBlock, limits DecodeLimits) error {
+	txns := obj.Block.Body.Transactions
+	if err := checkLimit("MaxTransactions", limits.MaxTransactions, uint64(len(txns)), -1, "Block.Body.Transactions"); err != nil {
+		return err
+	}
+
+	for i, txn := range txns {
+		path := fmt.Sprintf("Block.Body.Transactions[%d]", i)
+		if err := checkLimit("MaxSigs", limits.MaxSigs, uint64(len(txn.Sigs)), -1, path+".Sigs"); err != nil {
+			return err
+		}
+		if err := checkLimit("MaxInputs", limits.MaxInputs, uint64(len(txn.In)), -1, path+".In"); err != nil {
+			return err
+		}
+		if err := checkLimit("MaxOutputs", limits.MaxOutputs, uint64(len(txn.Out)), -1, path+".Out"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// wireLayout describes how a format lays out the fields of a coin.SignedBlock
+type wireLayout struct {
+	// order is the byte order of fixed-width integers and length prefixes
+	order binary.ByteOrder
+	// varint encodes integers as varints
+	varint bool
+	// varintLength encodes length prefixes as varints
+	varintLength bool
+	// minIntSize is the minimum width of a fixed-width integer
+	minIntSize int
+	// padding is the alignment of fixed-size byte arrays, which are padded with zeros
+	padding int
+	// sigFirst places the block signature before the block instead of after it
+	sigFirst bool
+	// rawBytes writes single-byte integers as they are when integers are varints
+	rawBytes bool
+	// nilLength writes a length prefix as the length plus one, with zero for a nil list
+	nilLength bool
+}
+
+var (
+	// skyLayout is the Skycoin encoder format, used by sky, skyenc and cgfixed
+	skyLayout = wireLayout{
+		order: binary.LittleEndian,
+	}
+
+	// bigEndianLayout is the codecgen bigendian format, the Skycoin layout in network byte order
+	bigEndianLayout = wireLayout{
+		order: binary.BigEndian,
+	}
+
+	// xdrUnpaddedLayout is the codecgen xdr format, XDR without padding after byte arrays
+	xdrUnpaddedLayout = wireLayout{
+		order:      binary.BigEndian,
+		minIntSize: 4,
+	}
+
+	// codecgenVarintLayout is the codecgen varint format
+	codecgenVarintLayout = wireLayout{
+		varint:       true,
+		varintLength: true,
+	}
+
+	// gencodeLayout is the gencode.schema format
+	gencodeLayout = wireLayout{
+		order:        binary.LittleEndian,
+		varintLength: true,
+		sigFirst:     true,
+	}
+
+	// gencodeVarintLayout is the gencode-varint.schema format
+	gencodeVarintLayout = wireLayout{
+		varint:       true,
+		varintLength: true,
+		sigFirst:     true,
+	}
+
+	// gotinyLayout is the gotiny format: varint integers except for bytes,
+	// and varint length prefixes which distinguish nil lists from empty ones
+	gotinyLayout = wireLayout{
+		varint:       true,
+		varintLength: true,
+		rawBytes:     true,
+		nilLength:    true,
+	}
+
+	// xdr2Layout is the XDR format: big-endian, at least 4 bytes per integer, padded to 4 bytes
+	xdr2Layout = wireLayout{
+		order:      binary.BigEndian,
+		minIntSize: 4,
+		padding:    4,
+	}
+)
+
+// pathElem is a field in a field path, with the index of the element being scanned if it is a list
+type pathElem struct {
+	name  string
+	index int
+}
+
+// limitScanner walks an encoded coin.SignedBlock without decoding it,
+// keeping track of the field path for error messages
+type limitScanner struct {
+	layout wireLayout
+	data   []byte
+	buf    []byte
+	path   []pathElem
+	// visit is called with each scanned field, if set
+	visit func(Field)
+	// canonical rejects encodings of a value other than the one its encoder writes
+	canonical bool
+}
+
+func newLimitScanner(layout wireLayout, data []byte) limitScanner {
+	return limitScanner{
+		layout: layout,
+		data:   data,
+		buf:    data,
+	}
+}
+
+// offset returns the offset of the next byte to scan
+func (s *limitScanner) offset() int {
+	return len(s.data) - len(s.buf)
+}
+
+// enter descends into the field name
+func (s *limitScanner) enter(name string) {
+	s.path = append(s.path, pathElem{
+		name:  name,
+		index: -1,
+	})
+}
+
+// at sets the index of the list element being scanned in the current field
+func (s *limitScanner) at(i uint64) {
+	s.path[len(s.path)-1].index = int(i)
+}
+
+// leave returns from the current field
+func (s *limitScanner) leave() {
+	s.path = s.path[:len(s.path)-1]
+}
+
+// fieldPath returns the path of field in the current field
+func (s *limitScanner) fieldPath(field string) string {
+	var b strings.Builder
+	for _, e := range s.path {
+		if b.Len() != 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(e.name)
+		if e.index >= 0 {
+			fmt.Fprintf(&b, "[%d]", e.index)
+		}
+	}
+	if field != "" {
+		if b.Len() != 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(field)
+	}
+	return b.String()
+}
+
+// record reports the field scanned since offset to the visitor.
+// value is the decoded value of int, length and header fields.
+func (s *limitScanner) record(offset int, kind, field string, value uint64) {
+	if s.visit == nil {
+		return
+	}
+
+	f := Field{
+		Offset: offset,
+		Size:   s.offset() - offset,
+		Path:   s.fieldPath(field),
+		Kind:   kind,
+	}
+	switch kind {
+	case "int", "length", "header", "fixed header":
+		f.Value = strconv.FormatUint(value, 10)
+	}
+
+	s.visit(f)
+}
+
+// fail returns a *DecodeError for field starting at offset
+func (s *limitScanner) fail(kind error, offset int, field string, err error) error {
+	return &DecodeError{
+		Kind:   kind,
+		Offset: offset,
+		Path:   s.fieldPath(field),
+		Err:    err,
+	}
+}
+
+func (s *limitScanner) skip(n int, field string) error {
+	if n > len(s.buf) {
+		return s.fail(ErrTruncated, s.offset(), field, nil)
+	}
+	s.buf = s.buf[n:]
+	return nil
+}
+
+func (s *limitScanner) uvarint(field string) (uint64, error) {
+	x, n := binary.Uvarint(s.buf)
+	if n == 0 {
+		return 0, s.fail(ErrTruncated, s.offset(), field, nil)
+	}
+	if n < 0 {
+		return 0, s.fail(ErrMalformed, s.offset(), field, errors.New("varint overflows uint64"))
+	}
+	// A minimal varint does not end with a zero continuation byte
+	if s.canonical && n > 1 && s.buf[n-1] == 0 {
+		return 0, s.fail(ErrNonCanonical, s.offset(), field, errors.New("non-minimal varint"))
+	}
+	s.buf = s.buf[n:]
+	return x, nil
+}
+
+func (s *limitScanner) varint(field string) (int64, error) {
+	x, n := binary.Varint(s.buf)
+	if n == 0 {
+		return 0, s.fail(ErrTruncated, s.offset(), field, nil)
+	}
+	if n < 0 {
+		return 0, s.fail(ErrMalformed, s.offset(), field, errors.New("varint overflows int64"))
+	}
+	s.buf = s.buf[n:]
+	return x, nil
+}
+
+// take returns the next n bytes
+func (s *limitScanner) take(n int, field string) ([]byte, error) {
+	if n > len(s.buf) {
+		return nil, s.fail(ErrTruncated, s.offset(), field, nil)
+	}
+	b := s.buf[:n]
+	s.buf = s.buf[n:]
+	return b, nil
+}
+
+// int skips an integer with a natural size of size bytes
+func (s *limitScanner) int(size int, field string) error {
+	offset := s.offset()
+
+	if s.layout.varint && !(s.layout.rawBytes && size == 1) {
+		x, err := s.uvarint(field)
+		if err != nil {
+			return err
+		}
+		// gencode truncates a varint which overflows the integer
+		if s.canonical && size < 8 && x>>(8*uint(size)) != 0 {
+			return s.fail(ErrNonCanonical, offset, field, fmt.Errorf("varint overflows uint%d", 8*size))
+		}
+		s.record(offset, "int", field, x)
+		return nil
+	}
+
+	if size < s.layout.minIntSize {
+		size = s.layout.minIntSize
+	}
+	if err := s.skip(size, field); err != nil {
+		return err
+	}
+
+	if s.visit != nil {
+		s.record(offset, "int", field, readUint(s.layout.order, s.data[offset:offset+size]))
+	}
+
+	return nil
+}
+
+// readUint reads a fixed-width integer of 1, 2, 4 or 8 bytes
+func readUint(order binary.ByteOrder, b []byte) uint64 {
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(order.Uint16(b))
+	case 4:
+		return uint64(order.Uint32(b))
+	default:
+		return order.Uint64(b)
+	}
+}
+
+// bytes skips a fixed-size byte array and its padding, which must be zero
+func (s *limitScanner) bytes(n int, field string) error {
+	offset := s.offset()
+
+	if s.layout.padding == 0 {
+		if err := s.skip(n, field); err != nil {
+			return err
+		}
+		s.record(offset, "bytes", field, 0)
+		return nil
+	}
+
+	padded := (n + s.layout.padding - 1) / s.layout.padding * s.layout.padding
+	if padded > len(s.buf) {
+		return s.fail(ErrTruncated, offset, field, nil)
+	}
+	s.buf = s.buf[n:]
+	s.record(offset, "bytes", field, 0)
+
+	for i := n; i < padded; i++ {
+		if s.buf[i-n] != 0 {
+			return s.fail(ErrMalformed, offset+i, field, errors.New("non-zero padding"))
+		}
+	}
+	if padded > n {
+		s.buf = s.buf[padded-n:]
+		s.record(offset+n, "padding", field, 0)
+	}
+
+	return nil
+}
+
+// length reads the length prefix of the list field and checks it against a limit
+func (s *limitScanner) length(field, limit string, max int) (uint64, error) {
+	offset := s.offset()
+
+	var n uint64
+	if s.layout.varintLength {
+		x, err := s.uvarint(field)
+		if err != nil {
+			return 0, err
+		}
+		n = x
+		// A nil list is written as 0, and any other list as its length plus one
+		if s.layout.nilLength && n != 0 {
+			n--
+		}
+	} else {
+		if len(s.buf) < 4 {
+			return 0, s.fail(ErrTruncated, offset, field, nil)
+		}
+		n = uint64(s.layout.order.Uint32(s.buf))
+		s.buf = s.buf[4:]
+	}
+
+	s.record(offset, "length", field, n)
+
+	if err := checkLimit(limit, max, n, offset, s.fieldPath(field)); err != nil {
+		return 0, err
+	}
+
+	// Every element takes at least one byte, so a length beyond the remaining bytes is truncated
+	if n > uint64(len(s.buf)) {
+		return 0, s.fail(ErrTruncated, offset, field, fmt.Errorf("length %d exceeds the remaining %d bytes", n, len(s.buf)))
+	}
+
+	return n, nil
+}
+
+// end checks that the whole payload was scanned
+func (s *limitScanner) end() error {
+	if len(s.buf) != 0 {
+		return s.fail(ErrTrailingBytes, s.offset(), "", nil)
+	}
+	return nil
+}
+
+// checkLayoutLimits scans an encoded coin.SignedBlock in the given layout and checks it against limits,
+// before a decoder allocates anything based on the lengths claimed by the payload.
+// The error is a *DecodeError locating the failure.
+func checkLayoutLimits(layout wireLayout, buf []byte, limits DecodeLimits) error {
+	if err := checkCommonLimits(buf, limits); err != nil {
+		return err
+	}
+
+	s := newLimitScanner(layout, buf)
+	return scanLayout(&s, limits)
+}
+
+// checkCanonicalLayout is checkLayoutLimits, which also rejects non-canonical encodings
+// with a *DecodeError of kind ErrNonCanonical
+func checkCanonicalLayout(layout wireLayout, buf []byte, limits DecodeLimits) error {
+	if err := checkCommonLimits(buf, limits); err != nil {
+		return err
+	}
+
+	s := newLimitScanner(layout, buf)
+	s.canonical = true
+	return scanLayout(&s, limits)
+}
+
+// scanLayout walks a coin.SignedBlock with the scanner, checking its element counts against limits
+func scanLayout(s *limitScanner, limits DecodeLimits) error {
+	if s.layout.sigFirst {
+		if err := s.bytes(65, "Sig"); err != nil {
+			return err
+		}
+	}
+
+	s.enter("Block")
+	s.enter("Head")
+	for _, f := range []struct {
+		name string
+		size int
+	}{
+		{"Version", 4},
+		{"Time", 8},
+		{"BkSeq", 8},
+		{"Fee", 8},
+	} {
+		if err := s.int(f.size, f.name); err != nil {
+			return err
+		}
+	}
+	for _, name := range []string{"PrevHash", "BodyHash", "UxHash"} {
+		if err := s.bytes(32, name); err != nil {
+			return err
+		}
+	}
+	s.leave()
+
+	s.enter("Body")
+	nTxns, err := s.length("Transactions", "MaxTransactions", limits.MaxTransactions)
+	if err != nil {
+		return err
+	}
+
+	s.enter("Transactions")
+	for i := uint64(0); i < nTxns; i++ {
+		s.at(i)
+
+		if err := s.int(4, "Length"); err != nil {
+			return err
+		}
+		if err := s.int(1, "Type"); err != nil {
+			return err
+		}
+		if err := s.bytes(32, "InnerHash"); err != nil {
+			return err
+		}
+
+		nSigs, err := s.length("Sigs", "MaxSigs", limits.MaxSigs)
+		if err != nil {
+			return err
+		}
+		s.enter("Sigs")
+		for j := uint64(0); j < nSigs; j++ {
+			s.at(j)
+			if err := s.bytes(65, ""); err != nil {
+				return err
+			}
+		}
+		s.leave()
+
+		nIn, err := s.length("In", "MaxInputs", limits.MaxInputs)
+		if err != nil {
+			return err
+		}
+		s.enter("In")
+		for j := uint64(0); j < nIn; j++ {
+			s.at(j)
+			if err := s.bytes(32, ""); err != nil {
+				return err
+			}
+		}
+		s.leave()
+
+		nOut, err := s.length("Out", "MaxOutputs", limits.MaxOutputs)
+		if err != nil {
+			return err
+		}
+		s.enter("Out")
+		for j := uint64(0); j < nOut; j++ {
+			s.at(j)
+			if err := s.int(1, "Address.Version"); err != nil {
+				return err
+			}
+			if err := s.bytes(20, "Address.Key"); err != nil {
+				return err
+			}
+			if err := s.int(8, "Coins"); err != nil {
+				return err
+			}
+			if err := s.int(8, "Hours"); err != nil {
+				return err
+			}
+		}
+		s.leave()
+	}
+	s.leave()
+	s.leave()
+	s.leave()
+
+	if !s.layout.sigFirst {
+		if err := s.bytes(65, "Sig"); err != nil {
+			return err
+		}
+	}
+
+	return s.end()
+}
+
+// colferKind is the wire type of a field in block.colf
+type colferKind int
+
+const (
+	colferUint8 colferKind = iota
+	colferUint32
+	colferUint64
+	colferBinary
+	colferBinaryList
+	colferStruct
+	colferStructList
+)
+
+// colferField describes a field of a struct in block.colf, indexed by its position in the struct
+type colferField struct {
+	name string
+	kind colferKind
+	// fields are the fields of a struct or struct list element
+	fields []colferField
+	// limit is the name of the DecodeLimits field that bounds a list's length
+	limit string
+}
+
+var (
+	colferAddressFields = []colferField{
+		{name: "Version", kind: colferUint8},
+		{name: "Key", kind: colferBinary},
+	}
+
+	colferTransactionOutputFields = []colferField{
+		{name: "Address", kind: colferStruct, fields: colferAddressFields},
+		{name: "Coins", kind: colferUint64},
+		{name: "Hours", kind: colferUint64},
+	}
+
+	colferTransactionFields = []colferField{
+		{name: "Length", kind: colferUint32},
+		{name: "Type", kind: colferUint8},
+		{name: "InnerHash", kind: colferBinary},
+		{name: "Sigs", kind: colferBinaryList, limit: "MaxSigs"},
+		{name: "In", kind: colferBinaryList, limit: "MaxInputs"},
+		{name: "Out", kind: colferStructList, fields: colferTransactionOutputFields, limit: "MaxOutputs"},
+	}
+
+	colferBlockHeaderFields = []colferField{
+		{name: "Version", kind: colferUint32},
+		{name: "Time", kind: colferUint64},
+		{name: "BkSeq", kind: colferUint64},
+		{name: "Fee", kind: colferUint64},
+		{name: "PrevHash", kind: colferBinary},
+		{name: "BodyHash", kind: colferBinary},
+		{name: "UxHash", kind: colferBinary},
+	}
+
+	colferBlockBodyFields = []colferField{
+		{name: "Transactions", kind: colferStructList, fields: colferTransactionFields, limit: "MaxTransactions"},
+	}
+
+	colferBlockFields = []colferField{
+		{name: "Head", kind: colferStruct, fields: colferBlockHeaderFields},
+		{name: "Body", kind: colferStruct, fields: colferBlockBodyFields},
+	}
+
+	colferSignedBlockFields = []colferField{
+		{name: "Sig", kind: colferBinary},
+		{name: "Block", kind: colferStruct, fields: colferBlockFields},
+	}
+)
+
+// colferScanner walks an encoded ColferSignedBlock without decoding it
+type colferScanner struct {
+	limitScanner
+	limits DecodeLimits
+}
+
+// colferUvarint reads a Colfer varint. Unlike encoding/binary varints,
+// the ninth byte of a 64-bit Colfer varint carries a full 8 bits.
+func (s *colferScanner) colferUvarint(field string) (uint64, error) {
+	offset := s.offset()
+
+	var x uint64
+	for shift := uint(0); ; shift += 7 {
+		if len(s.buf) == 0 {
+			return 0, s.fail(ErrTruncated, offset, field, nil)
+		}
+		b := uint64(s.buf[0])
+		s.buf = s.buf[1:]
+
+		if b < 0x80 || shift == 56 {
+			if s.canonical && b == 0 && shift > 0 {
+				return 0, s.fail(ErrNonCanonical, offset, field, errors.New("non-minimal varint"))
+			}
+			return x | b<<shift, nil
+		}
+		x |= (b & 0x7f) << shift
+	}
+}
+
+// list reads a list length and checks it against the field's limit
+func (s *colferScanner) list(f colferField) (uint64, error) {
+	offset := s.offset()
+
+	n, err := s.colferUvarint(f.name)
+	if err != nil {
+		return 0, err
+	}
+
+	s.record(offset, "length", f.name, n)
+
+	if err := checkLimit(f.limit, s.limits.max(f.limit), n, offset, s.fieldPath(f.name)); err != nil {
+		return 0, err
+	}
+
+	if n > uint64(len(s.buf)) {
+		return 0, s.fail(ErrTruncated, offset, f.name, fmt.Errorf("length %d exceeds the remaining %d bytes", n, len(s.buf)))
+	}
+
+	return n, nil
+}
+
+func (s *colferScanner) binary(field string) error {
+	offset := s.offset()
+
+	n, err := s.colferUvarint(field)
+	if err != nil {
+		return err
+	}
+	s.record(offset, "length", field, n)
+
+	if n > uint64(len(s.buf)) {
+		return s.fail(ErrTruncated, offset, field, fmt.Errorf("size %d exceeds the remaining %d bytes", n, len(s.buf)))
+	}
+
+	offset = s.offset()
+	s.buf = s.buf[n:]
+	s.record(offset, "bytes", field, 0)
+	return nil
+}
+
+// nonCanonical returns a *DecodeError of kind ErrNonCanonical for field starting at offset
+func (s *colferScanner) nonCanonical(offset int, field, format string, args ...interface{}) error {
+	return s.fail(ErrNonCanonical, offset, field, fmt.Errorf(format, args...))
+}
+
+// scanStruct walks a Colfer struct, which is a sequence of fields prefixed by their index
+// and terminated by 0x7f. An index with the 0x80 flag set marks a fixed-width integer.
+//
+// colf's generated code writes a zero field by omitting it, writes an integer as a fixed-width integer
+// only if its varint would be longer, and writes every struct field of coin.SignedBlock.
+// In canonical mode, a payload which does otherwise is rejected.
+func (s *colferScanner) scanStruct(fields []colferField) error {
+	var seen uint64
+	for {
+		if len(s.buf) == 0 {
+			return s.fail(ErrTruncated, s.offset(), "", nil)
+		}
+		offset := s.offset()
+		header := s.buf[0]
+
+		if header == 0x7f {
+			if s.canonical {
+				for i, f := range fields {
+					if f.kind == colferStruct && seen&(1<<uint(i)) == 0 {
+						return s.nonCanonical(offset, f.name, "struct field is omitted")
+					}
+				}
+			}
+			s.buf = s.buf[1:]
+			s.record(offset, "end", "", 0)
+			return nil
+		}
+
+		index := int(header & 0x7f)
+		fixed := header&0x80 != 0
+		if index >= len(fields) {
+			return s.fail(ErrMalformed, offset, "", fmt.Errorf("unknown field header %#x", header))
+		}
+		s.buf = s.buf[1:]
+		seen |= 1 << uint(index)
+
+		f := fields[index]
+		if fixed {
+			s.record(offset, "fixed header", f.name, uint64(index))
+		} else {
+			s.record(offset, "header", f.name, uint64(index))
+		}
+
+		headerOffset := offset
+		offset = s.offset()
+		var err error
+		switch f.kind {
+		case colferUint8:
+			if err = s.skip(1, f.name); err == nil {
+				if s.canonical && s.data[offset] == 0 {
+					return s.nonCanonical(headerOffset, f.name, "zero field is not omitted")
+				}
+				s.record(offset, "int", f.name, uint64(s.data[offset]))
+			}
+
+		case colferUint32, colferUint64:
+			size, fixedMin := 4, uint64(1<<21)
+			if f.kind == colferUint64 {
+				size, fixedMin = 8, 1<<49
+			}
+			if fixed {
+				if err = s.skip(size, f.name); err == nil && (s.visit != nil || s.canonical) {
+					x := readUint(binary.BigEndian, s.data[offset:offset+size])
+					if s.canonical && x < fixedMin {
+						return s.nonCanonical(headerOffset, f.name, "fixed-width integer %d is below %d", x, fixedMin)
+					}
+					s.record(offset, "int", f.name, x)
+				}
+			} else {
+				var x uint64
+				if x, err = s.colferUvarint(f.name); err == nil {
+					if s.canonical && (x == 0 || x >= fixedMin) {
+						return s.nonCanonical(headerOffset, f.name, "varint %d is zero or not below %d", x, fixedMin)
+					}
+					s.record(offset, "int", f.name, x)
+				}
+			}
+
+		case colferBinary:
+			if s.canonical && len(s.buf) != 0 && s.buf[0] == 0 {
+				return s.nonCanonical(headerOffset, f.name, "zero field is not omitted")
+			}
+			err = s.binary(f.name)
+
+		case colferBinaryList:
+			if s.canonical && len(s.buf) != 0 && s.buf[0] == 0 {
+				return s.nonCanonical(headerOffset, f.name, "zero field is not omitted")
+			}
+			var n uint64
+			n, err = s.list(f)
+			s.enter(f.name)
+			for i := uint64(0); err == nil && i < n; i++ {
+				s.at(i)
+				err = s.binary("")
+			}
+			s.leave()
+
+		case colferStruct:
+			s.enter(f.name)
+			err = s.scanStruct(f.fields)
+			s.leave()
+
+		case colferStructList:
+			if s.canonical && len(s.buf) != 0 && s.buf[0] == 0 {
+				return s.nonCanonical(headerOffset, f.name, "zero field is not omitted")
+			}
+			var n uint64
+			n, err = s.list(f)
+			s.enter(f.name)
+			for i := uint64(0); err == nil && i < n; i++ {
+				s.at(i)
+				err = s.scanStruct(f.fields)
+			}
+			s.leave()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// checkColferLimits scans an encoded ColferSignedBlock and checks it against limits.
+// The error is a *DecodeError locating the failure.
+func checkColferLimits(buf []byte, limits DecodeLimits) error {
+	return scanColfer(buf, limits, false)
+}
+
+// checkCanonicalColfer is checkColferLimits, which also rejects non-canonical encodings
+// with a *DecodeError of kind ErrNonCanonical
+func checkCanonicalColfer(buf []byte, limits DecodeLimits) error {
+	return scanColfer(buf, limits, true)
+}
+
+func scanColfer(buf []byte, limits DecodeLimits, canonical bool) error {
+	if err := checkCommonLimits(buf, limits); err != nil {
+		return err
+	}
+
+	s := &colferScanner{
+		limitScanner: newLimitScanner(wireLayout{}, buf),
+		limits:       limits,
+	}
+	s.canonical = canonical
+
+	if err := s.scanStruct(colferSignedBlockFields); err != nil {
+		return err
+	}
+
+	return s.end()
+}
+
+// checkJSONLimits checks the size and nesting depth of a JSON payload.
+// JSON arrays have no length prefix, so element counts can only be checked after decoding.
+func checkJSONLimits(buf []byte, limits DecodeLimits) error {
+	if err := checkLimit("MaxBytes", limits.MaxBytes, uint64(len(buf)), -1, ""); err != nil {
+		return err
+	}
+
+	if limits.MaxDepth <= 0 {
+		return nil
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i, c := range buf {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+			if err := checkLimit("MaxDepth", limits.MaxDepth, uint64(depth), i, ""); err != nil {
+				return err
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+func TestCodecsRoundTrip(t *testing.T) {
+	block := getBlock()
+
+	for _, c := range Codecs {
+		t.Run(c.Name, func(t *testing.T) {
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var result coin.SignedBlock
+			n, err := c.Decode(data, &result, DefaultDecodeLimits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(data) {
+				t.Errorf("read %d bytes of %d", n, len(data))
+			}
+
+			if !cmp.Equal(block, result) {
+				t.Error(cmp.Diff(block, result))
+			}
+		})
+	}
+}
+
+// putClaim replaces the size bytes at offset with a length prefix encoded by put
+func putClaim(data []byte, offset, size int, put func([]byte) int) []byte {
+	claim := make([]byte, binary.MaxVarintLen64)
+	claim = claim[:put(claim)]
+
+	out := append([]byte(nil), data[:offset]...)
+	out = append(out, claim...)
+	return append(out, data[offset+size:]...)
+}
+
+func TestDecodeLimitsHugeTransactionCount(t *testing.T) {
+	const claim = 1<<32 - 1
+
+	putUint32LE := func(b []byte) int {
+		binary.LittleEndian.PutUint32(b, claim)
+		return 4
+	}
+	putUint32BE := func(b []byte) int {
+		binary.BigEndian.PutUint32(b, claim)
+		return 4
+	}
+	putUvarint := func(b []byte) int {
+		return binary.PutUvarint(b, claim)
+	}
+
+	// Each case patches the encoding of a block without transactions to claim 2^32-1 transactions.
+	// json has no length prefixes, so its element counts are checked after decoding and it is not covered here.
+	cases := map[string]func(data []byte) []byte{
+		// The count is followed by the signature
+		"sky": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-4, 4, putUint32LE)
+		},
+		"skyenc": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-4, 4, putUint32LE)
+		},
+		"cgfixed": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-4, 4, putUint32LE)
+		},
+		"cgbe": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-4, 4, putUint32BE)
+		},
+		"cgxdr": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-4, 4, putUint32BE)
+		},
+		"cgvarint": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-1, 1, putUvarint)
+		},
+		// gotiny writes a length as the length plus one
+		"gotiny": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-1, 1, func(b []byte) int {
+				return binary.PutUvarint(b, claim+1)
+			})
+		},
+		"dict": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-1, 1, putUvarint)
+		},
+		// The empty array is a zero block count. Avro counts are zigzag varints.
+		"avro": func(data []byte) []byte {
+			return putClaim(data, len(data)-65-1, 1, func(b []byte) int {
+				return binary.PutVarint(b, claim)
+			})
+		},
+		// The count is followed by the padded signature
+		"xdr2": func(data []byte) []byte {
+			return putClaim(data, len(data)-68-4, 4, putUint32BE)
+		},
+		// The count is the last field
+		"gencode": func(data []byte) []byte {
+			return putClaim(data, len(data)-1, 1, putUvarint)
+		},
+		"gencodevar": func(data []byte) []byte {
+			return putClaim(data, len(data)-1, 1, putUvarint)
+		},
+		// The empty body struct is followed by the block and signed block terminators.
+		// Insert the transactions field (index 0) into the body.
+		"colfer": func(data []byte) []byte {
+			return putClaim(data, len(data)-3, 0, func(b []byte) int {
+				b[0] = 0
+				return 1 + binary.PutUvarint(b[1:], claim)
+			})
+		},
+	}
+
+	block := getBlock()
+	block.Block.Body.Transactions = nil
+
+	for _, c := range Codecs {
+		patch, ok := cases[c.Name]
+		if !ok {
+			if !c.Text {
+				t.Errorf("%s has no transaction count case", c.Name)
+			}
+			continue
+		}
+
+		t.Run(c.Name, func(t *testing.T) {
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data = patch(data)
+
+			var result coin.SignedBlock
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			_, err = c.Decode(data, &result, DefaultDecodeLimits)
+			runtime.ReadMemStats(&after)
+
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("expected *LimitError, got %v", err)
+			}
+			if limitErr.Limit != "MaxTransactions" || limitErr.Value != claim {
+				t.Errorf("unexpected limit error %v", limitErr)
+			}
+
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64*1024 {
+				t.Errorf("allocated %d bytes before rejecting the payload", allocated)
+			}
+		})
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	// The test block has 3 transactions, with up to 3 signatures, 3 inputs and 3 outputs
+	cases := []struct {
+		name  string
+		limit string
+		set   func(*DecodeLimits)
+	}{
+		{
+			name:  "bytes",
+			limit: "MaxBytes",
+			set:   func(l *DecodeLimits) { l.MaxBytes = 1000 },
+		},
+		{
+			name:  "transactions",
+			limit: "MaxTransactions",
+			set:   func(l *DecodeLimits) { l.MaxTransactions = 2 },
+		},
+		{
+			name:  "sigs",
+			limit: "MaxSigs",
+			set:   func(l *DecodeLimits) { l.MaxSigs = 1 },
+		},
+		{
+			name:  "inputs",
+			limit: "MaxInputs",
+			set:   func(l *DecodeLimits) { l.MaxInputs = 1 },
+		},
+		{
+			name:  "outputs",
+			limit: "MaxOutputs",
+			set:   func(l *DecodeLimits) { l.MaxOutputs = 1 },
+		},
+		{
+			name:  "depth",
+			limit: "MaxDepth",
+			set:   func(l *DecodeLimits) { l.MaxDepth = 3 },
+		},
+	}
+
+	block := getBlock()
+
+	for _, c := range Codecs {
+		data, err := c.Encode(&block)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tc := range cases {
+			t.Run(c.Name+"/"+tc.name, func(t *testing.T) {
+				limits := DefaultDecodeLimits
+				tc.set(&limits)
+
+				var result coin.SignedBlock
+				_, err := c.Decode(data, &result, limits)
+
+				var limitErr *LimitError
+				if !errors.As(err, &limitErr) {
+					t.Fatalf("expected *LimitError, got %v", err)
+				}
+				if limitErr.Limit != tc.limit {
+					t.Errorf("expected limit %s, got %v", tc.limit, limitErr)
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeLimitsJSONDepth(t *testing.T) {
+	data := []byte(strings.Repeat("[", 100) + strings.Repeat("]", 100))
+
+	var result coin.SignedBlock
+	_, err := decodeJSON(data, &result, DefaultDecodeLimits)
+
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "MaxDepth" {
+		t.Fatalf("expected MaxDepth *LimitError, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package serializebench
+
+import "fmt"
+
+// MappedBlockStore is a read-only view of the blocks of a BlockStore, with the data file mapped into memory
+// on Linux and read into memory elsewhere. Its blocks are read as a BlockView, which refers to the mapping,
+// so that scanning the store does not allocate.
+type MappedBlockStore struct {
+	layout  wireLayout
+	limits  DecodeLimits
+	data    []byte
+	offsets []int64
+}
+
+// Map writes the buffered appends and maps the blocks stored so far. Blocks appended later are not in the mapping.
+// The store's codec must be one of ViewFormats.
+func (s *BlockStore) Map() (*MappedBlockStore, error) {
+	layout, ok := viewLayout(s.codec.Name)
+	if !ok {
+		return nil, fmt.Errorf("views are not supported for format %q", s.codec.Name)
+	}
+
+	if err := s.flush(); err != nil {
+		return nil, err
+	}
+
+	data, err := mapFile(s.data, s.size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MappedBlockStore{
+		layout:  layout,
+		limits:  viewLimits(layout, s.limits),
+		data:    data,
+		offsets: s.offsets[:len(s.offsets):len(s.offsets)],
+	}, nil
+}
+
+// Len returns the number of blocks in the mapping
+func (m *MappedBlockStore) Len() int {
+	return len(m.offsets)
+}
+
+// View returns a view of the block at index i, which is valid until the store is closed.
+// Invalid blocks, and blocks exceeding the store's limits, are a *DecodeError with the offset in the data file.
+func (m *MappedBlockStore) View(i int) (BlockView, error) {
+	if i < 0 || i >= len(m.offsets) {
+		return BlockView{}, fmt.Errorf("block %d is out of range [0, %d)", i, len(m.offsets))
+	}
+
+	offset := m.offsets[i]
+	end := int64(len(m.data))
+	if i+1 < len(m.offsets) {
+		end = m.offsets[i+1]
+	}
+
+	data, err := recordData(m.data[offset:end], offset)
+	if err != nil {
+		return BlockView{}, err
+	}
+
+	b, err := newBlockView(m.layout, data)
+	if err == nil {
+		err = checkViewLimits(b, data, m.limits)
+	}
+	if err != nil {
+		return BlockView{}, recordError(err, offset)
+	}
+	return b, nil
+}
+
+// Range calls fn with a view of each block in order, stopping at the first error
+func (m *MappedBlockStore) Range(fn func(i int, b BlockView) error) error {
+	for i := range m.offsets {
+		b, err := m.View(i)
+		if err != nil {
+			return err
+		}
+		if err := fn(i, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close unmaps the store. Views of its blocks must not be used afterwards.
+func (m *MappedBlockStore) Close() error {
+	data := m.data
+	m.data = nil
+	m.offsets = nil
+	return unmapFile(data)
+}
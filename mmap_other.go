@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package serializebench
+
+import "os"
+
+// mapFile reads the first size bytes of f into memory, on platforms where the store is not mapped
+func mapFile(f *os.File, size int64) ([]byte, error) {
+	data := make([]byte, size)
+	if _, err := f.ReadAt(data, 0); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// unmapFile releases memory returned by mapFile
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,173 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// A message envelope is framed as the Skycoin daemon frames its messages: a little-endian uint32
+// length of the rest of the message, a 4-byte message type prefix, then the message body.
+//
+// The body of a GiveBlocks message is the number of blocks as a uvarint, followed by each block
+// prefixed with its length as a uvarint, so that any Codec can be used for the blocks.
+
+const (
+	// messageLengthSize is the size of the length prefix of a message envelope
+	messageLengthSize = 4
+	// messagePrefixSize is the size of the message type prefix
+	messagePrefixSize = 4
+)
+
+// GiveBlocksPrefix is the message type prefix of a GiveBlocks message
+var GiveBlocksPrefix = [messagePrefixSize]byte{'G', 'I', 'V', 'B'}
+
+// AppendGiveBlocks appends a GiveBlocks message envelope holding blocks encoded with c to buf
+func AppendGiveBlocks(buf []byte, c Codec, blocks []coin.SignedBlock) ([]byte, error) {
+	start := len(buf)
+	buf = append(buf, make([]byte, messageLengthSize)...)
+	buf = append(buf, GiveBlocksPrefix[:]...)
+	buf = appendUvarint(buf, uint64(len(blocks)))
+
+	for i := range blocks {
+		data, err := c.Encode(&blocks[i])
+		if err != nil {
+			return nil, err
+		}
+		buf = appendUvarint(buf, uint64(len(data)))
+		buf = append(buf, data...)
+	}
+
+	binary.LittleEndian.PutUint32(buf[start:], uint32(len(buf)-start-messageLengthSize))
+	return buf, nil
+}
+
+// MessageReader reads message envelopes from a connection
+type MessageReader struct {
+	r        io.Reader
+	maxBytes int
+	buf      []byte
+}
+
+// NewMessageReader returns a MessageReader reading from r.
+// A message longer than maxBytes is rejected before it is read. With a maxBytes of 0, messages are not limited,
+// and the buffer of a message grows as it is read.
+func NewMessageReader(r io.Reader, maxBytes int) *MessageReader {
+	return &MessageReader{
+		r:        r,
+		maxBytes: maxBytes,
+	}
+}
+
+// Next reads the next message and returns its prefix and body. The body is valid until the next call.
+// It returns io.EOF if the connection is closed between messages.
+func (r *MessageReader) Next() ([messagePrefixSize]byte, []byte, error) {
+	var prefix [messagePrefixSize]byte
+
+	var header [messageLengthSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return prefix, nil, &DecodeError{
+				Kind:   ErrTruncated,
+				Offset: -1,
+				Err:    err,
+			}
+		}
+		return prefix, nil, err
+	}
+
+	n := binary.LittleEndian.Uint32(header[:])
+	if err := checkLimit("MaxBytes", r.maxBytes, uint64(n), 0, ""); err != nil {
+		return prefix, nil, err
+	}
+	if n < messagePrefixSize {
+		return prefix, nil, &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: 0,
+			Err:    fmt.Errorf("message length %d is shorter than its prefix", n),
+		}
+	}
+
+	var err error
+	if r.buf, _, err = readFull(r.r, r.buf, uint64(n)); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return prefix, nil, &DecodeError{
+				Kind:   ErrTruncated,
+				Offset: -1,
+				Err:    io.ErrUnexpectedEOF,
+			}
+		}
+		return prefix, nil, err
+	}
+
+	copy(prefix[:], r.buf)
+	return prefix, r.buf[messagePrefixSize:], nil
+}
+
+// DecodeGiveBlocks decodes the body of a GiveBlocks message with c, appending the blocks to blocks.
+// Errors are a *DecodeError with the offset in the body.
+func DecodeGiveBlocks(body []byte, c Codec, limits DecodeLimits, blocks []coin.SignedBlock) ([]coin.SignedBlock, error) {
+	count, n := binary.Uvarint(body)
+	if n <= 0 {
+		return blocks, &DecodeError{
+			Kind:   ErrTruncated,
+			Offset: 0,
+			Path:   "Blocks",
+		}
+	}
+	// Every block has a length prefix, so a count larger than the body is rejected before allocating
+	if count > uint64(len(body)-n) {
+		return blocks, &DecodeError{
+			Kind:   ErrTruncated,
+			Offset: 0,
+			Path:   "Blocks",
+			Err:    fmt.Errorf("%d blocks in %d bytes", count, len(body)-n),
+		}
+	}
+	offset := n
+
+	for i := uint64(0); i < count; i++ {
+		path := fmt.Sprintf("Blocks[%d]", i)
+
+		size, n := binary.Uvarint(body[offset:])
+		if n <= 0 {
+			return blocks, &DecodeError{
+				Kind:   ErrTruncated,
+				Offset: offset,
+				Path:   path,
+			}
+		}
+		offset += n
+
+		if size > uint64(len(body)-offset) {
+			return blocks, &DecodeError{
+				Kind:   ErrTruncated,
+				Offset: len(body),
+				Path:   path,
+			}
+		}
+
+		blocks = append(blocks, coin.SignedBlock{})
+		if _, err := c.Decode(body[offset:offset+int(size)], &blocks[len(blocks)-1], limits); err != nil {
+			if e, ok := err.(*DecodeError); ok {
+				if e.Offset >= 0 {
+					e.Offset += offset
+				}
+				e.Path = joinPath(path, e.Path)
+			}
+			return blocks[:len(blocks)-1], err
+		}
+		offset += int(size)
+	}
+
+	if offset != len(body) {
+		return blocks, &DecodeError{
+			Kind:   ErrTrailingBytes,
+			Offset: offset,
+		}
+	}
+
+	return blocks, nil
+}
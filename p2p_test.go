@@ -0,0 +1,262 @@
+package serializebench
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// giveBlocksCount is the number of blocks in a benchmarked GiveBlocks message,
+// the number of blocks the Skycoin daemon requests at a time
+const giveBlocksCount = 20
+
+func TestGiveBlocks(t *testing.T) {
+	messages := [][]coin.SignedBlock{
+		{getBlock()},
+		GenerateChain(giveBlocksCount, 1),
+		nil,
+	}
+
+	for _, c := range Codecs {
+		t.Run(c.Name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer server.Close()
+
+			errC := make(chan error, 1)
+			go func() {
+				defer client.Close()
+				var buf []byte
+				for _, blocks := range messages {
+					var err error
+					if buf, err = AppendGiveBlocks(buf[:0], c, blocks); err != nil {
+						errC <- err
+						return
+					}
+					if _, err := client.Write(buf); err != nil {
+						errC <- err
+						return
+					}
+				}
+				errC <- nil
+			}()
+
+			r := NewMessageReader(bufio.NewReader(server), DefaultDecodeLimits.MaxBytes)
+			for i, blocks := range messages {
+				prefix, body, err := r.Next()
+				if err != nil {
+					t.Fatalf("message %d: %v", i, err)
+				}
+				if prefix != GiveBlocksPrefix {
+					t.Fatalf("message %d: unexpected prefix %q", i, prefix)
+				}
+
+				result, err := DecodeGiveBlocks(body, c, DefaultDecodeLimits, nil)
+				if err != nil {
+					t.Fatalf("message %d: %v", i, err)
+				}
+				if diff := diffBlocks(blocks, result); diff != "" {
+					t.Errorf("message %d: %s", i, diff)
+				}
+			}
+
+			if err := <-errC; err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestGiveBlocksErrors(t *testing.T) {
+	c, err := CodecByName("skyenc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := AppendGiveBlocks(nil, c, []coin.SignedBlock{getBlock(), getBlock()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := message[messageLengthSize+messagePrefixSize:]
+
+	// A block followed by a trailing byte inside its length prefix fails in the codec with an empty path
+	data, err := c.Encode(&coin.SignedBlock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := appendUvarint([]byte{1}, uint64(len(data)+1))
+	padded = append(append(padded, data...), 0)
+
+	cases := []struct {
+		name string
+		body []byte
+		kind error
+		path string
+	}{
+		{"empty", nil, ErrTruncated, "Blocks"},
+		{"count exceeds body", []byte{100, 1}, ErrTruncated, "Blocks"},
+		{"truncated block", body[:len(body)-1], ErrTruncated, "Blocks[1]"},
+		{"trailing bytes", append(append([]byte(nil), body...), 0), ErrTrailingBytes, ""},
+		{"trailing bytes in block", padded, ErrTrailingBytes, "Blocks[0]"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeGiveBlocks(tc.body, c, DefaultDecodeLimits, nil)
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) || !errors.Is(err, tc.kind) {
+				t.Fatalf("expected %v, got %v", tc.kind, err)
+			}
+			if decodeErr.Path != tc.path {
+				t.Errorf("expected path %q, got %q", tc.path, decodeErr.Path)
+			}
+		})
+	}
+
+	// A message claiming 1GiB is rejected before it is read
+	var header [messageLengthSize]byte
+	binary.LittleEndian.PutUint32(header[:], 1<<30)
+	r := NewMessageReader(bytes.NewReader(header[:]), DefaultDecodeLimits.MaxBytes)
+	if _, _, err := r.Next(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	// Without a limit, a message claiming 4GiB is truncated after allocating about as much as was sent
+	binary.LittleEndian.PutUint32(header[:], math.MaxUint32)
+	sent := append(header[:], GiveBlocksPrefix[:]...)
+	r = NewMessageReader(bytes.NewReader(sent), 0)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, _, err = r.Next()
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("expected ErrTruncated, got %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1024*1024 {
+		t.Errorf("allocated %d bytes for a message of %d bytes", allocated, len(sent))
+	}
+
+	// A connection closed inside a message is truncated
+	r = NewMessageReader(bytes.NewReader(message[:len(message)-1]), DefaultDecodeLimits.MaxBytes)
+	if _, _, err := r.Next(); !errors.Is(err, ErrTruncated) {
+		t.Errorf("expected ErrTruncated, got %v", err)
+	}
+}
+
+// pipeConns returns the two ends of a synchronous in-memory connection
+func pipeConns(b *testing.B) (net.Conn, net.Conn) {
+	client, server := net.Pipe()
+	return client, server
+}
+
+// tcpConns returns the two ends of a TCP connection over the loopback interface
+func tcpConns(b *testing.B) (net.Conn, net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer l.Close()
+
+	type accepted struct {
+		conn net.Conn
+		err  error
+	}
+	acceptC := make(chan accepted, 1)
+	go func() {
+		conn, err := l.Accept()
+		acceptC <- accepted{conn, err}
+	}()
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	a := <-acceptC
+	if a.err != nil {
+		b.Fatal(a.err)
+	}
+	return client, a.conn
+}
+
+// BenchmarkGiveBlocks sends GiveBlocks messages of giveBlocksCount blocks from a client to a server
+// over each transport, encoding every message on the client and decoding it on the server.
+// MB/s counts the whole envelope, and blocks/s the blocks decoded by the server.
+func BenchmarkGiveBlocks(b *testing.B) {
+	blocks := GenerateChain(giveBlocksCount, 1)
+
+	transports := []struct {
+		name  string
+		conns func(*testing.B) (net.Conn, net.Conn)
+	}{
+		{"pipe", pipeConns},
+		{"tcp", tcpConns},
+	}
+
+	for _, tr := range transports {
+		for _, c := range Codecs {
+			b.Run(tr.name+"/"+c.Name, func(b *testing.B) {
+				message, err := AppendGiveBlocks(nil, c, blocks)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				client, server := tr.conns(b)
+				defer server.Close()
+
+				b.SetBytes(int64(len(message)))
+				b.ReportAllocs()
+				b.ResetTimer()
+				start := time.Now()
+
+				errC := make(chan error, 1)
+				go func() {
+					defer client.Close()
+					buf := make([]byte, 0, len(message))
+					for i := 0; i < b.N; i++ {
+						var err error
+						if buf, err = AppendGiveBlocks(buf[:0], c, blocks); err != nil {
+							errC <- err
+							return
+						}
+						if _, err := client.Write(buf); err != nil {
+							errC <- err
+							return
+						}
+					}
+					errC <- nil
+				}()
+
+				r := NewMessageReader(bufio.NewReader(server), DefaultDecodeLimits.MaxBytes)
+				var result []coin.SignedBlock
+				for i := 0; i < b.N; i++ {
+					_, body, err := r.Next()
+					if err != nil {
+						b.Fatal(err)
+					}
+					if result, err = DecodeGiveBlocks(body, c, DefaultDecodeLimits, result[:0]); err != nil {
+						b.Fatal(err)
+					}
+
+					if validate {
+						if diffBlocks(blocks, result) != "" {
+							b.Fatalf("%s GiveBlocks result differs", c.Name)
+						}
+					}
+				}
+
+				if err := <-errC; err != nil {
+					b.Fatal(err)
+				}
+
+				b.ReportMetric(float64(b.N*len(blocks))/time.Since(start).Seconds(), "blocks/s")
+			})
+		}
+	}
+}
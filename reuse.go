@@ -0,0 +1,387 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// A reuse decoder decodes into a coin.SignedBlock which may hold a previously decoded block, reusing the
+// backing arrays of its Transactions, and of each transaction's Sigs, In and Out, when their capacity allows.
+// Every field of the block is overwritten, and slices are truncated to the decoded lengths, so that nothing
+// of the previous block remains visible. Empty slices may be non-nil, keeping their capacity.
+// Elements beyond the decoded lengths are kept for the next decode, so the value holds on to the memory
+// of the largest block decoded into it.
+
+// reuseTransactions returns txns resized to n, reusing its capacity. When it grows, the old elements
+// are copied, so that their slices can be reused too.
+func reuseTransactions(txns coin.Transactions, n int) coin.Transactions {
+	if n <= cap(txns) {
+		return txns[:n]
+	}
+	grown := make(coin.Transactions, n)
+	copy(grown, txns[:cap(txns)])
+	return grown
+}
+
+// reuseSigs returns sigs resized to n, reusing its capacity
+func reuseSigs(sigs []cipher.Sig, n int) []cipher.Sig {
+	if n <= cap(sigs) {
+		return sigs[:n]
+	}
+	return make([]cipher.Sig, n)
+}
+
+// reuseHashes returns hashes resized to n, reusing its capacity
+func reuseHashes(hashes []cipher.SHA256, n int) []cipher.SHA256 {
+	if n <= cap(hashes) {
+		return hashes[:n]
+	}
+	return make([]cipher.SHA256, n)
+}
+
+// reuseOutputs returns out resized to n, reusing its capacity
+func reuseOutputs(out []coin.TransactionOutput, n int) []coin.TransactionOutput {
+	if n <= cap(out) {
+		return out[:n]
+	}
+	return make([]coin.TransactionOutput, n)
+}
+
+// viewDecode is the reuse decoder of the block view layouts, used for skyenc, cgfixed and gencode.
+// It decodes from a BlockView rather than with the generated decoders. Checking the block as a BlockView
+// does not allocate, so decoding a block no larger than the previous one makes no allocations.
+func viewDecode(layout wireLayout, buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	b, err := newBlockView(layout, buf)
+	if err == nil {
+		err = checkViewLimits(b, buf, viewLimits(layout, limits))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	obj.Block.Head = b.Head()
+	obj.Sig = b.Sig()
+	obj.Block.Body.Transactions = reuseTransactions(obj.Block.Body.Transactions, b.NumTransactions())
+
+	it := b.Transactions()
+	for it.Next() {
+		t := it.Transaction()
+		txn := &obj.Block.Body.Transactions[it.Index()]
+		txn.Sigs = reuseSigs(txn.Sigs, t.NumSigs())
+		txn.In = reuseHashes(txn.In, t.NumIn())
+		txn.Out = reuseOutputs(txn.Out, t.NumOut())
+		t.copyTo(txn)
+	}
+
+	return len(buf), nil
+}
+
+func viewDecodeSkyencoder(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	return viewDecode(skyLayout, buf, obj, limits)
+}
+
+func viewDecodeGencode(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	return viewDecode(gencodeLayout, buf, obj, limits)
+}
+
+// reuseDecodeColfer is the reuse decoder of Colfer. The payload is checked by the colferScanner first,
+// so the decoder only has to check the sizes of the byte arrays.
+func reuseDecodeColfer(buf []byte, obj *coin.SignedBlock, limits DecodeLimits) (int, error) {
+	if err := checkColferLimits(buf, limits.capped(ColferListMax, ColferSizeMax)); err != nil {
+		return 0, err
+	}
+
+	d := colferReuseDecoder{
+		data: buf,
+	}
+	if err := d.signedBlock(obj); err != nil {
+		return 0, err
+	}
+	return d.i, nil
+}
+
+// colferReuseDecoder decodes a scanned ColferSignedBlock payload into a coin.SignedBlock.
+// Colfer omits zero fields, so each struct is zeroed before its fields are decoded, keeping only its slices.
+type colferReuseDecoder struct {
+	data []byte
+	i    int
+}
+
+// header reads the next field header, and returns the field index and whether an integer is fixed-width.
+// It returns false at the end of the struct.
+func (d *colferReuseDecoder) header() (int, bool, bool) {
+	header := d.data[d.i]
+	d.i++
+	if header == 0x7f {
+		return 0, false, false
+	}
+	return int(header & 0x7f), header&0x80 != 0, true
+}
+
+func (d *colferReuseDecoder) uvarint() uint64 {
+	var x uint64
+	for shift := uint(0); ; shift += 7 {
+		b := uint64(d.data[d.i])
+		d.i++
+		if b < 0x80 || shift == 56 {
+			return x | b<<shift
+		}
+		x |= (b & 0x7f) << shift
+	}
+}
+
+// uint reads an integer of size bytes, which is big-endian if fixed and a varint otherwise
+func (d *colferReuseDecoder) uint(size int, fixed bool) uint64 {
+	if !fixed {
+		return d.uvarint()
+	}
+	x := readUint(binary.BigEndian, d.data[d.i:d.i+size])
+	d.i += size
+	return x
+}
+
+// bytes copies a byte array field at path to dst, whose size it must have
+func (d *colferReuseDecoder) bytes(dst []byte, path func() string) error {
+	offset := d.i
+	n := int(d.uvarint())
+	if n != len(dst) {
+		return &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: offset,
+			Path:   path(),
+			Err:    fmt.Errorf("size %d is not %d", n, len(dst)),
+		}
+	}
+	copy(dst, d.data[d.i:d.i+n])
+	d.i += n
+	return nil
+}
+
+// missing returns the error for a byte array field at path which was omitted, as Colfer omits an empty one
+func (d *colferReuseDecoder) missing(path string, size int) error {
+	return &DecodeError{
+		Kind:   ErrMalformed,
+		Offset: d.i - 1,
+		Path:   path,
+		Err:    fmt.Errorf("size 0 is not %d", size),
+	}
+}
+
+// constPath returns a path function for a fixed path
+func constPath(path string) func() string {
+	return func() string {
+		return path
+	}
+}
+
+func (d *colferReuseDecoder) signedBlock(obj *coin.SignedBlock) error {
+	obj.Sig = cipher.Sig{}
+	obj.Block.Head = coin.BlockHeader{}
+	obj.Block.Body.Transactions = obj.Block.Body.Transactions[:0]
+
+	sig := false
+	for {
+		index, _, ok := d.header()
+		if !ok {
+			if !sig {
+				return d.missing("Sig", len(obj.Sig))
+			}
+			return nil
+		}
+		switch index {
+		case 0:
+			sig = true
+			if err := d.bytes(obj.Sig[:], constPath("Sig")); err != nil {
+				return err
+			}
+		case 1:
+			if err := d.block(&obj.Block); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (d *colferReuseDecoder) block(obj *coin.Block) error {
+	for {
+		index, _, ok := d.header()
+		if !ok {
+			return nil
+		}
+		switch index {
+		case 0:
+			if err := d.blockHeader(&obj.Head); err != nil {
+				return err
+			}
+		case 1:
+			if err := d.blockBody(&obj.Body); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (d *colferReuseDecoder) blockHeader(obj *coin.BlockHeader) error {
+	// seen has a bit set for each hash field decoded
+	var seen uint
+	for {
+		index, fixed, ok := d.header()
+		if !ok {
+			for i, name := range []string{"PrevHash", "BodyHash", "UxHash"} {
+				if seen&(1<<uint(4+i)) == 0 {
+					return d.missing("Block.Head."+name, len(cipher.SHA256{}))
+				}
+			}
+			return nil
+		}
+		seen |= 1 << uint(index)
+		var err error
+		switch index {
+		case 0:
+			obj.Version = uint32(d.uint(4, fixed))
+		case 1:
+			obj.Time = d.uint(8, fixed)
+		case 2:
+			obj.BkSeq = d.uint(8, fixed)
+		case 3:
+			obj.Fee = d.uint(8, fixed)
+		case 4:
+			err = d.bytes(obj.PrevHash[:], constPath("Block.Head.PrevHash"))
+		case 5:
+			err = d.bytes(obj.BodyHash[:], constPath("Block.Head.BodyHash"))
+		case 6:
+			err = d.bytes(obj.UxHash[:], constPath("Block.Head.UxHash"))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (d *colferReuseDecoder) blockBody(obj *coin.BlockBody) error {
+	for {
+		index, _, ok := d.header()
+		if !ok {
+			return nil
+		}
+		if index != 0 {
+			continue
+		}
+
+		obj.Transactions = reuseTransactions(obj.Transactions, int(d.uvarint()))
+		for i := range obj.Transactions {
+			if err := d.transaction(&obj.Transactions[i], i); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (d *colferReuseDecoder) transaction(obj *coin.Transaction, i int) error {
+	*obj = coin.Transaction{
+		Sigs: obj.Sigs[:0],
+		In:   obj.In[:0],
+		Out:  obj.Out[:0],
+	}
+
+	innerHash := false
+	for {
+		index, fixed, ok := d.header()
+		if !ok {
+			if !innerHash {
+				return d.missing(fmt.Sprintf("Block.Body.Transactions[%d].InnerHash", i), len(obj.InnerHash))
+			}
+			return nil
+		}
+		var err error
+		switch index {
+		case 0:
+			obj.Length = uint32(d.uint(4, fixed))
+		case 1:
+			obj.Type = d.data[d.i]
+			d.i++
+		case 2:
+			innerHash = true
+			err = d.bytes(obj.InnerHash[:], func() string {
+				return fmt.Sprintf("Block.Body.Transactions[%d].InnerHash", i)
+			})
+		case 3:
+			obj.Sigs = reuseSigs(obj.Sigs, int(d.uvarint()))
+			for j := range obj.Sigs {
+				if err = d.bytes(obj.Sigs[j][:], func() string {
+					return fmt.Sprintf("Block.Body.Transactions[%d].Sigs[%d]", i, j)
+				}); err != nil {
+					break
+				}
+			}
+		case 4:
+			obj.In = reuseHashes(obj.In, int(d.uvarint()))
+			for j := range obj.In {
+				if err = d.bytes(obj.In[j][:], func() string {
+					return fmt.Sprintf("Block.Body.Transactions[%d].In[%d]", i, j)
+				}); err != nil {
+					break
+				}
+			}
+		case 5:
+			obj.Out = reuseOutputs(obj.Out, int(d.uvarint()))
+			for j := range obj.Out {
+				if err = d.transactionOutput(&obj.Out[j], i, j); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (d *colferReuseDecoder) transactionOutput(obj *coin.TransactionOutput, i, j int) error {
+	*obj = coin.TransactionOutput{}
+
+	for {
+		index, fixed, ok := d.header()
+		if !ok {
+			return nil
+		}
+		switch index {
+		case 0:
+			if err := d.address(&obj.Address, i, j); err != nil {
+				return err
+			}
+		case 1:
+			obj.Coins = d.uint(8, fixed)
+		case 2:
+			obj.Hours = d.uint(8, fixed)
+		}
+	}
+}
+
+func (d *colferReuseDecoder) address(obj *cipher.Address, i, j int) error {
+	key := false
+	for {
+		index, _, ok := d.header()
+		if !ok {
+			if !key {
+				return d.missing(fmt.Sprintf("Block.Body.Transactions[%d].Out[%d].Address.Key", i, j), len(obj.Key))
+			}
+			return nil
+		}
+		switch index {
+		case 0:
+			obj.Version = d.data[d.i]
+			d.i++
+		case 1:
+			key = true
+			if err := d.bytes(obj.Key[:], func() string {
+				return fmt.Sprintf("Block.Body.Transactions[%d].Out[%d].Address.Key", i, j)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
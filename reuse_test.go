@@ -0,0 +1,215 @@
+package serializebench
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// reuseCodecs returns the codecs with a reuse decoder
+func reuseCodecs() []Codec {
+	var codecs []Codec
+	for _, c := range Codecs {
+		if c.ReuseDecode != nil {
+			codecs = append(codecs, c)
+		}
+	}
+	return codecs
+}
+
+func TestReuseDecode(t *testing.T) {
+	chain := GenerateChain(10, 1)
+
+	// Blocks shrink and grow, down to zero fields which Colfer omits
+	blocks := []coin.SignedBlock{
+		chain[0],
+		getBlock(),
+		chain[5],
+		{Block: coin.Block{Body: coin.BlockBody{Transactions: coin.Transactions{{Out: []coin.TransactionOutput{{}}}}}}},
+		{},
+		chain[3],
+		chain[0],
+	}
+
+	for _, c := range reuseCodecs() {
+		t.Run(c.Name, func(t *testing.T) {
+			var obj coin.SignedBlock
+			for i := range blocks {
+				data, err := c.Encode(&blocks[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				n, err := c.ReuseDecode(data, &obj, DefaultDecodeLimits)
+				if err != nil {
+					t.Fatalf("block %d: %v", i, err)
+				}
+				if n != len(data) {
+					t.Errorf("block %d: read %d bytes of %d", i, n, len(data))
+				}
+				// Reused slices may be empty rather than nil
+				if diff := diffBlocks(blocks[i], obj); diff != "" {
+					t.Errorf("block %d has stale fields: %s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestReuseDecodeCapacity(t *testing.T) {
+	chain := GenerateChain(10, 1)
+
+	for _, c := range reuseCodecs() {
+		t.Run(c.Name, func(t *testing.T) {
+			large, err := c.Encode(&chain[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			small, err := c.Encode(&chain[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var obj coin.SignedBlock
+			if _, err := c.ReuseDecode(large, &obj, DefaultDecodeLimits); err != nil {
+				t.Fatal(err)
+			}
+			out := &obj.Block.Body.Transactions[0].Out[0]
+			if _, err := c.ReuseDecode(small, &obj, DefaultDecodeLimits); err != nil {
+				t.Fatal(err)
+			}
+			if &obj.Block.Body.Transactions[0].Out[0] != out {
+				t.Error("the outputs of the first transaction were reallocated")
+			}
+
+			decode := func(decode func([]byte, *coin.SignedBlock, DecodeLimits) (int, error)) float64 {
+				return testing.AllocsPerRun(10, func() {
+					if _, err := decode(large, &obj, DefaultDecodeLimits); err != nil {
+						t.Fatal(err)
+					}
+				})
+			}
+			reuseAllocs := decode(c.ReuseDecode)
+			if _, ok := viewLayout(c.Name); ok && reuseAllocs != 0 {
+				t.Errorf("expected no allocations decoding into a reused block, got %v", reuseAllocs)
+			}
+			obj = coin.SignedBlock{}
+			if allocs := decode(c.Decode); reuseAllocs >= allocs {
+				t.Errorf("expected fewer allocations than Decode's %v, got %v", allocs, reuseAllocs)
+			}
+		})
+	}
+}
+
+func TestReuseDecodeColferErrors(t *testing.T) {
+	c, err := CodecByName("colfer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		change func(*ColferSignedBlock)
+		path   string
+	}{
+		{"short hash", func(b *ColferSignedBlock) {
+			b.Block.Body.Transactions[0].InnerHash = b.Block.Body.Transactions[0].InnerHash[:31]
+		}, "Block.Body.Transactions[0].InnerHash"},
+		{"omitted hash", func(b *ColferSignedBlock) {
+			b.Block.Head.UxHash = nil
+		}, "Block.Head.UxHash"},
+		{"long signature", func(b *ColferSignedBlock) {
+			b.Block.Body.Transactions[0].Sigs[1] = append(b.Block.Body.Transactions[0].Sigs[1], 0)
+		}, "Block.Body.Transactions[0].Sigs[1]"},
+		{"omitted key", func(b *ColferSignedBlock) {
+			b.Block.Body.Transactions[0].Out[1].Address.Key = nil
+		}, "Block.Body.Transactions[0].Out[1].Address.Key"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := blockToColfer(getBlock())
+			tc.change(b)
+			data, err := b.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := c.Decode(data, &coin.SignedBlock{}, DefaultDecodeLimits); err == nil {
+				t.Fatal("expected Decode to fail")
+			}
+
+			obj := getBlock()
+			_, err = c.ReuseDecode(data, &obj, DefaultDecodeLimits)
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) || !errors.Is(err, ErrMalformed) {
+				t.Fatalf("expected ErrMalformed, got %v", err)
+			}
+			if decodeErr.Path != tc.path {
+				t.Errorf("expected path %q, got %q", tc.path, decodeErr.Path)
+			}
+		})
+	}
+}
+
+// BenchmarkReuseDecode decodes a different block of a chain per op: into a new coin.SignedBlock with Decode
+// (fresh) and with ReuseDecode (fresh-reusedecoder), and into the same one with ReuseDecode (reuse).
+// ReuseDecode is a different decoder than Decode, so fresh-reusedecoder is the baseline of reuse.
+func BenchmarkReuseDecode(b *testing.B) {
+	chain := GenerateChain(storeBlocks, 1)
+
+	for _, c := range reuseCodecs() {
+		data := make([][]byte, len(chain))
+		var size int
+		for i := range chain {
+			var err error
+			if data[i], err = c.Encode(&chain[i]); err != nil {
+				b.Fatal(err)
+			}
+			size += len(data[i])
+		}
+
+		b.Run(c.Name+"/fresh", func(b *testing.B) {
+			b.SetBytes(int64(size / len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var result coin.SignedBlock
+				if _, err := c.Decode(data[i%len(data)], &result, DefaultDecodeLimits); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(c.Name+"/fresh-reusedecoder", func(b *testing.B) {
+			b.SetBytes(int64(size / len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var result coin.SignedBlock
+				if _, err := c.ReuseDecode(data[i%len(data)], &result, DefaultDecodeLimits); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(c.Name+"/reuse", func(b *testing.B) {
+			var result coin.SignedBlock
+			b.SetBytes(int64(size / len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.ReuseDecode(data[i%len(data)], &result, DefaultDecodeLimits); err != nil {
+					b.Fatal(err)
+				}
+
+				if validate {
+					if diffBlocks(chain[i%len(chain)], result) != "" {
+						b.Fatalf("%s reuse decode result differs", c.Name)
+					}
+				}
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	xdr "github.com/davecgh/go-xdr/xdr2"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/niubaoshu/gotiny"
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/encoder"
@@ -17,9 +18,34 @@ import (
 )
 
 //go:generate skyencoder -struct SignedBlock -no-test -package serializebench -output-path . github.com/skycoin/skycoin/src/coin
+//go:generate go run ./cmd/codecgen -struct SignedBlock -format fixed -package serializebench -output signed_block_fixed.go github.com/skycoin/skycoin/src/coin
+//go:generate go run ./cmd/codecgen -struct SignedBlock -format bigendian -package serializebench -output signed_block_bigendian.go github.com/skycoin/skycoin/src/coin
+//go:generate go run ./cmd/codecgen -struct SignedBlock -format xdr -package serializebench -output signed_block_xdr.go github.com/skycoin/skycoin/src/coin
+//go:generate go run ./cmd/codecgen -struct SignedBlock -format varint -package serializebench -output signed_block_varint.go github.com/skycoin/skycoin/src/coin
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source SignedBlock -target ColferSignedBlock -to blockToColfer -from colferToBlock -output transform_colfer.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source SignedBlock -target GencodeSignedBlock -to blockToGencode -from gencodeToBlock -output transform_gencode.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source SignedBlock -target GencodeVarintSignedBlock -to blockToGencodeVarint -from gencodeVarintToBlock -output transform_gencode_varint.go
+//go:generate skyencoder -struct Transaction -no-test -package serializebench -output-path . github.com/skycoin/skycoin/src/coin
+//go:generate skyencoder -struct BlockHeader -no-test -package serializebench -output-path . github.com/skycoin/skycoin/src/coin
+//go:generate skyencoder -struct UxOut -no-test -package serializebench -output-path . github.com/skycoin/skycoin/src/coin
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source Transaction -target ColferTransaction -to transactionToColfer -from colferToTransaction -output transform_colfer_transaction.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source Transaction -target GencodeTransaction -to transactionToGencode -from gencodeToTransaction -output transform_gencode_transaction.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source Transaction -target GencodeVarintTransaction -to transactionToGencodeVarint -from gencodeVarintToTransaction -output transform_gencode_varint_transaction.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source BlockHeader -target ColferBlockHeader -to headerToColfer -from colferToHeader -output transform_colfer_block_header.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source BlockHeader -target GencodeBlockHeader -to headerToGencode -from gencodeToHeader -output transform_gencode_block_header.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source BlockHeader -target GencodeVarintBlockHeader -to headerToGencodeVarint -from gencodeVarintToHeader -output transform_gencode_varint_block_header.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source UxOut -target ColferUxOut -to uxOutToColfer -from colferToUxOut -output transform_colfer_ux_out.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source UxOut -target GencodeUxOut -to uxOutToGencode -from gencodeToUxOut -output transform_gencode_ux_out.go
+//go:generate go run ./cmd/transformgen -source-pkg github.com/skycoin/skycoin/src/coin -source UxOut -target GencodeVarintUxOut -to uxOutToGencodeVarint -from gencodeVarintToUxOut -output transform_gencode_varint_ux_out.go
 
 var validate = os.Getenv("VALIDATE") != ""
 
+// diffBlocks returns the differences between two decoded blocks, or lists of blocks, or "" if they are equal.
+// Empty slices equal nil ones, as the generated transforms and some decoders leave empty lists nil.
+func diffBlocks(want, got interface{}) string {
+	return cmp.Diff(want, got, cmpopts.EquateEmpty())
+}
+
 func getBlock() coin.SignedBlock {
 	return coin.SignedBlock{
 		Sig: cipher.MustSigFromHex("8cf145e9ef4a4a5254bc57798a7a61dfed238768f94edc5635175c6b91bccd8ec1555da603c5e31b018e135b82b1525be8a92973c468a74b5b40b8da189cb465eb"),
@@ -184,6 +210,27 @@ func TestMarshaledBlockLen(t *testing.T) {
 		t.Fatal(err)
 	}
 	fmt.Printf("gencvar:\t\t\t %d bytes\n", len(gencodeVarintBytes))
+
+	codecgenFixedN := EncodeSizeSignedBlockFixed(&block)
+	fmt.Printf("cgfixed:\t\t\t %d bytes\n", codecgenFixedN)
+
+	codecgenBigEndianN := EncodeSizeSignedBlockBigEndian(&block)
+	fmt.Printf("cgbe:\t\t\t\t %d bytes\n", codecgenBigEndianN)
+
+	codecgenXDRN := EncodeSizeSignedBlockXDR(&block)
+	fmt.Printf("cgxdr:\t\t\t\t %d bytes\n", codecgenXDRN)
+
+	codecgenVarintN := EncodeSizeSignedBlockVarint(&block)
+	fmt.Printf("cgvarint:\t\t\t %d bytes\n", codecgenVarintN)
+
+	dictBytes := NewDictEncoder(false).Encode(nil, &block)
+	fmt.Printf("dict:\t\t\t\t %d bytes\n", len(dictBytes))
+
+	avroBytes, err := avroBlockSchema.Encode(nil, &block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("avro:\t\t\t\t %d bytes\n", len(avroBytes))
 }
 
 /* sky
@@ -407,124 +454,6 @@ func BenchmarkUnmarshalBlockByGotiny(b *testing.B) {
 - Does not support fixed size arrays yet (would be more optimal)
 */
 
-func copyBytes(b []byte) []byte {
-	x := make([]byte, len(b))
-	copy(x[:], b[:])
-	return x
-}
-
-func blockToColfer(block coin.SignedBlock) *ColferSignedBlock {
-	transactions := make([]*ColferTransaction, len(block.Block.Body.Transactions))
-	for i := range block.Block.Body.Transactions {
-		txn := block.Block.Body.Transactions[i]
-
-		sigs := make([][]byte, len(txn.Sigs))
-		for j, s := range txn.Sigs {
-			sigs[j] = copyBytes(s[:])
-		}
-
-		in := make([][]byte, len(txn.In))
-		for j, h := range txn.In {
-			in[j] = copyBytes(h[:])
-		}
-
-		out := make([]*ColferTransactionOutput, len(txn.Out))
-		for j, o := range txn.Out {
-			out[j] = &ColferTransactionOutput{
-				Address: &ColferAddress{
-					Version: o.Address.Version,
-					Key:     copyBytes(o.Address.Key[:]),
-				},
-				Coins: o.Coins,
-				Hours: o.Hours,
-			}
-		}
-
-		transactions[i] = &ColferTransaction{
-			Length:    txn.Length,
-			Type:      txn.Type,
-			InnerHash: copyBytes(txn.InnerHash[:]),
-			Sigs:      sigs,
-			In:        in,
-			Out:       out,
-		}
-	}
-
-	return &ColferSignedBlock{
-		Sig: block.Sig[:],
-		Block: &ColferBlock{
-			Head: &ColferBlockHeader{
-				Version:  block.Block.Head.Version,
-				Time:     block.Block.Head.Time,
-				BkSeq:    block.Block.Head.BkSeq,
-				Fee:      block.Block.Head.Fee,
-				PrevHash: copyBytes(block.Block.Head.PrevHash[:]),
-				BodyHash: copyBytes(block.Block.Head.BodyHash[:]),
-				UxHash:   copyBytes(block.Block.Head.UxHash[:]),
-			},
-			Body: &ColferBlockBody{
-				Transactions: transactions,
-			},
-		},
-	}
-}
-
-func colferToBlock(b *ColferSignedBlock) coin.SignedBlock {
-	transactions := make([]coin.Transaction, len(b.Block.Body.Transactions))
-	for i := range b.Block.Body.Transactions {
-		txn := b.Block.Body.Transactions[i]
-
-		sigs := make([]cipher.Sig, len(txn.Sigs))
-		for j, s := range txn.Sigs {
-			sigs[j] = cipher.MustNewSig(s)
-		}
-
-		in := make([]cipher.SHA256, len(txn.In))
-		for j, h := range txn.In {
-			in[j] = cipher.MustSHA256FromBytes(h)
-		}
-
-		out := make([]coin.TransactionOutput, len(txn.Out))
-		for j, o := range txn.Out {
-			out[j] = coin.TransactionOutput{
-				Address: cipher.Address{
-					Version: byte(o.Address.Version),
-					Key:     cipher.MustRipemd160FromBytes(o.Address.Key),
-				},
-				Coins: o.Coins,
-				Hours: o.Hours,
-			}
-		}
-
-		transactions[i] = coin.Transaction{
-			Length:    txn.Length,
-			Type:      txn.Type,
-			InnerHash: cipher.MustSHA256FromBytes(txn.InnerHash),
-			Sigs:      sigs,
-			In:        in,
-			Out:       out,
-		}
-	}
-
-	return coin.SignedBlock{
-		Sig: cipher.MustNewSig(b.Sig),
-		Block: coin.Block{
-			Head: coin.BlockHeader{
-				Version:  b.Block.Head.Version,
-				Time:     b.Block.Head.Time,
-				BkSeq:    b.Block.Head.BkSeq,
-				Fee:      b.Block.Head.Fee,
-				PrevHash: cipher.MustSHA256FromBytes(b.Block.Head.PrevHash),
-				BodyHash: cipher.MustSHA256FromBytes(b.Block.Head.BodyHash),
-				UxHash:   cipher.MustSHA256FromBytes(b.Block.Head.UxHash),
-			},
-			Body: coin.BlockBody{
-				Transactions: transactions,
-			},
-		},
-	}
-}
-
 func BenchmarkMarshalBlockByColfer(b *testing.B) {
 	block := getBlock()
 	b.ResetTimer()
@@ -555,7 +484,10 @@ func BenchmarkUnmarshalBlockByColfer(b *testing.B) {
 			b.Fatal(err)
 		}
 
-		result := colferToBlock(&colferResult)
+		result, err := colferToBlock(&colferResult)
+		if err != nil {
+			b.Fatal(err)
+		}
 
 		if validate {
 			if !cmp.Equal(result, block) {
@@ -596,7 +528,10 @@ func BenchmarkUnmarshalBlockByColferNoTransform(b *testing.B) {
 		}
 
 		if validate {
-			result := colferToBlock(&colferResult)
+			result, err := colferToBlock(&colferResult)
+			if err != nil {
+				b.Fatal(err)
+			}
 			if !cmp.Equal(result, block) {
 				b.Fatal("colfer unmarshal result differs")
 			}
@@ -612,152 +547,111 @@ func BenchmarkUnmarshalBlockByColferNoTransform(b *testing.B) {
 - the code is also hard to read
 */
 
-func copySig(s cipher.Sig) [65]byte {
-	var ss [65]byte
-	copy(ss[:], s[:])
-	return ss
-}
+func BenchmarkMarshalBlockByGencode(b *testing.B) {
+	block := getBlock()
+	b.ResetTimer()
+	b.ReportAllocs()
 
-func copySHA256(h cipher.SHA256) [32]byte {
-	var hh [32]byte
-	copy(hh[:], h[:])
-	return hh
+	for i := 0; i < b.N; i++ {
+		gencodeBlock := blockToGencode(block)
+		if _, err := gencodeBlock.Marshal(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
 
-func copyRipemd160(h cipher.Ripemd160) [20]byte {
-	var hh [20]byte
-	copy(hh[:], h[:])
-	return hh
-}
+func BenchmarkUnmarshalBlockByGencode(b *testing.B) {
+	block := getBlock()
+	gencodeBlock := blockToGencode(block)
+	raw, err := gencodeBlock.Marshal(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	block = getBlock()
 
-func blockToGencode(block coin.SignedBlock) *GencodeSignedBlock {
-	transactions := make([]GencodeTransaction, len(block.Block.Body.Transactions))
-	for i := range block.Block.Body.Transactions {
-		txn := block.Block.Body.Transactions[i]
+	b.ResetTimer()
+	b.ReportAllocs()
 
-		sigs := make([][65]byte, len(txn.Sigs))
-		for j, s := range txn.Sigs {
-			sigs[j] = copySig(s)
+	for i := 0; i < b.N; i++ {
+		var gencodeResult GencodeSignedBlock
+		if _, err := gencodeResult.Unmarshal(raw); err != nil {
+			b.Fatal(err)
 		}
 
-		in := make([][32]byte, len(txn.In))
-		for j, h := range txn.In {
-			in[j] = copySHA256(h)
-		}
+		result := gencodeToBlock(&gencodeResult)
 
-		out := make([]GencodeTransactionOutput, len(txn.Out))
-		for j, o := range txn.Out {
-			out[j] = GencodeTransactionOutput{
-				Address: GencodeAddress{
-					Version: o.Address.Version,
-					Key:     copyRipemd160(o.Address.Key),
-				},
-				Coins: o.Coins,
-				Hours: o.Hours,
+		if validate {
+			if !cmp.Equal(result, block) {
+				b.Fatal("gencode unmarshal result differs")
 			}
 		}
-
-		transactions[i] = GencodeTransaction{
-			Length:    txn.Length,
-			Type:      txn.Type,
-			InnerHash: copySHA256(txn.InnerHash),
-			Sigs:      sigs,
-			In:        in,
-			Out:       out,
-		}
 	}
+}
 
-	return &GencodeSignedBlock{
-		Sig: copySig(block.Sig),
-		Block: GencodeBlock{
-			Head: GencodeBlockHeader{
-				Version:  block.Block.Head.Version,
-				Time:     block.Block.Head.Time,
-				BkSeq:    block.Block.Head.BkSeq,
-				Fee:      block.Block.Head.Fee,
-				PrevHash: copySHA256(block.Block.Head.PrevHash),
-				BodyHash: copySHA256(block.Block.Head.BodyHash),
-				UxHash:   copySHA256(block.Block.Head.UxHash),
-			},
-			Body: GencodeBlockBody{
-				Transactions: transactions,
-			},
-		},
+func BenchmarkMarshalBlockByGencodeNoTransform(b *testing.B) {
+	block := getBlock()
+	gencodeBlock := blockToGencode(block)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := gencodeBlock.Marshal(nil); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
 
-func gencodeToBlock(b *GencodeSignedBlock) coin.SignedBlock {
-	transactions := make([]coin.Transaction, len(b.Block.Body.Transactions))
-	for i := range b.Block.Body.Transactions {
-		txn := b.Block.Body.Transactions[i]
+func BenchmarkUnmarshalBlockByGencodeNoTransform(b *testing.B) {
+	block := getBlock()
+	gencodeBlock := blockToGencode(block)
+	raw, err := gencodeBlock.Marshal(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	block = getBlock()
 
-		sigs := make([]cipher.Sig, len(txn.Sigs))
-		for j, s := range txn.Sigs {
-			sigs[j] = cipher.MustNewSig(s[:])
-		}
+	b.ResetTimer()
+	b.ReportAllocs()
 
-		in := make([]cipher.SHA256, len(txn.In))
-		for j, h := range txn.In {
-			in[j] = cipher.MustSHA256FromBytes(h[:])
+	for i := 0; i < b.N; i++ {
+		var gencodeResult GencodeSignedBlock
+		if _, err := gencodeResult.Unmarshal(raw); err != nil {
+			b.Fatal(err)
 		}
 
-		out := make([]coin.TransactionOutput, len(txn.Out))
-		for j, o := range txn.Out {
-			out[j] = coin.TransactionOutput{
-				Address: cipher.Address{
-					Version: byte(o.Address.Version),
-					Key:     cipher.MustRipemd160FromBytes(o.Address.Key[:]),
-				},
-				Coins: o.Coins,
-				Hours: o.Hours,
+		if validate {
+			result := gencodeToBlock(&gencodeResult)
+			if !cmp.Equal(result, block) {
+				b.Fatal("gencode unmarshal result differs")
 			}
 		}
-
-		transactions[i] = coin.Transaction{
-			Length:    txn.Length,
-			Type:      txn.Type,
-			InnerHash: cipher.MustSHA256FromBytes(txn.InnerHash[:]),
-			Sigs:      sigs,
-			In:        in,
-			Out:       out,
-		}
-	}
-
-	return coin.SignedBlock{
-		Sig: cipher.MustNewSig(b.Sig[:]),
-		Block: coin.Block{
-			Head: coin.BlockHeader{
-				Version:  b.Block.Head.Version,
-				Time:     b.Block.Head.Time,
-				BkSeq:    b.Block.Head.BkSeq,
-				Fee:      b.Block.Head.Fee,
-				PrevHash: cipher.MustSHA256FromBytes(b.Block.Head.PrevHash[:]),
-				BodyHash: cipher.MustSHA256FromBytes(b.Block.Head.BodyHash[:]),
-				UxHash:   cipher.MustSHA256FromBytes(b.Block.Head.UxHash[:]),
-			},
-			Body: coin.BlockBody{
-				Transactions: transactions,
-			},
-		},
 	}
 }
 
-func BenchmarkMarshalBlockByGencode(b *testing.B) {
+/* gencode with varints
+
+- gencode schema using varints for some values (should be smaller size, but slower)
+
+- ~ 6% smaller
+- ~ 6% slower (unmarshal)
+*/
+
+func BenchmarkMarshalBlockByGencodeVarint(b *testing.B) {
 	block := getBlock()
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		gencodeBlock := blockToGencode(block)
+		gencodeBlock := blockToGencodeVarint(block)
 		if _, err := gencodeBlock.Marshal(nil); err != nil {
 			b.Fatal(err)
 		}
 	}
 }
 
-func BenchmarkUnmarshalBlockByGencode(b *testing.B) {
+func BenchmarkUnmarshalBlockByGencodeVarint(b *testing.B) {
 	block := getBlock()
-	gencodeBlock := blockToGencode(block)
+	gencodeBlock := blockToGencodeVarint(block)
 	raw, err := gencodeBlock.Marshal(nil)
 	if err != nil {
 		b.Fatal(err)
@@ -768,12 +662,12 @@ func BenchmarkUnmarshalBlockByGencode(b *testing.B) {
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		var gencodeResult GencodeSignedBlock
+		var gencodeResult GencodeVarintSignedBlock
 		if _, err := gencodeResult.Unmarshal(raw); err != nil {
 			b.Fatal(err)
 		}
 
-		result := gencodeToBlock(&gencodeResult)
+		result := gencodeVarintToBlock(&gencodeResult)
 
 		if validate {
 			if !cmp.Equal(result, block) {
@@ -783,9 +677,9 @@ func BenchmarkUnmarshalBlockByGencode(b *testing.B) {
 	}
 }
 
-func BenchmarkMarshalBlockByGencodeNoTransform(b *testing.B) {
+func BenchmarkMarshalBlockByGencodeVarintNoTransform(b *testing.B) {
 	block := getBlock()
-	gencodeBlock := blockToGencode(block)
+	gencodeBlock := blockToGencodeVarint(block)
 	b.ResetTimer()
 	b.ReportAllocs()
 
@@ -796,9 +690,9 @@ func BenchmarkMarshalBlockByGencodeNoTransform(b *testing.B) {
 	}
 }
 
-func BenchmarkUnmarshalBlockByGencodeNoTransform(b *testing.B) {
+func BenchmarkUnmarshalBlockByGencodeVarintNoTransform(b *testing.B) {
 	block := getBlock()
-	gencodeBlock := blockToGencode(block)
+	gencodeBlock := blockToGencodeVarint(block)
 	raw, err := gencodeBlock.Marshal(nil)
 	if err != nil {
 		b.Fatal(err)
@@ -809,13 +703,13 @@ func BenchmarkUnmarshalBlockByGencodeNoTransform(b *testing.B) {
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		var gencodeResult GencodeSignedBlock
+		var gencodeResult GencodeVarintSignedBlock
 		if _, err := gencodeResult.Unmarshal(raw); err != nil {
 			b.Fatal(err)
 		}
 
 		if validate {
-			result := gencodeToBlock(&gencodeResult)
+			result := gencodeVarintToBlock(&gencodeResult)
 			if !cmp.Equal(result, block) {
 				b.Fatal("gencode unmarshal result differs")
 			}
@@ -823,202 +717,328 @@ func BenchmarkUnmarshalBlockByGencodeNoTransform(b *testing.B) {
 	}
 }
 
-/* gencode with varints
+/* codecgen
 
-- gencode schema using varints for some values (should be smaller size, but slower)
-
-- ~ 6% smaller
-- ~ 6% slower (unmarshal)
+- Generated from the coin.SignedBlock definition with go/types, so no transform step is needed
+- The fixed format is the Skycoin encoder format
+- The varint format is the gencode-varint.schema format, except that fields are written in struct order,
+  so the signature is at the end instead of the beginning
+- The bigendian format is the Skycoin encoder format with big-endian integers
+- The xdr format is the XDR2 encoding without the padding after byte arrays
 */
 
-func blockToGencodeVarint(block coin.SignedBlock) *GencodeVarintSignedBlock {
-	transactions := make([]GencodeVarintTransaction, len(block.Block.Body.Transactions))
-	for i := range block.Block.Body.Transactions {
-		txn := block.Block.Body.Transactions[i]
+func TestCodecgenRoundTrip(t *testing.T) {
+	block := getBlock()
 
-		sigs := make([][65]byte, len(txn.Sigs))
-		for j, s := range txn.Sigs {
-			sigs[j] = copySig(s)
-		}
+	skyBytes := encoder.Serialize(block)
 
-		in := make([][32]byte, len(txn.In))
-		for j, h := range txn.In {
-			in[j] = copySHA256(h)
-		}
+	gencodeVarintBytes, err := blockToGencodeVarint(block).Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Move the gencode signature from the beginning to the end to match the coin.SignedBlock field order
+	gencodeVarintBytes = append(gencodeVarintBytes[65:], gencodeVarintBytes[:65]...)
 
-		out := make([]GencodeVarintTransactionOutput, len(txn.Out))
-		for j, o := range txn.Out {
-			out[j] = GencodeVarintTransactionOutput{
-				Address: GencodeVarintAddress{
-					Version: o.Address.Version,
-					Key:     copyRipemd160(o.Address.Key),
-				},
-				Coins: o.Coins,
-				Hours: o.Hours,
+	// Swap the byte order of the integers and lengths of the Skycoin encoding
+	skyFields, err := Dissect("skyenc", skyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bigEndianBytes := append([]byte(nil), skyBytes...)
+	for _, f := range skyFields {
+		if f.Kind == "int" || f.Kind == "length" {
+			b := bigEndianBytes[f.Offset : f.Offset+f.Size]
+			for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+				b[i], b[j] = b[j], b[i]
 			}
 		}
+	}
 
-		transactions[i] = GencodeVarintTransaction{
-			Length:    txn.Length,
-			Type:      txn.Type,
-			InnerHash: copySHA256(txn.InnerHash),
-			Sigs:      sigs,
-			In:        in,
-			Out:       out,
+	// Drop the padding of the XDR2 encoding
+	var xdrBuf bytes.Buffer
+	if _, err := xdr.Marshal(&xdrBuf, block); err != nil {
+		t.Fatal(err)
+	}
+	xdrFields, err := Dissect("xdr2", xdrBuf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var xdrBytes []byte
+	for _, f := range xdrFields {
+		if f.Kind != "padding" {
+			xdrBytes = append(xdrBytes, xdrBuf.Bytes()[f.Offset:f.Offset+f.Size]...)
 		}
 	}
 
-	return &GencodeVarintSignedBlock{
-		Sig: copySig(block.Sig),
-		Block: GencodeVarintBlock{
-			Head: GencodeVarintBlockHeader{
-				Version:  block.Block.Head.Version,
-				Time:     block.Block.Head.Time,
-				BkSeq:    block.Block.Head.BkSeq,
-				Fee:      block.Block.Head.Fee,
-				PrevHash: copySHA256(block.Block.Head.PrevHash),
-				BodyHash: copySHA256(block.Block.Head.BodyHash),
-				UxHash:   copySHA256(block.Block.Head.UxHash),
-			},
-			Body: GencodeVarintBlockBody{
-				Transactions: transactions,
-			},
+	cases := []struct {
+		name     string
+		size     func(*coin.SignedBlock) int
+		encode   func([]byte, *coin.SignedBlock) error
+		decode   func([]byte, *coin.SignedBlock) (int, error)
+		expected []byte
+	}{
+		{
+			name:     "fixed",
+			size:     EncodeSizeSignedBlockFixed,
+			encode:   EncodeSignedBlockFixed,
+			decode:   DecodeSignedBlockFixed,
+			expected: skyBytes,
+		},
+		{
+			name:     "bigendian",
+			size:     EncodeSizeSignedBlockBigEndian,
+			encode:   EncodeSignedBlockBigEndian,
+			decode:   DecodeSignedBlockBigEndian,
+			expected: bigEndianBytes,
 		},
+		{
+			name:     "xdr",
+			size:     EncodeSizeSignedBlockXDR,
+			encode:   EncodeSignedBlockXDR,
+			decode:   DecodeSignedBlockXDR,
+			expected: xdrBytes,
+		},
+		{
+			name:     "varint",
+			size:     EncodeSizeSignedBlockVarint,
+			encode:   EncodeSignedBlockVarint,
+			decode:   DecodeSignedBlockVarint,
+			expected: gencodeVarintBytes,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := make([]byte, tc.size(&block))
+			if err := tc.encode(buf, &block); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(buf, tc.expected) {
+				t.Fatal("encoded bytes differ from the reference encoding")
+			}
+
+			if err := tc.encode(buf[:len(buf)-1], &block); err != encoder.ErrBufferOverflow {
+				t.Fatalf("expected encoder.ErrBufferOverflow for a short buffer, got %v", err)
+			}
+
+			var result coin.SignedBlock
+			n, err := tc.decode(buf, &result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(buf) {
+				t.Fatalf("decoded %d bytes, expected %d", n, len(buf))
+			}
+			if !cmp.Equal(result, block) {
+				t.Fatal("decoded block differs")
+			}
+
+			for i := 0; i < len(buf); i++ {
+				var result coin.SignedBlock
+				if _, err := tc.decode(buf[:i], &result); err != encoder.ErrBufferUnderflow {
+					t.Fatalf("expected encoder.ErrBufferUnderflow decoding %d bytes, got %v", i, err)
+				}
+			}
+		})
 	}
 }
 
-func gencodeVarintToBlock(b *GencodeVarintSignedBlock) coin.SignedBlock {
-	transactions := make([]coin.Transaction, len(b.Block.Body.Transactions))
-	for i := range b.Block.Body.Transactions {
-		txn := b.Block.Body.Transactions[i]
+func BenchmarkMarshalBlockByCodecgenFixed(b *testing.B) {
+	block := getBlock()
+	buf := make([]byte, EncodeSizeSignedBlockFixed(&block))
 
-		sigs := make([]cipher.Sig, len(txn.Sigs))
-		for j, s := range txn.Sigs {
-			sigs[j] = cipher.MustNewSig(s[:])
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := EncodeSignedBlockFixed(buf, &block); err != nil {
+			b.Fatal(err)
 		}
+	}
+}
+
+func BenchmarkUnmarshalBlockByCodecgenFixed(b *testing.B) {
+	block := getBlock()
+	raw := make([]byte, EncodeSizeSignedBlockFixed(&block))
+	if err := EncodeSignedBlockFixed(raw, &block); err != nil {
+		b.Fatal(err)
+	}
 
-		in := make([]cipher.SHA256, len(txn.In))
-		for j, h := range txn.In {
-			in[j] = cipher.MustSHA256FromBytes(h[:])
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var result coin.SignedBlock
+		if x, err := DecodeSignedBlockFixed(raw, &result); err != nil {
+			b.Fatal(err)
+		} else if x != len(raw) {
+			b.Fatal("codecgen: DecodeSignedBlockFixed bytes remain")
 		}
 
-		out := make([]coin.TransactionOutput, len(txn.Out))
-		for j, o := range txn.Out {
-			out[j] = coin.TransactionOutput{
-				Address: cipher.Address{
-					Version: byte(o.Address.Version),
-					Key:     cipher.MustRipemd160FromBytes(o.Address.Key[:]),
-				},
-				Coins: o.Coins,
-				Hours: o.Hours,
+		if validate {
+			if !cmp.Equal(result, block) {
+				b.Fatal("codecgen fixed unmarshal result differs")
 			}
 		}
+	}
+}
+
+func BenchmarkMarshalBlockByCodecgenBigEndian(b *testing.B) {
+	block := getBlock()
+	buf := make([]byte, EncodeSizeSignedBlockBigEndian(&block))
 
-		transactions[i] = coin.Transaction{
-			Length:    txn.Length,
-			Type:      txn.Type,
-			InnerHash: cipher.MustSHA256FromBytes(txn.InnerHash[:]),
-			Sigs:      sigs,
-			In:        in,
-			Out:       out,
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := EncodeSignedBlockBigEndian(buf, &block); err != nil {
+			b.Fatal(err)
 		}
 	}
+}
 
-	return coin.SignedBlock{
-		Sig: cipher.MustNewSig(b.Sig[:]),
-		Block: coin.Block{
-			Head: coin.BlockHeader{
-				Version:  b.Block.Head.Version,
-				Time:     b.Block.Head.Time,
-				BkSeq:    b.Block.Head.BkSeq,
-				Fee:      b.Block.Head.Fee,
-				PrevHash: cipher.MustSHA256FromBytes(b.Block.Head.PrevHash[:]),
-				BodyHash: cipher.MustSHA256FromBytes(b.Block.Head.BodyHash[:]),
-				UxHash:   cipher.MustSHA256FromBytes(b.Block.Head.UxHash[:]),
-			},
-			Body: coin.BlockBody{
-				Transactions: transactions,
-			},
-		},
+func BenchmarkUnmarshalBlockByCodecgenBigEndian(b *testing.B) {
+	block := getBlock()
+	raw := make([]byte, EncodeSizeSignedBlockBigEndian(&block))
+	if err := EncodeSignedBlockBigEndian(raw, &block); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var result coin.SignedBlock
+		if x, err := DecodeSignedBlockBigEndian(raw, &result); err != nil {
+			b.Fatal(err)
+		} else if x != len(raw) {
+			b.Fatal("codecgen: DecodeSignedBlockBigEndian bytes remain")
+		}
+
+		if validate {
+			if !cmp.Equal(result, block) {
+				b.Fatal("codecgen bigendian unmarshal result differs")
+			}
+		}
 	}
 }
 
-func BenchmarkMarshalBlockByGencodeVarint(b *testing.B) {
+func BenchmarkMarshalBlockByCodecgenXDR(b *testing.B) {
 	block := getBlock()
+	buf := make([]byte, EncodeSizeSignedBlockXDR(&block))
+
 	b.ResetTimer()
 	b.ReportAllocs()
-
 	for i := 0; i < b.N; i++ {
-		gencodeBlock := blockToGencodeVarint(block)
-		if _, err := gencodeBlock.Marshal(nil); err != nil {
+		if err := EncodeSignedBlockXDR(buf, &block); err != nil {
 			b.Fatal(err)
 		}
 	}
 }
 
-func BenchmarkUnmarshalBlockByGencodeVarint(b *testing.B) {
+func BenchmarkUnmarshalBlockByCodecgenXDR(b *testing.B) {
 	block := getBlock()
-	gencodeBlock := blockToGencodeVarint(block)
-	raw, err := gencodeBlock.Marshal(nil)
-	if err != nil {
+	raw := make([]byte, EncodeSizeSignedBlockXDR(&block))
+	if err := EncodeSignedBlockXDR(raw, &block); err != nil {
 		b.Fatal(err)
 	}
-	block = getBlock()
 
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		var gencodeResult GencodeVarintSignedBlock
-		if _, err := gencodeResult.Unmarshal(raw); err != nil {
+		var result coin.SignedBlock
+		if x, err := DecodeSignedBlockXDR(raw, &result); err != nil {
 			b.Fatal(err)
+		} else if x != len(raw) {
+			b.Fatal("codecgen: DecodeSignedBlockXDR bytes remain")
 		}
 
-		result := gencodeVarintToBlock(&gencodeResult)
-
 		if validate {
 			if !cmp.Equal(result, block) {
-				b.Fatal("gencode unmarshal result differs")
+				b.Fatal("codecgen xdr unmarshal result differs")
 			}
 		}
 	}
 }
 
-func BenchmarkMarshalBlockByGencodeVarintNoTransform(b *testing.B) {
+func BenchmarkMarshalBlockByCodecgenVarint(b *testing.B) {
 	block := getBlock()
-	gencodeBlock := blockToGencodeVarint(block)
+	buf := make([]byte, EncodeSizeSignedBlockVarint(&block))
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := EncodeSignedBlockVarint(buf, &block); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalBlockByCodecgenVarint(b *testing.B) {
+	block := getBlock()
+	raw := make([]byte, EncodeSizeSignedBlockVarint(&block))
+	if err := EncodeSignedBlockVarint(raw, &block); err != nil {
+		b.Fatal(err)
+	}
+
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		if _, err := gencodeBlock.Marshal(nil); err != nil {
+		var result coin.SignedBlock
+		if x, err := DecodeSignedBlockVarint(raw, &result); err != nil {
 			b.Fatal(err)
+		} else if x != len(raw) {
+			b.Fatal("codecgen: DecodeSignedBlockVarint bytes remain")
+		}
+
+		if validate {
+			if !cmp.Equal(result, block) {
+				b.Fatal("codecgen varint unmarshal result differs")
+			}
 		}
 	}
 }
 
-func BenchmarkUnmarshalBlockByGencodeVarintNoTransform(b *testing.B) {
+/* Avro
+
+- Schema in block.avsc, resolved against the reader's schema when decoding
+- Encoded by reflection, since Avro is usually used with a generic data model
+- Varints, including array block counts; arrays end with a zero count
+*/
+
+func BenchmarkMarshalBlockByAvro(b *testing.B) {
 	block := getBlock()
-	gencodeBlock := blockToGencodeVarint(block)
-	raw, err := gencodeBlock.Marshal(nil)
+	buf := make([]byte, 0, 2048)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := avroBlockSchema.Encode(buf[:0], &block); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalBlockByAvro(b *testing.B) {
+	block := getBlock()
+	raw, err := avroBlockSchema.Encode(nil, &block)
 	if err != nil {
 		b.Fatal(err)
 	}
-	block = getBlock()
 
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		var gencodeResult GencodeVarintSignedBlock
-		if _, err := gencodeResult.Unmarshal(raw); err != nil {
+		var result coin.SignedBlock
+		if _, err := avroBlockResolver.Decode(raw, &result, DefaultDecodeLimits); err != nil {
 			b.Fatal(err)
 		}
 
 		if validate {
-			result := gencodeVarintToBlock(&gencodeResult)
 			if !cmp.Equal(result, block) {
-				b.Fatal("gencode unmarshal result differs")
+				b.Fatal("avro unmarshal result differs")
 			}
 		}
 	}
@@ -0,0 +1,937 @@
+// Code generated by github.com/gz-c/skycoin-serialization-benchmarks/cmd/codecgen. DO NOT EDIT.
+package serializebench
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// EncodeSizeSignedBlockVarint computes the size of an encoded object of type SignedBlock
+// in the gencode-varint.schema format (varint integers, varint length prefixes)
+func EncodeSizeSignedBlockVarint(obj *coin.SignedBlock) int {
+	i0 := 0
+
+	// obj.Block.Head.Version
+	{
+		t := uint64(obj.Block.Head.Version)
+		for t >= 0x80 {
+			t >>= 7
+			i0++
+		}
+		i0++
+	}
+
+	// obj.Block.Head.Time
+	{
+		t := uint64(obj.Block.Head.Time)
+		for t >= 0x80 {
+			t >>= 7
+			i0++
+		}
+		i0++
+	}
+
+	// obj.Block.Head.BkSeq
+	{
+		t := uint64(obj.Block.Head.BkSeq)
+		for t >= 0x80 {
+			t >>= 7
+			i0++
+		}
+		i0++
+	}
+
+	// obj.Block.Head.Fee
+	{
+		t := uint64(obj.Block.Head.Fee)
+		for t >= 0x80 {
+			t >>= 7
+			i0++
+		}
+		i0++
+	}
+
+	// obj.Block.Head.PrevHash
+	i0 += 32
+
+	// obj.Block.Head.BodyHash
+	i0 += 32
+
+	// obj.Block.Head.UxHash
+	i0 += 32
+
+	// obj.Block.Body.Transactions
+	{
+		t := uint64(len(obj.Block.Body.Transactions))
+		for t >= 0x80 {
+			t >>= 7
+			i0++
+		}
+		i0++
+	}
+	for _, x1 := range obj.Block.Body.Transactions {
+		// x1.Length
+		{
+			t := uint64(x1.Length)
+			for t >= 0x80 {
+				t >>= 7
+				i0++
+			}
+			i0++
+		}
+
+		// x1.Type
+		{
+			t := uint64(x1.Type)
+			for t >= 0x80 {
+				t >>= 7
+				i0++
+			}
+			i0++
+		}
+
+		// x1.InnerHash
+		i0 += 32
+
+		// x1.Sigs
+		{
+			t := uint64(len(x1.Sigs))
+			for t >= 0x80 {
+				t >>= 7
+				i0++
+			}
+			i0++
+		}
+		i0 += len(x1.Sigs) * 65
+
+		// x1.In
+		{
+			t := uint64(len(x1.In))
+			for t >= 0x80 {
+				t >>= 7
+				i0++
+			}
+			i0++
+		}
+		i0 += len(x1.In) * 32
+
+		// x1.Out
+		{
+			t := uint64(len(x1.Out))
+			for t >= 0x80 {
+				t >>= 7
+				i0++
+			}
+			i0++
+		}
+		for _, x2 := range x1.Out {
+			// x2.Address.Version
+			{
+				t := uint64(x2.Address.Version)
+				for t >= 0x80 {
+					t >>= 7
+					i0++
+				}
+				i0++
+			}
+
+			// x2.Address.Key
+			i0 += 20
+
+			// x2.Coins
+			{
+				t := uint64(x2.Coins)
+				for t >= 0x80 {
+					t >>= 7
+					i0++
+				}
+				i0++
+			}
+
+			// x2.Hours
+			{
+				t := uint64(x2.Hours)
+				for t >= 0x80 {
+					t >>= 7
+					i0++
+				}
+				i0++
+			}
+
+		}
+
+	}
+
+	// obj.Sig
+	i0 += 65
+
+	return i0
+}
+
+// EncodeSignedBlockVarint encodes an object of type SignedBlock to the buffer
+// in the gencode-varint.schema format (varint integers, varint length prefixes).
+// The buffer must be at least EncodeSizeSignedBlockVarint(obj) bytes long, otherwise an error is returned.
+func EncodeSignedBlockVarint(buf []byte, obj *coin.SignedBlock) error {
+	if len(buf) < EncodeSizeSignedBlockVarint(obj) {
+		return encoder.ErrBufferOverflow
+	}
+
+	i := 0
+
+	// obj.Block.Head.Version
+	i += binary.PutUvarint(buf[i:], uint64(obj.Block.Head.Version))
+
+	// obj.Block.Head.Time
+	i += binary.PutUvarint(buf[i:], uint64(obj.Block.Head.Time))
+
+	// obj.Block.Head.BkSeq
+	i += binary.PutUvarint(buf[i:], uint64(obj.Block.Head.BkSeq))
+
+	// obj.Block.Head.Fee
+	i += binary.PutUvarint(buf[i:], uint64(obj.Block.Head.Fee))
+
+	// obj.Block.Head.PrevHash
+	i += copy(buf[i:], obj.Block.Head.PrevHash[:])
+
+	// obj.Block.Head.BodyHash
+	i += copy(buf[i:], obj.Block.Head.BodyHash[:])
+
+	// obj.Block.Head.UxHash
+	i += copy(buf[i:], obj.Block.Head.UxHash[:])
+
+	// obj.Block.Body.Transactions maxlen check
+	if len(obj.Block.Body.Transactions) > 65535 {
+		return encoder.ErrMaxLenExceeded
+	}
+
+	// obj.Block.Body.Transactions length
+	i += binary.PutUvarint(buf[i:], uint64(len(obj.Block.Body.Transactions)))
+
+	// obj.Block.Body.Transactions
+	for _, x1 := range obj.Block.Body.Transactions {
+		// x1.Length
+		i += binary.PutUvarint(buf[i:], uint64(x1.Length))
+
+		// x1.Type
+		i += binary.PutUvarint(buf[i:], uint64(x1.Type))
+
+		// x1.InnerHash
+		i += copy(buf[i:], x1.InnerHash[:])
+
+		// x1.Sigs maxlen check
+		if len(x1.Sigs) > 65535 {
+			return encoder.ErrMaxLenExceeded
+		}
+
+		// x1.Sigs length
+		i += binary.PutUvarint(buf[i:], uint64(len(x1.Sigs)))
+
+		// x1.Sigs
+		for _, x2 := range x1.Sigs {
+			// x2
+			i += copy(buf[i:], x2[:])
+
+		}
+
+		// x1.In maxlen check
+		if len(x1.In) > 65535 {
+			return encoder.ErrMaxLenExceeded
+		}
+
+		// x1.In length
+		i += binary.PutUvarint(buf[i:], uint64(len(x1.In)))
+
+		// x1.In
+		for _, x2 := range x1.In {
+			// x2
+			i += copy(buf[i:], x2[:])
+
+		}
+
+		// x1.Out maxlen check
+		if len(x1.Out) > 65535 {
+			return encoder.ErrMaxLenExceeded
+		}
+
+		// x1.Out length
+		i += binary.PutUvarint(buf[i:], uint64(len(x1.Out)))
+
+		// x1.Out
+		for _, x2 := range x1.Out {
+			// x2.Address.Version
+			i += binary.PutUvarint(buf[i:], uint64(x2.Address.Version))
+
+			// x2.Address.Key
+			i += copy(buf[i:], x2.Address.Key[:])
+
+			// x2.Coins
+			i += binary.PutUvarint(buf[i:], uint64(x2.Coins))
+
+			// x2.Hours
+			i += binary.PutUvarint(buf[i:], uint64(x2.Hours))
+
+		}
+
+	}
+
+	// obj.Sig
+	i += copy(buf[i:], obj.Sig[:])
+
+	return nil
+}
+
+// DecodeSignedBlockVarint decodes an object of type SignedBlock from the buffer
+// in the gencode-varint.schema format (varint integers, varint length prefixes).
+// Returns the number of bytes used from the buffer to decode the object.
+func DecodeSignedBlockVarint(buf []byte, obj *coin.SignedBlock) (int, error) {
+	i := 0
+
+	{
+		// obj.Block.Head.Version
+		x, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 || x > math.MaxUint32 {
+			return i, errors.New("obj.Block.Head.Version: varint overflows uint32")
+		}
+		obj.Block.Head.Version = uint32(x)
+		i += n
+	}
+
+	{
+		// obj.Block.Head.Time
+		x, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 {
+			return i, errors.New("obj.Block.Head.Time: varint overflows uint64")
+		}
+		obj.Block.Head.Time = x
+		i += n
+	}
+
+	{
+		// obj.Block.Head.BkSeq
+		x, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 {
+			return i, errors.New("obj.Block.Head.BkSeq: varint overflows uint64")
+		}
+		obj.Block.Head.BkSeq = x
+		i += n
+	}
+
+	{
+		// obj.Block.Head.Fee
+		x, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 {
+			return i, errors.New("obj.Block.Head.Fee: varint overflows uint64")
+		}
+		obj.Block.Head.Fee = x
+		i += n
+	}
+
+	{
+		// obj.Block.Head.PrevHash
+		if len(buf)-i < len(obj.Block.Head.PrevHash) {
+			return i, encoder.ErrBufferUnderflow
+		}
+		i += copy(obj.Block.Head.PrevHash[:], buf[i:])
+	}
+
+	{
+		// obj.Block.Head.BodyHash
+		if len(buf)-i < len(obj.Block.Head.BodyHash) {
+			return i, encoder.ErrBufferUnderflow
+		}
+		i += copy(obj.Block.Head.BodyHash[:], buf[i:])
+	}
+
+	{
+		// obj.Block.Head.UxHash
+		if len(buf)-i < len(obj.Block.Head.UxHash) {
+			return i, encoder.ErrBufferUnderflow
+		}
+		i += copy(obj.Block.Head.UxHash[:], buf[i:])
+	}
+
+	{
+		// obj.Block.Body.Transactions
+
+		ul, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 {
+			return i, errors.New("obj.Block.Body.Transactions: length varint overflows uint64")
+		}
+		i += n
+
+		if ul > 65535 {
+			return i, encoder.ErrMaxLenExceeded
+		}
+
+		length := int(ul)
+		if length > len(buf)-i {
+			return i, encoder.ErrBufferUnderflow
+		}
+
+		if length != 0 {
+			obj.Block.Body.Transactions = make(coin.Transactions, length)
+
+			for z1 := range obj.Block.Body.Transactions {
+				{
+					// obj.Block.Body.Transactions[z1].Length
+					x, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 || x > math.MaxUint32 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Length: varint overflows uint32")
+					}
+					obj.Block.Body.Transactions[z1].Length = uint32(x)
+					i += n
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].Type
+					x, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 || x > math.MaxUint8 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Type: varint overflows uint8")
+					}
+					obj.Block.Body.Transactions[z1].Type = uint8(x)
+					i += n
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].InnerHash
+					if len(buf)-i < len(obj.Block.Body.Transactions[z1].InnerHash) {
+						return i, encoder.ErrBufferUnderflow
+					}
+					i += copy(obj.Block.Body.Transactions[z1].InnerHash[:], buf[i:])
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].Sigs
+
+					ul, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Sigs: length varint overflows uint64")
+					}
+					i += n
+
+					if ul > 65535 {
+						return i, encoder.ErrMaxLenExceeded
+					}
+
+					length := int(ul)
+					if length > len(buf)-i {
+						return i, encoder.ErrBufferUnderflow
+					}
+
+					if length != 0 {
+						obj.Block.Body.Transactions[z1].Sigs = make([]cipher.Sig, length)
+
+						for z2 := range obj.Block.Body.Transactions[z1].Sigs {
+							{
+								// obj.Block.Body.Transactions[z1].Sigs[z2]
+								if len(buf)-i < len(obj.Block.Body.Transactions[z1].Sigs[z2]) {
+									return i, encoder.ErrBufferUnderflow
+								}
+								i += copy(obj.Block.Body.Transactions[z1].Sigs[z2][:], buf[i:])
+							}
+
+						}
+					}
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].In
+
+					ul, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].In: length varint overflows uint64")
+					}
+					i += n
+
+					if ul > 65535 {
+						return i, encoder.ErrMaxLenExceeded
+					}
+
+					length := int(ul)
+					if length > len(buf)-i {
+						return i, encoder.ErrBufferUnderflow
+					}
+
+					if length != 0 {
+						obj.Block.Body.Transactions[z1].In = make([]cipher.SHA256, length)
+
+						for z2 := range obj.Block.Body.Transactions[z1].In {
+							{
+								// obj.Block.Body.Transactions[z1].In[z2]
+								if len(buf)-i < len(obj.Block.Body.Transactions[z1].In[z2]) {
+									return i, encoder.ErrBufferUnderflow
+								}
+								i += copy(obj.Block.Body.Transactions[z1].In[z2][:], buf[i:])
+							}
+
+						}
+					}
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].Out
+
+					ul, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Out: length varint overflows uint64")
+					}
+					i += n
+
+					if ul > 65535 {
+						return i, encoder.ErrMaxLenExceeded
+					}
+
+					length := int(ul)
+					if length > len(buf)-i {
+						return i, encoder.ErrBufferUnderflow
+					}
+
+					if length != 0 {
+						obj.Block.Body.Transactions[z1].Out = make([]coin.TransactionOutput, length)
+
+						for z2 := range obj.Block.Body.Transactions[z1].Out {
+							{
+								// obj.Block.Body.Transactions[z1].Out[z2].Address.Version
+								x, n := binary.Uvarint(buf[i:])
+								if n == 0 {
+									return i, encoder.ErrBufferUnderflow
+								}
+								if n < 0 || x > math.MaxUint8 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Address.Version: varint overflows byte")
+								}
+								obj.Block.Body.Transactions[z1].Out[z2].Address.Version = byte(x)
+								i += n
+							}
+
+							{
+								// obj.Block.Body.Transactions[z1].Out[z2].Address.Key
+								if len(buf)-i < len(obj.Block.Body.Transactions[z1].Out[z2].Address.Key) {
+									return i, encoder.ErrBufferUnderflow
+								}
+								i += copy(obj.Block.Body.Transactions[z1].Out[z2].Address.Key[:], buf[i:])
+							}
+
+							{
+								// obj.Block.Body.Transactions[z1].Out[z2].Coins
+								x, n := binary.Uvarint(buf[i:])
+								if n == 0 {
+									return i, encoder.ErrBufferUnderflow
+								}
+								if n < 0 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Coins: varint overflows uint64")
+								}
+								obj.Block.Body.Transactions[z1].Out[z2].Coins = x
+								i += n
+							}
+
+							{
+								// obj.Block.Body.Transactions[z1].Out[z2].Hours
+								x, n := binary.Uvarint(buf[i:])
+								if n == 0 {
+									return i, encoder.ErrBufferUnderflow
+								}
+								if n < 0 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Hours: varint overflows uint64")
+								}
+								obj.Block.Body.Transactions[z1].Out[z2].Hours = x
+								i += n
+							}
+
+						}
+					}
+				}
+
+			}
+		}
+	}
+
+	{
+		// obj.Sig
+		if len(buf)-i < len(obj.Sig) {
+			return i, encoder.ErrBufferUnderflow
+		}
+		i += copy(obj.Sig[:], buf[i:])
+	}
+
+	return i, nil
+}
+
+// DecodeSignedBlockVarintCanonical decodes an object of type SignedBlock from the buffer like DecodeSignedBlockVarint,
+// except that it rejects varints with redundant continuation bytes, so that an object has exactly one encoding.
+// Returns the number of bytes used from the buffer to decode the object.
+func DecodeSignedBlockVarintCanonical(buf []byte, obj *coin.SignedBlock) (int, error) {
+	i := 0
+
+	{
+		// obj.Block.Head.Version
+		x, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 || x > math.MaxUint32 {
+			return i, errors.New("obj.Block.Head.Version: varint overflows uint32")
+		}
+		if n > 1 && buf[i+n-1] == 0 {
+			return i, errors.New("obj.Block.Head.Version: non-minimal varint")
+		}
+		obj.Block.Head.Version = uint32(x)
+		i += n
+	}
+
+	{
+		// obj.Block.Head.Time
+		x, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 {
+			return i, errors.New("obj.Block.Head.Time: varint overflows uint64")
+		}
+		if n > 1 && buf[i+n-1] == 0 {
+			return i, errors.New("obj.Block.Head.Time: non-minimal varint")
+		}
+		obj.Block.Head.Time = x
+		i += n
+	}
+
+	{
+		// obj.Block.Head.BkSeq
+		x, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 {
+			return i, errors.New("obj.Block.Head.BkSeq: varint overflows uint64")
+		}
+		if n > 1 && buf[i+n-1] == 0 {
+			return i, errors.New("obj.Block.Head.BkSeq: non-minimal varint")
+		}
+		obj.Block.Head.BkSeq = x
+		i += n
+	}
+
+	{
+		// obj.Block.Head.Fee
+		x, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 {
+			return i, errors.New("obj.Block.Head.Fee: varint overflows uint64")
+		}
+		if n > 1 && buf[i+n-1] == 0 {
+			return i, errors.New("obj.Block.Head.Fee: non-minimal varint")
+		}
+		obj.Block.Head.Fee = x
+		i += n
+	}
+
+	{
+		// obj.Block.Head.PrevHash
+		if len(buf)-i < len(obj.Block.Head.PrevHash) {
+			return i, encoder.ErrBufferUnderflow
+		}
+		i += copy(obj.Block.Head.PrevHash[:], buf[i:])
+	}
+
+	{
+		// obj.Block.Head.BodyHash
+		if len(buf)-i < len(obj.Block.Head.BodyHash) {
+			return i, encoder.ErrBufferUnderflow
+		}
+		i += copy(obj.Block.Head.BodyHash[:], buf[i:])
+	}
+
+	{
+		// obj.Block.Head.UxHash
+		if len(buf)-i < len(obj.Block.Head.UxHash) {
+			return i, encoder.ErrBufferUnderflow
+		}
+		i += copy(obj.Block.Head.UxHash[:], buf[i:])
+	}
+
+	{
+		// obj.Block.Body.Transactions
+
+		ul, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return i, encoder.ErrBufferUnderflow
+		}
+		if n < 0 {
+			return i, errors.New("obj.Block.Body.Transactions: length varint overflows uint64")
+		}
+		if n > 1 && buf[i+n-1] == 0 {
+			return i, errors.New("obj.Block.Body.Transactions: non-minimal varint")
+		}
+		i += n
+
+		if ul > 65535 {
+			return i, encoder.ErrMaxLenExceeded
+		}
+
+		length := int(ul)
+		if length > len(buf)-i {
+			return i, encoder.ErrBufferUnderflow
+		}
+
+		if length != 0 {
+			obj.Block.Body.Transactions = make(coin.Transactions, length)
+
+			for z1 := range obj.Block.Body.Transactions {
+				{
+					// obj.Block.Body.Transactions[z1].Length
+					x, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 || x > math.MaxUint32 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Length: varint overflows uint32")
+					}
+					if n > 1 && buf[i+n-1] == 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Length: non-minimal varint")
+					}
+					obj.Block.Body.Transactions[z1].Length = uint32(x)
+					i += n
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].Type
+					x, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 || x > math.MaxUint8 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Type: varint overflows uint8")
+					}
+					if n > 1 && buf[i+n-1] == 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Type: non-minimal varint")
+					}
+					obj.Block.Body.Transactions[z1].Type = uint8(x)
+					i += n
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].InnerHash
+					if len(buf)-i < len(obj.Block.Body.Transactions[z1].InnerHash) {
+						return i, encoder.ErrBufferUnderflow
+					}
+					i += copy(obj.Block.Body.Transactions[z1].InnerHash[:], buf[i:])
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].Sigs
+
+					ul, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Sigs: length varint overflows uint64")
+					}
+					if n > 1 && buf[i+n-1] == 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Sigs: non-minimal varint")
+					}
+					i += n
+
+					if ul > 65535 {
+						return i, encoder.ErrMaxLenExceeded
+					}
+
+					length := int(ul)
+					if length > len(buf)-i {
+						return i, encoder.ErrBufferUnderflow
+					}
+
+					if length != 0 {
+						obj.Block.Body.Transactions[z1].Sigs = make([]cipher.Sig, length)
+
+						for z2 := range obj.Block.Body.Transactions[z1].Sigs {
+							{
+								// obj.Block.Body.Transactions[z1].Sigs[z2]
+								if len(buf)-i < len(obj.Block.Body.Transactions[z1].Sigs[z2]) {
+									return i, encoder.ErrBufferUnderflow
+								}
+								i += copy(obj.Block.Body.Transactions[z1].Sigs[z2][:], buf[i:])
+							}
+
+						}
+					}
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].In
+
+					ul, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].In: length varint overflows uint64")
+					}
+					if n > 1 && buf[i+n-1] == 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].In: non-minimal varint")
+					}
+					i += n
+
+					if ul > 65535 {
+						return i, encoder.ErrMaxLenExceeded
+					}
+
+					length := int(ul)
+					if length > len(buf)-i {
+						return i, encoder.ErrBufferUnderflow
+					}
+
+					if length != 0 {
+						obj.Block.Body.Transactions[z1].In = make([]cipher.SHA256, length)
+
+						for z2 := range obj.Block.Body.Transactions[z1].In {
+							{
+								// obj.Block.Body.Transactions[z1].In[z2]
+								if len(buf)-i < len(obj.Block.Body.Transactions[z1].In[z2]) {
+									return i, encoder.ErrBufferUnderflow
+								}
+								i += copy(obj.Block.Body.Transactions[z1].In[z2][:], buf[i:])
+							}
+
+						}
+					}
+				}
+
+				{
+					// obj.Block.Body.Transactions[z1].Out
+
+					ul, n := binary.Uvarint(buf[i:])
+					if n == 0 {
+						return i, encoder.ErrBufferUnderflow
+					}
+					if n < 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Out: length varint overflows uint64")
+					}
+					if n > 1 && buf[i+n-1] == 0 {
+						return i, errors.New("obj.Block.Body.Transactions[z1].Out: non-minimal varint")
+					}
+					i += n
+
+					if ul > 65535 {
+						return i, encoder.ErrMaxLenExceeded
+					}
+
+					length := int(ul)
+					if length > len(buf)-i {
+						return i, encoder.ErrBufferUnderflow
+					}
+
+					if length != 0 {
+						obj.Block.Body.Transactions[z1].Out = make([]coin.TransactionOutput, length)
+
+						for z2 := range obj.Block.Body.Transactions[z1].Out {
+							{
+								// obj.Block.Body.Transactions[z1].Out[z2].Address.Version
+								x, n := binary.Uvarint(buf[i:])
+								if n == 0 {
+									return i, encoder.ErrBufferUnderflow
+								}
+								if n < 0 || x > math.MaxUint8 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Address.Version: varint overflows byte")
+								}
+								if n > 1 && buf[i+n-1] == 0 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Address.Version: non-minimal varint")
+								}
+								obj.Block.Body.Transactions[z1].Out[z2].Address.Version = byte(x)
+								i += n
+							}
+
+							{
+								// obj.Block.Body.Transactions[z1].Out[z2].Address.Key
+								if len(buf)-i < len(obj.Block.Body.Transactions[z1].Out[z2].Address.Key) {
+									return i, encoder.ErrBufferUnderflow
+								}
+								i += copy(obj.Block.Body.Transactions[z1].Out[z2].Address.Key[:], buf[i:])
+							}
+
+							{
+								// obj.Block.Body.Transactions[z1].Out[z2].Coins
+								x, n := binary.Uvarint(buf[i:])
+								if n == 0 {
+									return i, encoder.ErrBufferUnderflow
+								}
+								if n < 0 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Coins: varint overflows uint64")
+								}
+								if n > 1 && buf[i+n-1] == 0 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Coins: non-minimal varint")
+								}
+								obj.Block.Body.Transactions[z1].Out[z2].Coins = x
+								i += n
+							}
+
+							{
+								// obj.Block.Body.Transactions[z1].Out[z2].Hours
+								x, n := binary.Uvarint(buf[i:])
+								if n == 0 {
+									return i, encoder.ErrBufferUnderflow
+								}
+								if n < 0 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Hours: varint overflows uint64")
+								}
+								if n > 1 && buf[i+n-1] == 0 {
+									return i, errors.New("obj.Block.Body.Transactions[z1].Out[z2].Hours: non-minimal varint")
+								}
+								obj.Block.Body.Transactions[z1].Out[z2].Hours = x
+								i += n
+							}
+
+						}
+					}
+				}
+
+			}
+		}
+	}
+
+	{
+		// obj.Sig
+		if len(buf)-i < len(obj.Sig) {
+			return i, encoder.ErrBufferUnderflow
+		}
+		i += copy(obj.Sig[:], buf[i:])
+	}
+
+	return i, nil
+}
@@ -0,0 +1,234 @@
+package serializebench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// IntDistribution draws an integer field value
+type IntDistribution func(r *rand.Rand) uint64
+
+// Uniform returns a distribution of integers drawn uniformly from [lo, hi], which may be the whole uint64 range.
+// It panics if lo > hi.
+func Uniform(lo, hi uint64) IntDistribution {
+	if lo > hi {
+		panic(fmt.Sprintf("uniform range [%d, %d] is empty", lo, hi))
+	}
+
+	span := hi - lo
+	if span < math.MaxInt64 {
+		return func(r *rand.Rand) uint64 {
+			return lo + uint64(r.Int63n(int64(span+1)))
+		}
+	}
+	// Int63n can not draw from more than 2^63 values, so a wider range draws 64 bits and rejects the draws
+	// past its end, which are at most half of them
+	return func(r *rand.Rand) uint64 {
+		for {
+			if x := r.Uint64(); x <= span {
+				return lo + x
+			}
+		}
+	}
+}
+
+// LogUniform returns a distribution of integers in [lo, hi] whose logarithm is uniform,
+// rounded down to a multiple of unit, so that each order of magnitude is equally likely.
+// The logarithm of 0 is not defined, so it panics unless 1 <= lo <= hi and unit is positive.
+func LogUniform(lo, hi, unit uint64) IntDistribution {
+	if lo == 0 || lo > hi || unit == 0 {
+		panic(fmt.Sprintf("log-uniform range [%d, %d] with unit %d is invalid", lo, hi, unit))
+	}
+
+	logLo := math.Log(float64(lo))
+	logHi := math.Log(float64(hi))
+	return func(r *rand.Rand) uint64 {
+		// The bounds are clamped before converting, as float64(hi) may round up past the uint64 range
+		f := math.Exp(logLo + r.Float64()*(logHi-logLo))
+		x := hi
+		if f < float64(hi) {
+			x = uint64(f)
+		}
+		if x < lo {
+			x = lo
+		}
+		return x / unit * unit
+	}
+}
+
+// Constant returns a distribution which always draws v
+func Constant(v uint64) IntDistribution {
+	return func(*rand.Rand) uint64 {
+		return v
+	}
+}
+
+// BlockDistribution is the distribution of the integer fields of a block drawn by DrawBlocks
+type BlockDistribution struct {
+	// Time is Block.Head.Time, a Unix timestamp
+	Time IntDistribution
+	// BkSeq is Block.Head.BkSeq, the block height
+	BkSeq IntDistribution
+	// Fee is Block.Head.Fee, the coin hours burned by the block
+	Fee IntDistribution
+	// Coins is the amount of each output in droplets, 1e-6 of a coin
+	Coins IntDistribution
+	// Hours is the coin hours of each output
+	Hours IntDistribution
+}
+
+// SkycoinDistribution draws values in the ranges seen on the Skycoin chain: timestamps from the launch of
+// the chain to 2021, heights up to 200k, and amounts from 0.001 to 1M coins with at most 3 decimals,
+// the precision wallets send. Amounts and hours are log-uniform, as small payments are as common as
+// exchange transfers.
+var SkycoinDistribution = BlockDistribution{
+	Time:  Uniform(chainGenesisTime, 1609459200),
+	BkSeq: Uniform(0, 200000),
+	Fee:   LogUniform(1, 1e6, 1),
+	Coins: LogUniform(1e3, 1e12, 1e3),
+	Hours: LogUniform(1, 1e6, 1),
+}
+
+// DrawBlocks returns n blocks with the structure of GenerateChain(n, seed),
+// and their integer fields drawn from d
+func DrawBlocks(n int, seed int64, d BlockDistribution) []coin.SignedBlock {
+	blocks := GenerateChain(n, seed)
+
+	r := rand.New(rand.NewSource(seed))
+	for i := range blocks {
+		head := &blocks[i].Block.Head
+		head.Time = d.Time(r)
+		head.BkSeq = d.BkSeq(r)
+		head.Fee = d.Fee(r)
+
+		for _, txn := range blocks[i].Block.Body.Transactions {
+			for j := range txn.Out {
+				txn.Out[j].Coins = d.Coins(r)
+				txn.Out[j].Hours = d.Hours(r)
+			}
+		}
+	}
+
+	return blocks
+}
+
+// SizeDistribution summarizes the encoded sizes of a set of blocks in one format
+type SizeDistribution struct {
+	// Mean is the mean size of a block in bytes
+	Mean float64
+	// P50, P99 and Max are percentiles of the size of a block in bytes
+	P50 int
+	P99 int
+	Max int
+	// Fields maps the path of each integer field, such as Block.Body.Transactions[].Out[].Coins,
+	// to a histogram of the bytes spent on its values: Fields[path][n] is the number of values which took n bytes,
+	// including Colfer's field header. A Colfer field omitted because it is zero takes 0 bytes.
+	// It is nil for formats which Dissect does not support.
+	Fields map[string][]int
+}
+
+// intFields are the paths of the integer fields of a coin.SignedBlock, with list indexes removed
+var intFields = []string{
+	"Block.Head.Version",
+	"Block.Head.Time",
+	"Block.Head.BkSeq",
+	"Block.Head.Fee",
+	"Block.Body.Transactions[].Length",
+	"Block.Body.Transactions[].Type",
+	"Block.Body.Transactions[].Out[].Address.Version",
+	"Block.Body.Transactions[].Out[].Coins",
+	"Block.Body.Transactions[].Out[].Hours",
+}
+
+// MeasureSizes encodes blocks with c and returns the distribution of their sizes.
+// Field histograms are only measured for DissectFormats, so there are none for gotiny, json, dict and avro.
+func MeasureSizes(c Codec, blocks []coin.SignedBlock) (SizeDistribution, error) {
+	var d SizeDistribution
+	dissect := inStrings(DissectFormats, c.Name)
+	if dissect {
+		d.Fields = make(map[string][]int)
+	}
+
+	sizes := make([]int, len(blocks))
+	var total int
+	for i := range blocks {
+		data, err := c.Encode(&blocks[i])
+		if err != nil {
+			return SizeDistribution{}, err
+		}
+		sizes[i] = len(data)
+		total += len(data)
+
+		if !dissect {
+			continue
+		}
+		fields, err := Dissect(c.Name, data)
+		if err != nil {
+			return SizeDistribution{}, err
+		}
+		countFieldSizes(d.Fields, &blocks[i], fields)
+	}
+
+	if len(sizes) == 0 {
+		return d, nil
+	}
+
+	sort.Ints(sizes)
+	d.Mean = float64(total) / float64(len(sizes))
+	d.P50 = percentile(sizes, 50)
+	d.P99 = percentile(sizes, 99)
+	d.Max = sizes[len(sizes)-1]
+	return d, nil
+}
+
+// percentile returns the pth percentile of sorted, by the nearest-rank method
+func percentile(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// countFieldSizes adds the sizes of the integer fields of obj, dissected as fields, to the histograms
+func countFieldSizes(hist map[string][]int, obj *coin.SignedBlock, fields []Field) {
+	sizes := make(map[string]int)
+	for _, f := range fields {
+		switch f.Kind {
+		case "int", "header", "fixed header":
+			sizes[f.Path] += f.Size
+		}
+	}
+
+	add := func(path, field string) {
+		h := hist[field]
+		n := sizes[path]
+		for len(h) <= n {
+			h = append(h, 0)
+		}
+		h[n]++
+		hist[field] = h
+	}
+
+	// The header fields occur once per block
+	for _, field := range intFields[:4] {
+		add(field, field)
+	}
+
+	for i, txn := range obj.Block.Body.Transactions {
+		prefix := "Block.Body.Transactions[" + strconv.Itoa(i) + "]"
+		add(prefix+".Length", "Block.Body.Transactions[].Length")
+		add(prefix+".Type", "Block.Body.Transactions[].Type")
+		for j := range txn.Out {
+			out := prefix + ".Out[" + strconv.Itoa(j) + "]"
+			add(out+".Address.Version", "Block.Body.Transactions[].Out[].Address.Version")
+			add(out+".Coins", "Block.Body.Transactions[].Out[].Coins")
+			add(out+".Hours", "Block.Body.Transactions[].Out[].Hours")
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package serializebench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestSizeDistribution prints the size distribution of blocks drawn from SkycoinDistribution in every format,
+// and the mean bytes spent on each integer field by the formats with variable-width integers
+func TestSizeDistribution(t *testing.T) {
+	blocks := DrawBlocks(storeBlocks, 1, SkycoinDistribution)
+
+	dists := make(map[string]SizeDistribution)
+	fmt.Printf("%-11s %9s %6s %6s %6s\n", "format", "mean", "p50", "p99", "max")
+	for _, c := range Codecs {
+		d, err := MeasureSizes(c, blocks)
+		if err != nil {
+			t.Fatalf("%s: %v", c.Name, err)
+		}
+		dists[c.Name] = d
+		fmt.Printf("%-11s %9.1f %6d %6d %6d\n", c.Name, d.Mean, d.P50, d.P99, d.Max)
+	}
+
+	// gotiny has variable-width integers too, but no field histograms, as Dissect does not support it
+	varint := []string{"gencodevar", "cgvarint", "colfer"}
+	fmt.Printf("\n%-48s", "mean bytes per value")
+	for _, name := range varint {
+		fmt.Printf(" %10s", name)
+	}
+	fmt.Println()
+	for _, field := range intFields {
+		fmt.Printf("%-48s", field)
+		for _, name := range varint {
+			fmt.Printf(" %10.2f", meanFieldSize(dists[name].Fields[field]))
+		}
+		fmt.Println()
+	}
+
+	for _, field := range []string{"Block.Body.Transactions[].Out[].Coins", "Block.Body.Transactions[].Out[].Hours"} {
+		fmt.Printf("\n%s histogram\n%-11s", field, "bytes")
+		for n := 0; n <= 10; n++ {
+			fmt.Printf(" %6d", n)
+		}
+		fmt.Println()
+		for _, name := range varint {
+			fmt.Printf("%-11s", name)
+			h := dists[name].Fields[field]
+			for n := 0; n <= 10; n++ {
+				var count int
+				if n < len(h) {
+					count = h[n]
+				}
+				fmt.Printf(" %6d", count)
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// meanFieldSize returns the mean of a histogram of field sizes
+func meanFieldSize(h []int) float64 {
+	var count, total int
+	for n, c := range h {
+		count += c
+		total += n * c
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+func TestIntDistributionBounds(t *testing.T) {
+	cases := []struct {
+		name   string
+		d      IntDistribution
+		lo, hi uint64
+	}{
+		{"constant range", Uniform(5, 5), 5, 5},
+		{"whole range", Uniform(0, math.MaxUint64), 0, math.MaxUint64},
+		{"range wider than 2^63", Uniform(1<<62, math.MaxUint64-1), 1 << 62, math.MaxUint64 - 1},
+		{"log-uniform to the top of the range", LogUniform(1, math.MaxUint64, 1), 1, math.MaxUint64},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, tc := range cases {
+		var high bool
+		for i := 0; i < 1000; i++ {
+			x := tc.d(r)
+			if x < tc.lo || x > tc.hi {
+				t.Fatalf("%s: drew %d outside [%d, %d]", tc.name, x, tc.lo, tc.hi)
+			}
+			high = high || x-tc.lo >= (tc.hi-tc.lo)/2
+		}
+		if !high && tc.lo != tc.hi {
+			t.Errorf("%s: drew nothing in the upper half of the range", tc.name)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		fn   func()
+	}{
+		{"empty uniform range", func() { Uniform(2, 1) }},
+		{"log-uniform from 0", func() { LogUniform(0, 10, 1) }},
+		{"log-uniform with unit 0", func() { LogUniform(1, 10, 0) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", tc.name)
+				}
+			}()
+			tc.fn()
+		}()
+	}
+}
+
+func TestMeasureSizes(t *testing.T) {
+	d := SkycoinDistribution
+	d.Hours = Constant(0)
+	blocks := DrawBlocks(100, 1, d)
+
+	var txns, outputs int
+	for _, b := range blocks {
+		txns += len(b.Block.Body.Transactions)
+		for _, txn := range b.Block.Body.Transactions {
+			outputs += len(txn.Out)
+			for _, out := range txn.Out {
+				if out.Coins%1e3 != 0 || out.Coins < 1e3 || out.Coins > 1e12 {
+					t.Fatalf("coins %d are not drawn from the distribution", out.Coins)
+				}
+			}
+		}
+	}
+
+	cases := []struct {
+		format string
+		field  string
+		// size is the number of bytes each of the count values of field takes
+		size  int
+		count int
+	}{
+		{"skyenc", "Block.Body.Transactions[].Out[].Hours", 8, outputs},
+		{"skyenc", "Block.Head.Time", 8, len(blocks)},
+		{"gencodevar", "Block.Body.Transactions[].Out[].Hours", 1, outputs},
+		// Colfer omits zero fields
+		{"colfer", "Block.Body.Transactions[].Out[].Hours", 0, outputs},
+		{"colfer", "Block.Body.Transactions[].Type", 0, txns},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.format+"/"+tc.field, func(t *testing.T) {
+			c, err := CodecByName(tc.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := MeasureSizes(c, blocks)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if s.P50 > s.P99 || s.P99 > s.Max || s.Mean > float64(s.Max) {
+				t.Errorf("inconsistent sizes: %+v", s)
+			}
+
+			h := s.Fields[tc.field]
+			if len(h) != tc.size+1 || h[tc.size] != tc.count {
+				t.Errorf("expected %d values of %d bytes, got histogram %v", tc.count, tc.size, h)
+			}
+		})
+	}
+
+	c, err := CodecByName("gotiny")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := MeasureSizes(c, blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Fields != nil || s.Max == 0 {
+		t.Errorf("expected sizes without field histograms, got %+v", s)
+	}
+}
@@ -0,0 +1,349 @@
+package serializebench
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// A block store is a pair of append-only files. The data file holds each block encoded with one Codec,
+// prefixed with its length as a little-endian uint32. The index file, at the data file's path with
+// an .idx suffix, holds the offset of each block in the data file as a little-endian uint64.
+//
+// Blocks are appended to the data file before their offsets are appended to the index, and Sync
+// syncs the data file before the index, so that the index only refers to blocks that are complete.
+// A block written after the last indexed block, by a process which stopped before writing its offset,
+// is discarded when the store is opened. Without Sync, the operating system may still write the index
+// before the data, so indexed blocks which extend past the end of the data file are also discarded.
+
+const (
+	// storeLengthSize is the size of the length prefix of a block in the data file
+	storeLengthSize = 4
+	// storeOffsetSize is the size of an offset in the index file
+	storeOffsetSize = 8
+)
+
+// BlockStore is an append-only file store of blocks encoded with a Codec
+type BlockStore struct {
+	codec  Codec
+	limits DecodeLimits
+
+	data  *os.File
+	index *os.File
+	// dataW and indexW buffer appends until the next Sync or read
+	dataW  *bufio.Writer
+	indexW *bufio.Writer
+
+	// offsets are the offsets of the blocks in the data file
+	offsets []int64
+	// indexed is the number of offsets written to indexW. The others are written after the data is flushed.
+	indexed int
+	// size is the size of the data file, including buffered appends
+	size int64
+	buf  []byte
+}
+
+// OpenBlockStore opens the block store at path, creating it if it does not exist.
+// Blocks are encoded with c, and read blocks are decoded with limits.
+func OpenBlockStore(path string, c Codec, limits DecodeLimits) (*BlockStore, error) {
+	data, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := os.OpenFile(path+".idx", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+
+	s := &BlockStore{
+		codec:  c,
+		limits: limits,
+		data:   data,
+		index:  index,
+	}
+
+	if err := s.load(); err != nil {
+		data.Close()
+		index.Close()
+		return nil, fmt.Errorf("open block store %s: %v", path, err)
+	}
+
+	s.dataW = bufio.NewWriter(data)
+	s.indexW = bufio.NewWriter(index)
+	return s, nil
+}
+
+// load reads the index, checks it against the data file, and discards any unindexed data
+func (s *BlockStore) load() error {
+	index, err := readAll(s.index)
+	if err != nil {
+		return err
+	}
+	// Drop a partially written offset
+	n := len(index) / storeOffsetSize
+
+	// The length prefix of each block is checked when it is read, so only the last block's is read here
+	s.offsets = make([]int64, n)
+	var end int64
+	for i := range s.offsets {
+		offset := int64(binary.LittleEndian.Uint64(index[i*storeOffsetSize:]))
+		if offset < end || (i == 0 && offset != 0) {
+			return fmt.Errorf("block %d is at offset %d, before the end of the previous block", i, offset)
+		}
+		s.offsets[i] = offset
+		end = offset + storeLengthSize
+	}
+
+	info, err := s.data.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Drop the offsets of blocks which were not completely written to the data file
+	for ; n != 0; n-- {
+		offset := s.offsets[n-1]
+		if offset+storeLengthSize > info.Size() {
+			continue
+		}
+		size, err := s.blockSize(offset)
+		if err != nil {
+			return fmt.Errorf("block %d: %v", n-1, err)
+		}
+		if end = offset + storeLengthSize + int64(size); end <= info.Size() {
+			break
+		}
+	}
+	if n == 0 {
+		end = 0
+	}
+	s.offsets = s.offsets[:n]
+	s.indexed = n
+
+	if err := s.data.Truncate(end); err != nil {
+		return err
+	}
+	if err := s.index.Truncate(int64(n * storeOffsetSize)); err != nil {
+		return err
+	}
+	if _, err := s.data.Seek(end, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := s.index.Seek(int64(n*storeOffsetSize), io.SeekStart); err != nil {
+		return err
+	}
+
+	s.size = end
+	return nil
+}
+
+// readAll reads the whole of f from the start
+func readAll(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, info.Size())
+	_, err = f.ReadAt(buf, 0)
+	return buf, err
+}
+
+// blockSize reads the length prefix of the block at offset in the data file
+func (s *BlockStore) blockSize(offset int64) (uint32, error) {
+	var prefix [storeLengthSize]byte
+	if _, err := s.data.ReadAt(prefix[:], offset); err != nil {
+		if err == io.EOF {
+			return 0, &DecodeError{
+				Kind:   ErrTruncated,
+				Offset: int(offset),
+				Err:    io.ErrUnexpectedEOF,
+			}
+		}
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(prefix[:]), nil
+}
+
+// Len returns the number of blocks in the store
+func (s *BlockStore) Len() int {
+	return len(s.offsets)
+}
+
+// Size returns the size of the data file in bytes, including appends not yet synced
+func (s *BlockStore) Size() int64 {
+	return s.size
+}
+
+// Append encodes obj and appends it to the store, returning its index.
+// The block is buffered, and is only durable after Sync.
+func (s *BlockStore) Append(obj *coin.SignedBlock) (int, error) {
+	data, err := s.codec.Encode(obj)
+	if err != nil {
+		return 0, err
+	}
+
+	var prefix [storeLengthSize]byte
+	binary.LittleEndian.PutUint32(prefix[:], uint32(len(data)))
+	if _, err := s.dataW.Write(prefix[:]); err != nil {
+		return 0, err
+	}
+	if _, err := s.dataW.Write(data); err != nil {
+		return 0, err
+	}
+
+	s.offsets = append(s.offsets, s.size)
+	s.size += int64(storeLengthSize + len(data))
+	return len(s.offsets) - 1, nil
+}
+
+// flush writes the buffered appends to the files, data first
+func (s *BlockStore) flush() error {
+	if err := s.dataW.Flush(); err != nil {
+		return err
+	}
+	return s.flushIndex()
+}
+
+// flushIndex writes the offsets of the appended blocks to the index. The blocks must have been flushed,
+// so that the index never refers to data which is still buffered.
+func (s *BlockStore) flushIndex() error {
+	var offset [storeOffsetSize]byte
+	for _, o := range s.offsets[s.indexed:] {
+		binary.LittleEndian.PutUint64(offset[:], uint64(o))
+		if _, err := s.indexW.Write(offset[:]); err != nil {
+			return err
+		}
+		s.indexed++
+	}
+	return s.indexW.Flush()
+}
+
+// Sync writes the buffered appends and syncs the data file, then the index
+func (s *BlockStore) Sync() error {
+	if err := s.dataW.Flush(); err != nil {
+		return err
+	}
+	if err := s.data.Sync(); err != nil {
+		return err
+	}
+	if err := s.flushIndex(); err != nil {
+		return err
+	}
+	return s.index.Sync()
+}
+
+// Get decodes the block at index i into obj.
+// Decode errors are a *DecodeError with the offset in the data file.
+func (s *BlockStore) Get(i int, obj *coin.SignedBlock) error {
+	if i < 0 || i >= len(s.offsets) {
+		return fmt.Errorf("block %d is out of range [0, %d)", i, len(s.offsets))
+	}
+
+	if s.dataW.Buffered() != 0 {
+		if err := s.flush(); err != nil {
+			return err
+		}
+	}
+
+	offset := s.offsets[i]
+	end := s.size
+	if i+1 < len(s.offsets) {
+		end = s.offsets[i+1]
+	}
+
+	n := int(end - offset)
+	if cap(s.buf) < n {
+		s.buf = make([]byte, n)
+	}
+	s.buf = s.buf[:n]
+	if _, err := s.data.ReadAt(s.buf, offset); err != nil {
+		return err
+	}
+
+	return s.decode(s.buf, offset, obj)
+}
+
+// decode decodes the block stored as buf, including its length prefix, at offset
+func (s *BlockStore) decode(buf []byte, offset int64, obj *coin.SignedBlock) error {
+	data, err := recordData(buf, offset)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.codec.Decode(data, obj, s.limits)
+	return recordError(err, offset)
+}
+
+// recordData checks the length prefix of the record buf at offset against the index, and returns the encoded block
+func recordData(buf []byte, offset int64) ([]byte, error) {
+	size := binary.LittleEndian.Uint32(buf)
+	if int(size) != len(buf)-storeLengthSize {
+		return nil, &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: int(offset),
+			Err:    fmt.Errorf("block length %d does not match the index", size),
+		}
+	}
+	return buf[storeLengthSize:], nil
+}
+
+// recordError adds the offset of the encoded block of the record at offset to a *DecodeError
+func recordError(err error, offset int64) error {
+	if e, ok := err.(*DecodeError); ok && e.Offset >= 0 {
+		e.Offset += int(offset) + storeLengthSize
+	}
+	return err
+}
+
+// Range decodes the blocks in order, reading the data file sequentially, and calls fn with each.
+// obj is reused for every block. Range stops at the first error returned by fn.
+func (s *BlockStore) Range(fn func(i int, obj *coin.SignedBlock) error) error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+
+	r := bufio.NewReaderSize(io.NewSectionReader(s.data, 0, s.size), 64*1024)
+	var obj coin.SignedBlock
+	for i, offset := range s.offsets {
+		end := s.size
+		if i+1 < len(s.offsets) {
+			end = s.offsets[i+1]
+		}
+
+		n := int(end - offset)
+		if cap(s.buf) < n {
+			s.buf = make([]byte, n)
+		}
+		s.buf = s.buf[:n]
+		if _, err := io.ReadFull(r, s.buf); err != nil {
+			return err
+		}
+
+		obj = coin.SignedBlock{}
+		if err := s.decode(s.buf, offset, &obj); err != nil {
+			return err
+		}
+		if err := fn(i, &obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close syncs and closes the store
+func (s *BlockStore) Close() error {
+	err := s.Sync()
+	if cerr := s.data.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := s.index.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
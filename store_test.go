@@ -0,0 +1,347 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// storeBlocks is the number of blocks written to a store by the tests and benchmarks
+const storeBlocks = 1000
+
+func tempDir(tb testing.TB) string {
+	dir, err := ioutil.TempDir("", "serializebench")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return dir
+}
+
+// writeStore writes blocks to a new store at path and closes it
+func writeStore(tb testing.TB, path string, c Codec, blocks []coin.SignedBlock) {
+	s, err := OpenBlockStore(path, c, DefaultDecodeLimits)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for i := range blocks {
+		if _, err := s.Append(&blocks[i]); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestBlockStore(t *testing.T) {
+	blocks := GenerateChain(20, 1)
+
+	for _, c := range Codecs {
+		t.Run(c.Name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "blocks")
+
+			writeStore(t, path, c, blocks[:10])
+
+			// Reopen and append the rest
+			s, err := OpenBlockStore(path, c, DefaultDecodeLimits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			if s.Len() != 10 {
+				t.Fatalf("expected 10 blocks, got %d", s.Len())
+			}
+			for i := 10; i < len(blocks); i++ {
+				if j, err := s.Append(&blocks[i]); err != nil {
+					t.Fatal(err)
+				} else if j != i {
+					t.Fatalf("expected block %d to be appended at %d", i, j)
+				}
+			}
+
+			// Random reads, including blocks not yet synced
+			for _, i := range rand.New(rand.NewSource(1)).Perm(len(blocks)) {
+				var result coin.SignedBlock
+				if err := s.Get(i, &result); err != nil {
+					t.Fatalf("block %d: %v", i, err)
+				}
+				if diff := diffBlocks(blocks[i], result); diff != "" {
+					t.Fatalf("block %d: %s", i, diff)
+				}
+			}
+
+			// Sequential reads
+			n := 0
+			err = s.Range(func(i int, obj *coin.SignedBlock) error {
+				if diff := diffBlocks(blocks[i], *obj); diff != "" {
+					return fmt.Errorf("block %d: %s", i, diff)
+				}
+				n++
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(blocks) {
+				t.Errorf("expected %d blocks, got %d", len(blocks), n)
+			}
+
+			if err := s.Get(len(blocks), &coin.SignedBlock{}); err == nil {
+				t.Error("expected an error for a block out of range")
+			}
+		})
+	}
+}
+
+// blockOffset returns the offset of block i in an index file
+func blockOffset(t *testing.T, index []byte, i int) int64 {
+	if (i+1)*storeOffsetSize > len(index) {
+		t.Fatalf("the index has no block %d", i)
+	}
+	return int64(binary.LittleEndian.Uint64(index[i*storeOffsetSize:]))
+}
+
+func TestBlockStoreRecovery(t *testing.T) {
+	c, err := CodecByName("skyenc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := GenerateChain(10, 1)
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "blocks")
+	writeStore(t, path, c, blocks)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := ioutil.ReadFile(path + ".idx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A process stopped after writing the last block, and part of its offset
+	if err := ioutil.WriteFile(path+".idx", index[:len(index)-3], 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenBlockStore(path, c, DefaultDecodeLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != len(blocks)-1 {
+		t.Errorf("expected %d blocks, got %d", len(blocks)-1, s.Len())
+	}
+	if s.Size() != int64(len(data)-storeLengthSize-EncodeSizeSignedBlock(&blocks[len(blocks)-1])) {
+		t.Errorf("expected the unindexed block to be discarded, the store is %d bytes", s.Size())
+	}
+	// The last block can be appended again
+	if _, err := s.Append(&blocks[len(blocks)-1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if recovered, err := ioutil.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if !cmp.Equal(data, recovered) {
+		t.Error("the recovered data file is different")
+	}
+
+	// The index was written before the data of the last blocks: the blocks past the end of the data file,
+	// including a block with only part of its length prefix, are discarded
+	for _, tc := range []struct {
+		blocks int
+		size   int
+	}{
+		{len(blocks) - 1, len(data) - 1},
+		{4, int(blockOffset(t, index, 4)) + 2},
+		{0, 0},
+	} {
+		if err := ioutil.WriteFile(path, data[:tc.size], 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path+".idx", index, 0644); err != nil {
+			t.Fatal(err)
+		}
+		s, err := OpenBlockStore(path, c, DefaultDecodeLimits)
+		if err != nil {
+			t.Fatalf("data file of %d bytes: %v", tc.size, err)
+		}
+		if s.Len() != tc.blocks {
+			t.Errorf("data file of %d bytes: expected %d blocks, got %d", tc.size, tc.blocks, s.Len())
+		}
+		for i := 0; i < s.Len(); i++ {
+			var block coin.SignedBlock
+			if err := s.Get(i, &block); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// The discarded blocks can be appended again
+		for i := s.Len(); i < len(blocks); i++ {
+			if _, err := s.Append(&blocks[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if recovered, err := ioutil.ReadFile(path); err != nil {
+			t.Fatal(err)
+		} else if !cmp.Equal(data, recovered) {
+			t.Errorf("data file of %d bytes: the recovered data file is different", tc.size)
+		}
+		if recovered, err := ioutil.ReadFile(path + ".idx"); err != nil {
+			t.Fatal(err)
+		} else if !cmp.Equal(index, recovered) {
+			t.Errorf("data file of %d bytes: the recovered index is different", tc.size)
+		}
+	}
+
+	// A corrupted block is reported with an offset in the data file
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := append([]byte(nil), data...)
+	offset := len(data) - EncodeSizeSignedBlock(&blocks[len(blocks)-1])
+	// The transaction count of the last block
+	corrupt[offset+124] = 0xff
+	if err := ioutil.WriteFile(path, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err = OpenBlockStore(path, c, DefaultDecodeLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	err = s.Get(len(blocks)-1, &coin.SignedBlock{})
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	if decodeErr.Offset < offset || decodeErr.Offset > len(data) {
+		t.Errorf("expected an offset in the last block, at [%d, %d], got %d", offset, len(data), decodeErr.Offset)
+	}
+}
+
+// TestBlockStoreSize prints the size of a store of storeBlocks blocks for each codec
+func TestBlockStoreSize(t *testing.T) {
+	blocks := GenerateChain(storeBlocks, 1)
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	fmt.Printf("%-11s %12s %10s\n", "format", "data bytes", "per block")
+	for _, c := range Codecs {
+		path := filepath.Join(dir, c.Name)
+		writeStore(t, path, c, blocks)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Printf("%-11s %12d %10.1f\n", c.Name, info.Size(), float64(info.Size())/storeBlocks)
+	}
+}
+
+// BenchmarkBlockStoreWrite appends storeBlocks blocks to a new store and syncs it.
+// MB/s is the write throughput of the data file.
+func BenchmarkBlockStoreWrite(b *testing.B) {
+	blocks := GenerateChain(storeBlocks, 1)
+
+	for _, c := range Codecs {
+		b.Run(c.Name, func(b *testing.B) {
+			dir := tempDir(b)
+			defer os.RemoveAll(dir)
+
+			var size int64
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				path := filepath.Join(dir, fmt.Sprint(i))
+				s, err := OpenBlockStore(path, c, DefaultDecodeLimits)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for j := range blocks {
+					if _, err := s.Append(&blocks[j]); err != nil {
+						b.Fatal(err)
+					}
+				}
+				size = s.Size()
+				if err := s.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.SetBytes(size)
+			b.ReportMetric(float64(size), "file-bytes")
+		})
+	}
+}
+
+// openBenchmarkStore writes storeBlocks blocks to a store in dir and reopens it
+func openBenchmarkStore(b *testing.B, dir string, c Codec) *BlockStore {
+	path := filepath.Join(dir, "blocks")
+	writeStore(b, path, c, GenerateChain(storeBlocks, 1))
+
+	s, err := OpenBlockStore(path, c, DefaultDecodeLimits)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return s
+}
+
+// BenchmarkBlockStoreRandomRead reads one block at a random height per op, so ns/op is the read latency
+func BenchmarkBlockStoreRandomRead(b *testing.B) {
+	for _, c := range Codecs {
+		b.Run(c.Name, func(b *testing.B) {
+			dir := tempDir(b)
+			defer os.RemoveAll(dir)
+			s := openBenchmarkStore(b, dir, c)
+			defer s.Close()
+
+			r := rand.New(rand.NewSource(1))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var result coin.SignedBlock
+				if err := s.Get(r.Intn(storeBlocks), &result); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkBlockStoreScan reads the whole store sequentially per op
+func BenchmarkBlockStoreScan(b *testing.B) {
+	for _, c := range Codecs {
+		b.Run(c.Name, func(b *testing.B) {
+			dir := tempDir(b)
+			defer os.RemoveAll(dir)
+			s := openBenchmarkStore(b, dir, c)
+			defer s.Close()
+
+			b.SetBytes(s.Size())
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := s.Range(func(int, *coin.SignedBlock) error { return nil }); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package serializebench
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// A block stream is a sequence of blocks encoded with one Codec. Each binary block is prefixed
+// with its length as a uvarint. Text blocks are written one per line.
+
+// BlockWriter writes a stream of blocks
+type BlockWriter struct {
+	w     *bufio.Writer
+	codec Codec
+}
+
+// NewBlockWriter returns a BlockWriter writing blocks encoded with codec to w
+func NewBlockWriter(w io.Writer, codec Codec) *BlockWriter {
+	return &BlockWriter{
+		w:     bufio.NewWriter(w),
+		codec: codec,
+	}
+}
+
+// Write encodes obj and writes it to the stream, returning its encoding
+func (w *BlockWriter) Write(obj *coin.SignedBlock) ([]byte, error) {
+	data, err := w.codec.Encode(obj)
+	if err != nil {
+		return nil, err
+	}
+	return data, w.WriteEncoded(data)
+}
+
+// WriteEncoded writes a block already encoded with the writer's codec to the stream
+func (w *BlockWriter) WriteEncoded(data []byte) error {
+	if w.codec.Text {
+		if _, err := w.w.Write(data); err != nil {
+			return err
+		}
+		return w.w.WriteByte('\n')
+	}
+
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(len(data)))
+	if _, err := w.w.Write(prefix[:n]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(data)
+	return err
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (w *BlockWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// BlockReader reads a stream of blocks, holding only one block in memory at a time
+type BlockReader struct {
+	r      *bufio.Reader
+	codec  Codec
+	limits DecodeLimits
+	buf    []byte
+	// start is the stream offset of the encoding in buf
+	start int
+	// offset is the stream offset of the next byte to read
+	offset int
+}
+
+// NewBlockReader returns a BlockReader reading blocks encoded with codec from r.
+// A block longer than limits.MaxBytes is rejected before it is read.
+func NewBlockReader(r io.Reader, codec Codec, limits DecodeLimits) *BlockReader {
+	return &BlockReader{
+		r:      bufio.NewReader(r),
+		codec:  codec,
+		limits: limits,
+	}
+}
+
+// Next decodes the next block into obj and returns its encoding, which is valid until the next call.
+// It returns io.EOF at the end of the stream. Errors are a *DecodeError with the offset in the stream.
+func (r *BlockReader) Next(obj *coin.SignedBlock) ([]byte, error) {
+	var err error
+	if r.codec.Text {
+		err = r.readLine()
+	} else {
+		err = r.readFrame()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.codec.Decode(r.buf, obj, r.limits); err != nil {
+		if e, ok := err.(*DecodeError); ok && e.Offset >= 0 {
+			e.Offset += r.start
+		}
+		return nil, err
+	}
+
+	return r.buf, nil
+}
+
+// readFrame reads a length-prefixed block into r.buf
+func (r *BlockReader) readFrame() error {
+	start := r.offset
+
+	n, err := binary.ReadUvarint(r.r)
+	switch err {
+	case nil:
+	case io.EOF:
+		return io.EOF
+	case io.ErrUnexpectedEOF:
+		return &DecodeError{
+			Kind:   ErrTruncated,
+			Offset: start,
+			Err:    err,
+		}
+	default:
+		return &DecodeError{
+			Kind:   ErrMalformed,
+			Offset: start,
+			Err:    err,
+		}
+	}
+	r.offset += uvarintSize(n)
+	r.start = r.offset
+
+	if err := checkLimit("MaxBytes", r.limits.MaxBytes, n, start, ""); err != nil {
+		return err
+	}
+
+	var m int
+	r.buf, m, err = readFull(r.r, r.buf, n)
+	r.offset += m
+	if err == io.ErrUnexpectedEOF {
+		return &DecodeError{
+			Kind:   ErrTruncated,
+			Offset: r.offset,
+			Err:    io.ErrUnexpectedEOF,
+		}
+	}
+	return err
+}
+
+// readChunkSize is the size by which readFull grows a buffer which is too small for a frame
+const readChunkSize = 64 * 1024
+
+// readFull reads n bytes from r into buf, reusing its capacity, and returns the buffer and the number of bytes read.
+// A buffer too small for n is grown as the bytes arrive rather than allocated up front, so that a frame claiming
+// more bytes than the stream holds, with no limit on its length, only allocates about as much as was read.
+// It returns io.ErrUnexpectedEOF if the stream ends before n bytes.
+func readFull(r io.Reader, buf []byte, n uint64) ([]byte, int, error) {
+	buf = buf[:0]
+	for uint64(len(buf)) < n {
+		if len(buf) == cap(buf) {
+			buf = append(buf, make([]byte, readChunkSize)...)[:len(buf)]
+		}
+		end := cap(buf)
+		if remaining := n - uint64(len(buf)); uint64(end-len(buf)) > remaining {
+			end = len(buf) + int(remaining)
+		}
+
+		m, err := io.ReadFull(r, buf[len(buf):end])
+		buf = buf[:len(buf)+m]
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return buf, len(buf), err
+		}
+	}
+	return buf, len(buf), nil
+}
+
+// readLine reads a newline-terminated block into r.buf
+func (r *BlockReader) readLine() error {
+	start := r.offset
+	r.start = start
+	r.buf = r.buf[:0]
+
+	for {
+		line, err := r.r.ReadSlice('\n')
+		r.offset += len(line)
+		r.buf = append(r.buf, line...)
+
+		if limitErr := checkLimit("MaxBytes", r.limits.MaxBytes, uint64(len(r.buf)), start, ""); limitErr != nil {
+			return limitErr
+		}
+
+		switch err {
+		case nil:
+			r.buf = r.buf[:len(r.buf)-1]
+			return nil
+		case bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			if len(r.buf) == 0 {
+				return io.EOF
+			}
+			// The last line may not be terminated
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// uvarintSize returns the number of bytes of x encoded as a uvarint
+func uvarintSize(x uint64) int {
+	n := 1
+	for x >= 0x80 {
+		x >>= 7
+		n++
+	}
+	return n
+}
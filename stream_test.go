@@ -0,0 +1,101 @@
+package serializebench
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+func TestBlockStream(t *testing.T) {
+	blocks := []coin.SignedBlock{getBlock(), getBlock(), getBlock()}
+	blocks[1].Block.Head.BkSeq++
+	blocks[2].Block.Body.Transactions = nil
+
+	for _, c := range Codecs {
+		t.Run(c.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewBlockWriter(&buf, c)
+			for i := range blocks {
+				if _, err := w.Write(&blocks[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			stream := buf.Bytes()
+
+			r := NewBlockReader(bytes.NewReader(stream), c, DefaultDecodeLimits)
+			for i := range blocks {
+				var result coin.SignedBlock
+				if _, err := r.Next(&result); err != nil {
+					t.Fatalf("block %d: %v", i, err)
+				}
+				if diff := diffBlocks(blocks[i], result); diff != "" {
+					t.Errorf("block %d: %s", i, diff)
+				}
+			}
+
+			var result coin.SignedBlock
+			if _, err := r.Next(&result); err != io.EOF {
+				t.Fatalf("expected io.EOF, got %v", err)
+			}
+
+			// A stream cut inside the last block is truncated
+			r = NewBlockReader(bytes.NewReader(stream[:len(stream)-10]), c, DefaultDecodeLimits)
+			var err error
+			for i := 0; i < len(blocks) && err == nil; i++ {
+				_, err = r.Next(&result)
+			}
+			if !errors.Is(err, ErrTruncated) {
+				t.Fatalf("expected ErrTruncated, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBlockStreamLimit(t *testing.T) {
+	c, err := CodecByName("sky")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A frame claiming 1GiB is rejected before it is read
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], 1<<30)
+
+	r := NewBlockReader(bytes.NewReader(prefix[:n]), c, DefaultDecodeLimits)
+
+	var result coin.SignedBlock
+	_, err = r.Next(&result)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	// Without a limit, a frame claiming more than any buffer can hold is truncated
+	// after allocating about as much as the stream holds
+	n = binary.PutUvarint(prefix[:], 1<<63)
+	stream := append(append([]byte(nil), prefix[:n]...), make([]byte, 1000)...)
+	r = NewBlockReader(bytes.NewReader(stream), c, DecodeLimits{})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = r.Next(&result)
+	runtime.ReadMemStats(&after)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected ErrTruncated, got %v", err)
+	}
+	if decodeErr.Offset != len(stream) {
+		t.Errorf("expected offset %d, got %d", len(stream), decodeErr.Offset)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1024*1024 {
+		t.Errorf("allocated %d bytes for a frame of %d bytes", allocated, len(stream))
+	}
+}
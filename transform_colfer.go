@@ -0,0 +1,147 @@
+// Code generated by github.com/gz-c/skycoin-serialization-benchmarks/cmd/transformgen. DO NOT EDIT.
+package serializebench
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// blockToColfer converts a coin.SignedBlock to a ColferSignedBlock
+func blockToColfer(obj coin.SignedBlock) *ColferSignedBlock {
+	var out ColferSignedBlock
+
+	out.Sig = make([]byte, len(obj.Sig))
+	copy(out.Sig, obj.Sig[:])
+	out.Block = &ColferBlock{}
+	out.Block.Head = &ColferBlockHeader{}
+	out.Block.Head.Version = obj.Block.Head.Version
+	out.Block.Head.Time = obj.Block.Head.Time
+	out.Block.Head.BkSeq = obj.Block.Head.BkSeq
+	out.Block.Head.Fee = obj.Block.Head.Fee
+	out.Block.Head.PrevHash = make([]byte, len(obj.Block.Head.PrevHash))
+	copy(out.Block.Head.PrevHash, obj.Block.Head.PrevHash[:])
+	out.Block.Head.BodyHash = make([]byte, len(obj.Block.Head.BodyHash))
+	copy(out.Block.Head.BodyHash, obj.Block.Head.BodyHash[:])
+	out.Block.Head.UxHash = make([]byte, len(obj.Block.Head.UxHash))
+	copy(out.Block.Head.UxHash, obj.Block.Head.UxHash[:])
+	out.Block.Body = &ColferBlockBody{}
+	if len(obj.Block.Body.Transactions) != 0 {
+		out.Block.Body.Transactions = make([]*ColferTransaction, len(obj.Block.Body.Transactions))
+		for i1 := range obj.Block.Body.Transactions {
+			out.Block.Body.Transactions[i1] = &ColferTransaction{}
+			out.Block.Body.Transactions[i1].Length = obj.Block.Body.Transactions[i1].Length
+			out.Block.Body.Transactions[i1].Type = obj.Block.Body.Transactions[i1].Type
+			out.Block.Body.Transactions[i1].InnerHash = make([]byte, len(obj.Block.Body.Transactions[i1].InnerHash))
+			copy(out.Block.Body.Transactions[i1].InnerHash, obj.Block.Body.Transactions[i1].InnerHash[:])
+			if len(obj.Block.Body.Transactions[i1].Sigs) != 0 {
+				out.Block.Body.Transactions[i1].Sigs = make([][]byte, len(obj.Block.Body.Transactions[i1].Sigs))
+				for i2 := range obj.Block.Body.Transactions[i1].Sigs {
+					out.Block.Body.Transactions[i1].Sigs[i2] = make([]byte, len(obj.Block.Body.Transactions[i1].Sigs[i2]))
+					copy(out.Block.Body.Transactions[i1].Sigs[i2], obj.Block.Body.Transactions[i1].Sigs[i2][:])
+				}
+			}
+			if len(obj.Block.Body.Transactions[i1].In) != 0 {
+				out.Block.Body.Transactions[i1].In = make([][]byte, len(obj.Block.Body.Transactions[i1].In))
+				for i2 := range obj.Block.Body.Transactions[i1].In {
+					out.Block.Body.Transactions[i1].In[i2] = make([]byte, len(obj.Block.Body.Transactions[i1].In[i2]))
+					copy(out.Block.Body.Transactions[i1].In[i2], obj.Block.Body.Transactions[i1].In[i2][:])
+				}
+			}
+			if len(obj.Block.Body.Transactions[i1].Out) != 0 {
+				out.Block.Body.Transactions[i1].Out = make([]*ColferTransactionOutput, len(obj.Block.Body.Transactions[i1].Out))
+				for i2 := range obj.Block.Body.Transactions[i1].Out {
+					out.Block.Body.Transactions[i1].Out[i2] = &ColferTransactionOutput{}
+					out.Block.Body.Transactions[i1].Out[i2].Address = &ColferAddress{}
+					out.Block.Body.Transactions[i1].Out[i2].Address.Version = obj.Block.Body.Transactions[i1].Out[i2].Address.Version
+					out.Block.Body.Transactions[i1].Out[i2].Address.Key = make([]byte, len(obj.Block.Body.Transactions[i1].Out[i2].Address.Key))
+					copy(out.Block.Body.Transactions[i1].Out[i2].Address.Key, obj.Block.Body.Transactions[i1].Out[i2].Address.Key[:])
+					out.Block.Body.Transactions[i1].Out[i2].Coins = obj.Block.Body.Transactions[i1].Out[i2].Coins
+					out.Block.Body.Transactions[i1].Out[i2].Hours = obj.Block.Body.Transactions[i1].Out[i2].Hours
+				}
+			}
+		}
+	}
+	return &out
+}
+
+// colferToBlock converts a ColferSignedBlock to a coin.SignedBlock
+func colferToBlock(obj *ColferSignedBlock) (coin.SignedBlock, error) {
+	var out coin.SignedBlock
+
+	if obj.Block != nil {
+		if obj.Block.Head != nil {
+			out.Block.Head.Version = obj.Block.Head.Version
+			out.Block.Head.Time = obj.Block.Head.Time
+			out.Block.Head.BkSeq = obj.Block.Head.BkSeq
+			out.Block.Head.Fee = obj.Block.Head.Fee
+			if len(obj.Block.Head.PrevHash) != len(out.Block.Head.PrevHash) {
+				return coin.SignedBlock{}, errors.New("Block.Head.PrevHash: invalid length")
+			}
+			copy(out.Block.Head.PrevHash[:], obj.Block.Head.PrevHash)
+			if len(obj.Block.Head.BodyHash) != len(out.Block.Head.BodyHash) {
+				return coin.SignedBlock{}, errors.New("Block.Head.BodyHash: invalid length")
+			}
+			copy(out.Block.Head.BodyHash[:], obj.Block.Head.BodyHash)
+			if len(obj.Block.Head.UxHash) != len(out.Block.Head.UxHash) {
+				return coin.SignedBlock{}, errors.New("Block.Head.UxHash: invalid length")
+			}
+			copy(out.Block.Head.UxHash[:], obj.Block.Head.UxHash)
+		}
+		if obj.Block.Body != nil {
+			if len(obj.Block.Body.Transactions) != 0 {
+				out.Block.Body.Transactions = make(coin.Transactions, len(obj.Block.Body.Transactions))
+				for i1 := range obj.Block.Body.Transactions {
+					if obj.Block.Body.Transactions[i1] != nil {
+						out.Block.Body.Transactions[i1].Length = obj.Block.Body.Transactions[i1].Length
+						out.Block.Body.Transactions[i1].Type = obj.Block.Body.Transactions[i1].Type
+						if len(obj.Block.Body.Transactions[i1].InnerHash) != len(out.Block.Body.Transactions[i1].InnerHash) {
+							return coin.SignedBlock{}, errors.New("Block.Body.Transactions.InnerHash: invalid length")
+						}
+						copy(out.Block.Body.Transactions[i1].InnerHash[:], obj.Block.Body.Transactions[i1].InnerHash)
+						if len(obj.Block.Body.Transactions[i1].Sigs) != 0 {
+							out.Block.Body.Transactions[i1].Sigs = make([]cipher.Sig, len(obj.Block.Body.Transactions[i1].Sigs))
+							for i2 := range obj.Block.Body.Transactions[i1].Sigs {
+								if len(obj.Block.Body.Transactions[i1].Sigs[i2]) != len(out.Block.Body.Transactions[i1].Sigs[i2]) {
+									return coin.SignedBlock{}, errors.New("Block.Body.Transactions.Sigs: invalid length")
+								}
+								copy(out.Block.Body.Transactions[i1].Sigs[i2][:], obj.Block.Body.Transactions[i1].Sigs[i2])
+							}
+						}
+						if len(obj.Block.Body.Transactions[i1].In) != 0 {
+							out.Block.Body.Transactions[i1].In = make([]cipher.SHA256, len(obj.Block.Body.Transactions[i1].In))
+							for i2 := range obj.Block.Body.Transactions[i1].In {
+								if len(obj.Block.Body.Transactions[i1].In[i2]) != len(out.Block.Body.Transactions[i1].In[i2]) {
+									return coin.SignedBlock{}, errors.New("Block.Body.Transactions.In: invalid length")
+								}
+								copy(out.Block.Body.Transactions[i1].In[i2][:], obj.Block.Body.Transactions[i1].In[i2])
+							}
+						}
+						if len(obj.Block.Body.Transactions[i1].Out) != 0 {
+							out.Block.Body.Transactions[i1].Out = make([]coin.TransactionOutput, len(obj.Block.Body.Transactions[i1].Out))
+							for i2 := range obj.Block.Body.Transactions[i1].Out {
+								if obj.Block.Body.Transactions[i1].Out[i2] != nil {
+									if obj.Block.Body.Transactions[i1].Out[i2].Address != nil {
+										out.Block.Body.Transactions[i1].Out[i2].Address.Version = obj.Block.Body.Transactions[i1].Out[i2].Address.Version
+										if len(obj.Block.Body.Transactions[i1].Out[i2].Address.Key) != len(out.Block.Body.Transactions[i1].Out[i2].Address.Key) {
+											return coin.SignedBlock{}, errors.New("Block.Body.Transactions.Out.Address.Key: invalid length")
+										}
+										copy(out.Block.Body.Transactions[i1].Out[i2].Address.Key[:], obj.Block.Body.Transactions[i1].Out[i2].Address.Key)
+									}
+									out.Block.Body.Transactions[i1].Out[i2].Coins = obj.Block.Body.Transactions[i1].Out[i2].Coins
+									out.Block.Body.Transactions[i1].Out[i2].Hours = obj.Block.Body.Transactions[i1].Out[i2].Hours
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(obj.Sig) != len(out.Sig) {
+		return coin.SignedBlock{}, errors.New("Sig: invalid length")
+	}
+	copy(out.Sig[:], obj.Sig)
+	return out, nil
+}
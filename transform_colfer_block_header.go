@@ -0,0 +1,48 @@
+// Code generated by github.com/gz-c/skycoin-serialization-benchmarks/cmd/transformgen. DO NOT EDIT.
+package serializebench
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// headerToColfer converts a coin.BlockHeader to a ColferBlockHeader
+func headerToColfer(obj coin.BlockHeader) *ColferBlockHeader {
+	var out ColferBlockHeader
+
+	out.Version = obj.Version
+	out.Time = obj.Time
+	out.BkSeq = obj.BkSeq
+	out.Fee = obj.Fee
+	out.PrevHash = make([]byte, len(obj.PrevHash))
+	copy(out.PrevHash, obj.PrevHash[:])
+	out.BodyHash = make([]byte, len(obj.BodyHash))
+	copy(out.BodyHash, obj.BodyHash[:])
+	out.UxHash = make([]byte, len(obj.UxHash))
+	copy(out.UxHash, obj.UxHash[:])
+	return &out
+}
+
+// colferToHeader converts a ColferBlockHeader to a coin.BlockHeader
+func colferToHeader(obj *ColferBlockHeader) (coin.BlockHeader, error) {
+	var out coin.BlockHeader
+
+	out.Version = obj.Version
+	out.Time = obj.Time
+	out.BkSeq = obj.BkSeq
+	out.Fee = obj.Fee
+	if len(obj.PrevHash) != len(out.PrevHash) {
+		return coin.BlockHeader{}, errors.New("PrevHash: invalid length")
+	}
+	copy(out.PrevHash[:], obj.PrevHash)
+	if len(obj.BodyHash) != len(out.BodyHash) {
+		return coin.BlockHeader{}, errors.New("BodyHash: invalid length")
+	}
+	copy(out.BodyHash[:], obj.BodyHash)
+	if len(obj.UxHash) != len(out.UxHash) {
+		return coin.BlockHeader{}, errors.New("UxHash: invalid length")
+	}
+	copy(out.UxHash[:], obj.UxHash)
+	return out, nil
+}
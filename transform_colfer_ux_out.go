@@ -0,0 +1,51 @@
+// Code generated by github.com/gz-c/skycoin-serialization-benchmarks/cmd/transformgen. DO NOT EDIT.
+package serializebench
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// uxOutToColfer converts a coin.UxOut to a ColferUxOut
+func uxOutToColfer(obj coin.UxOut) *ColferUxOut {
+	var out ColferUxOut
+
+	out.Head = &ColferUxHead{}
+	*out.Head = ColferUxHead(obj.Head)
+	out.Body = &ColferUxBody{}
+	out.Body.SrcTransaction = make([]byte, len(obj.Body.SrcTransaction))
+	copy(out.Body.SrcTransaction, obj.Body.SrcTransaction[:])
+	out.Body.Address = &ColferAddress{}
+	out.Body.Address.Version = obj.Body.Address.Version
+	out.Body.Address.Key = make([]byte, len(obj.Body.Address.Key))
+	copy(out.Body.Address.Key, obj.Body.Address.Key[:])
+	out.Body.Coins = obj.Body.Coins
+	out.Body.Hours = obj.Body.Hours
+	return &out
+}
+
+// colferToUxOut converts a ColferUxOut to a coin.UxOut
+func colferToUxOut(obj *ColferUxOut) (coin.UxOut, error) {
+	var out coin.UxOut
+
+	if obj.Head != nil {
+		out.Head = coin.UxHead(*obj.Head)
+	}
+	if obj.Body != nil {
+		if len(obj.Body.SrcTransaction) != len(out.Body.SrcTransaction) {
+			return coin.UxOut{}, errors.New("Body.SrcTransaction: invalid length")
+		}
+		copy(out.Body.SrcTransaction[:], obj.Body.SrcTransaction)
+		if obj.Body.Address != nil {
+			out.Body.Address.Version = obj.Body.Address.Version
+			if len(obj.Body.Address.Key) != len(out.Body.Address.Key) {
+				return coin.UxOut{}, errors.New("Body.Address.Key: invalid length")
+			}
+			copy(out.Body.Address.Key[:], obj.Body.Address.Key)
+		}
+		out.Body.Coins = obj.Body.Coins
+		out.Body.Hours = obj.Body.Hours
+	}
+	return out, nil
+}
@@ -0,0 +1,95 @@
+// Code generated by github.com/gz-c/skycoin-serialization-benchmarks/cmd/transformgen. DO NOT EDIT.
+package serializebench
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// blockToGencodeVarint converts a coin.SignedBlock to a GencodeVarintSignedBlock
+func blockToGencodeVarint(obj coin.SignedBlock) *GencodeVarintSignedBlock {
+	var out GencodeVarintSignedBlock
+
+	out.Sig = obj.Sig
+	out.Block.Head.Version = obj.Block.Head.Version
+	out.Block.Head.Time = obj.Block.Head.Time
+	out.Block.Head.BkSeq = obj.Block.Head.BkSeq
+	out.Block.Head.Fee = obj.Block.Head.Fee
+	out.Block.Head.PrevHash = obj.Block.Head.PrevHash
+	out.Block.Head.BodyHash = obj.Block.Head.BodyHash
+	out.Block.Head.UxHash = obj.Block.Head.UxHash
+	if len(obj.Block.Body.Transactions) != 0 {
+		out.Block.Body.Transactions = make([]GencodeVarintTransaction, len(obj.Block.Body.Transactions))
+		for i1 := range obj.Block.Body.Transactions {
+			out.Block.Body.Transactions[i1].Length = obj.Block.Body.Transactions[i1].Length
+			out.Block.Body.Transactions[i1].Type = obj.Block.Body.Transactions[i1].Type
+			out.Block.Body.Transactions[i1].InnerHash = obj.Block.Body.Transactions[i1].InnerHash
+			if len(obj.Block.Body.Transactions[i1].Sigs) != 0 {
+				out.Block.Body.Transactions[i1].Sigs = make([][65]byte, len(obj.Block.Body.Transactions[i1].Sigs))
+				for i2 := range obj.Block.Body.Transactions[i1].Sigs {
+					out.Block.Body.Transactions[i1].Sigs[i2] = obj.Block.Body.Transactions[i1].Sigs[i2]
+				}
+			}
+			if len(obj.Block.Body.Transactions[i1].In) != 0 {
+				out.Block.Body.Transactions[i1].In = make([][32]byte, len(obj.Block.Body.Transactions[i1].In))
+				for i2 := range obj.Block.Body.Transactions[i1].In {
+					out.Block.Body.Transactions[i1].In[i2] = obj.Block.Body.Transactions[i1].In[i2]
+				}
+			}
+			if len(obj.Block.Body.Transactions[i1].Out) != 0 {
+				out.Block.Body.Transactions[i1].Out = make([]GencodeVarintTransactionOutput, len(obj.Block.Body.Transactions[i1].Out))
+				for i2 := range obj.Block.Body.Transactions[i1].Out {
+					out.Block.Body.Transactions[i1].Out[i2].Address.Version = obj.Block.Body.Transactions[i1].Out[i2].Address.Version
+					out.Block.Body.Transactions[i1].Out[i2].Address.Key = obj.Block.Body.Transactions[i1].Out[i2].Address.Key
+					out.Block.Body.Transactions[i1].Out[i2].Coins = obj.Block.Body.Transactions[i1].Out[i2].Coins
+					out.Block.Body.Transactions[i1].Out[i2].Hours = obj.Block.Body.Transactions[i1].Out[i2].Hours
+				}
+			}
+		}
+	}
+	return &out
+}
+
+// gencodeVarintToBlock converts a GencodeVarintSignedBlock to a coin.SignedBlock
+func gencodeVarintToBlock(obj *GencodeVarintSignedBlock) coin.SignedBlock {
+	var out coin.SignedBlock
+
+	out.Block.Head.Version = obj.Block.Head.Version
+	out.Block.Head.Time = obj.Block.Head.Time
+	out.Block.Head.BkSeq = obj.Block.Head.BkSeq
+	out.Block.Head.Fee = obj.Block.Head.Fee
+	out.Block.Head.PrevHash = obj.Block.Head.PrevHash
+	out.Block.Head.BodyHash = obj.Block.Head.BodyHash
+	out.Block.Head.UxHash = obj.Block.Head.UxHash
+	if len(obj.Block.Body.Transactions) != 0 {
+		out.Block.Body.Transactions = make(coin.Transactions, len(obj.Block.Body.Transactions))
+		for i1 := range obj.Block.Body.Transactions {
+			out.Block.Body.Transactions[i1].Length = obj.Block.Body.Transactions[i1].Length
+			out.Block.Body.Transactions[i1].Type = obj.Block.Body.Transactions[i1].Type
+			out.Block.Body.Transactions[i1].InnerHash = obj.Block.Body.Transactions[i1].InnerHash
+			if len(obj.Block.Body.Transactions[i1].Sigs) != 0 {
+				out.Block.Body.Transactions[i1].Sigs = make([]cipher.Sig, len(obj.Block.Body.Transactions[i1].Sigs))
+				for i2 := range obj.Block.Body.Transactions[i1].Sigs {
+					out.Block.Body.Transactions[i1].Sigs[i2] = obj.Block.Body.Transactions[i1].Sigs[i2]
+				}
+			}
+			if len(obj.Block.Body.Transactions[i1].In) != 0 {
+				out.Block.Body.Transactions[i1].In = make([]cipher.SHA256, len(obj.Block.Body.Transactions[i1].In))
+				for i2 := range obj.Block.Body.Transactions[i1].In {
+					out.Block.Body.Transactions[i1].In[i2] = obj.Block.Body.Transactions[i1].In[i2]
+				}
+			}
+			if len(obj.Block.Body.Transactions[i1].Out) != 0 {
+				out.Block.Body.Transactions[i1].Out = make([]coin.TransactionOutput, len(obj.Block.Body.Transactions[i1].Out))
+				for i2 := range obj.Block.Body.Transactions[i1].Out {
+					out.Block.Body.Transactions[i1].Out[i2].Address.Version = obj.Block.Body.Transactions[i1].Out[i2].Address.Version
+					out.Block.Body.Transactions[i1].Out[i2].Address.Key = obj.Block.Body.Transactions[i1].Out[i2].Address.Key
+					out.Block.Body.Transactions[i1].Out[i2].Coins = obj.Block.Body.Transactions[i1].Out[i2].Coins
+					out.Block.Body.Transactions[i1].Out[i2].Hours = obj.Block.Body.Transactions[i1].Out[i2].Hours
+				}
+			}
+		}
+	}
+	out.Sig = obj.Sig
+	return out
+}
@@ -0,0 +1,32 @@
+// Code generated by github.com/gz-c/skycoin-serialization-benchmarks/cmd/transformgen. DO NOT EDIT.
+package serializebench
+
+import (
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// uxOutToGencodeVarint converts a coin.UxOut to a GencodeVarintUxOut
+func uxOutToGencodeVarint(obj coin.UxOut) *GencodeVarintUxOut {
+	var out GencodeVarintUxOut
+
+	out.Head = GencodeVarintUxHead(obj.Head)
+	out.Body.SrcTransaction = obj.Body.SrcTransaction
+	out.Body.Address.Version = obj.Body.Address.Version
+	out.Body.Address.Key = obj.Body.Address.Key
+	out.Body.Coins = obj.Body.Coins
+	out.Body.Hours = obj.Body.Hours
+	return &out
+}
+
+// gencodeVarintToUxOut converts a GencodeVarintUxOut to a coin.UxOut
+func gencodeVarintToUxOut(obj *GencodeVarintUxOut) coin.UxOut {
+	var out coin.UxOut
+
+	out.Head = coin.UxHead(obj.Head)
+	out.Body.SrcTransaction = obj.Body.SrcTransaction
+	out.Body.Address.Version = obj.Body.Address.Version
+	out.Body.Address.Key = obj.Body.Address.Key
+	out.Body.Coins = obj.Body.Coins
+	out.Body.Hours = obj.Body.Hours
+	return out
+}
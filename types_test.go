@@ -0,0 +1,530 @@
+package serializebench
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	xdr "github.com/davecgh/go-xdr/xdr2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/niubaoshu/gotiny"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// getTransaction returns the first transaction of the test block, as announced on its own to peers
+func getTransaction() coin.Transaction {
+	return getBlock().Block.Body.Transactions[0]
+}
+
+// getBlockHeader returns the header of the test block
+func getBlockHeader() coin.BlockHeader {
+	return getBlock().Block.Head
+}
+
+// getUxOut returns an unspent output of the test block, as stored in the unspent pool
+func getUxOut() coin.UxOut {
+	block := getBlock()
+	txn := block.Block.Body.Transactions[0]
+	return coin.UxOut{
+		Head: coin.UxHead{
+			Time:  block.Block.Head.Time,
+			BkSeq: block.Block.Head.BkSeq,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: cipher.MustSHA256FromHex("a6c8ed5d0ab9bd1a3a2c1bd3e5a2f6b0e4d0a6a0a2f5b3e3b0f3d7c1e9d5b2a4"),
+			Address:        txn.Out[0].Address,
+			Coins:          txn.Out[0].Coins,
+			Hours:          txn.Out[0].Hours,
+		},
+	}
+}
+
+// typeCodec encodes and decodes a single object of one of the types in typeFixtures
+type typeCodec struct {
+	name      string
+	marshal   func() ([]byte, error)
+	unmarshal func(data []byte) (interface{}, error)
+	// convert converts the result of unmarshal to the fixture's type. It is set for the notransform codecs,
+	// which marshal an object converted in advance and unmarshal into the generated struct, so that the
+	// benchmarks time the serializer alone, as the ...NoTransform block benchmarks do.
+	convert func(result interface{}) (interface{}, error)
+}
+
+// decode unmarshals data and converts the result to the fixture's type
+func (c typeCodec) decode(data []byte) (interface{}, error) {
+	result, err := c.unmarshal(data)
+	if err != nil || c.convert == nil {
+		return result, err
+	}
+	return c.convert(result)
+}
+
+// typeFixture is an object of a Skycoin type other than SignedBlock, with the codecs which support its type
+type typeFixture struct {
+	name   string
+	obj    interface{}
+	codecs []typeCodec
+}
+
+// reflectCodecs returns the codecs which encode any type by reflection
+func reflectCodecs(obj interface{}) []typeCodec {
+	t := reflect.TypeOf(obj)
+	tinyEnc := gotiny.NewEncoder(obj)
+	tinyDec := gotiny.NewDecoder(obj)
+
+	decode := func(f func(data []byte, ptr interface{}) error) func([]byte) (interface{}, error) {
+		return func(data []byte) (interface{}, error) {
+			ptr := reflect.New(t)
+			if err := f(data, ptr.Interface()); err != nil {
+				return nil, err
+			}
+			return ptr.Elem().Interface(), nil
+		}
+	}
+
+	return []typeCodec{
+		{
+			name:      "sky",
+			marshal:   func() ([]byte, error) { return encoder.Serialize(obj), nil },
+			unmarshal: decode(encoder.DeserializeRaw),
+		},
+		{
+			name: "xdr2",
+			marshal: func() ([]byte, error) {
+				var w bytes.Buffer
+				_, err := xdr.Marshal(&w, obj)
+				return w.Bytes(), err
+			},
+			unmarshal: decode(func(data []byte, ptr interface{}) error {
+				_, err := xdr.Unmarshal(bytes.NewReader(data), ptr)
+				return err
+			}),
+		},
+		{
+			name:      "json",
+			marshal:   func() ([]byte, error) { return json.Marshal(obj) },
+			unmarshal: decode(json.Unmarshal),
+		},
+		{
+			name:    "gotiny",
+			marshal: func() ([]byte, error) { return tinyEnc.Encode(obj), nil },
+			unmarshal: decode(func(data []byte, ptr interface{}) error {
+				if n := tinyDec.Decode(data, ptr); n != len(data) {
+					return fmt.Errorf("gotiny read %d bytes of %d", n, len(data))
+				}
+				return nil
+			}),
+		},
+	}
+}
+
+// checkRead returns an error if a generated decoder did not read the whole buffer
+func checkRead(n, size int, err error) error {
+	if err == nil && n != size {
+		return fmt.Errorf("read %d bytes of %d", n, size)
+	}
+	return err
+}
+
+func transactionFixture() typeFixture {
+	txn := getTransaction()
+	// Converted in advance for the notransform codecs
+	colferTransaction := transactionToColfer(txn)
+	gencodeTransaction := transactionToGencode(txn)
+	gencodeVarintTransaction := transactionToGencodeVarint(txn)
+
+	return typeFixture{
+		name: "Transaction",
+		obj:  txn,
+		codecs: append(reflectCodecs(txn),
+			typeCodec{
+				name: "skyenc",
+				marshal: func() ([]byte, error) {
+					buf := make([]byte, EncodeSizeTransaction(&txn))
+					return buf, EncodeTransaction(buf, &txn)
+				},
+				unmarshal: func(data []byte) (interface{}, error) {
+					var result coin.Transaction
+					n, err := DecodeTransaction(data, &result)
+					return result, checkRead(n, len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "colfer",
+				marshal: func() ([]byte, error) { return transactionToColfer(txn).MarshalBinary() },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var colferResult ColferTransaction
+					if err := colferResult.UnmarshalBinary(data); err != nil {
+						return nil, err
+					}
+					return colferToTransaction(&colferResult)
+				},
+			},
+			typeCodec{
+				name:    "gencode",
+				marshal: func() ([]byte, error) { return transactionToGencode(txn).Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeTransaction
+					n, err := gencodeResult.Unmarshal(data)
+					return gencodeToTransaction(&gencodeResult), checkRead(int(n), len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "gencodevar",
+				marshal: func() ([]byte, error) { return transactionToGencodeVarint(txn).Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeVarintTransaction
+					n, err := gencodeResult.Unmarshal(data)
+					return gencodeVarintToTransaction(&gencodeResult), checkRead(int(n), len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "colfer-notransform",
+				marshal: func() ([]byte, error) { return colferTransaction.MarshalBinary() },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var colferResult ColferTransaction
+					err := colferResult.UnmarshalBinary(data)
+					return &colferResult, err
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return colferToTransaction(result.(*ColferTransaction))
+				},
+			},
+			typeCodec{
+				name:    "gencode-notransform",
+				marshal: func() ([]byte, error) { return gencodeTransaction.Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeTransaction
+					n, err := gencodeResult.Unmarshal(data)
+					return &gencodeResult, checkRead(int(n), len(data), err)
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return gencodeToTransaction(result.(*GencodeTransaction)), nil
+				},
+			},
+			typeCodec{
+				name:    "gencodevar-notransform",
+				marshal: func() ([]byte, error) { return gencodeVarintTransaction.Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeVarintTransaction
+					n, err := gencodeResult.Unmarshal(data)
+					return &gencodeResult, checkRead(int(n), len(data), err)
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return gencodeVarintToTransaction(result.(*GencodeVarintTransaction)), nil
+				},
+			},
+		),
+	}
+}
+
+func blockHeaderFixture() typeFixture {
+	header := getBlockHeader()
+	// Converted in advance for the notransform codecs
+	colferBlockHeader := headerToColfer(header)
+	gencodeBlockHeader := headerToGencode(header)
+	gencodeVarintBlockHeader := headerToGencodeVarint(header)
+
+	return typeFixture{
+		name: "BlockHeader",
+		obj:  header,
+		codecs: append(reflectCodecs(header),
+			typeCodec{
+				name: "skyenc",
+				marshal: func() ([]byte, error) {
+					buf := make([]byte, EncodeSizeBlockHeader(&header))
+					return buf, EncodeBlockHeader(buf, &header)
+				},
+				unmarshal: func(data []byte) (interface{}, error) {
+					var result coin.BlockHeader
+					n, err := DecodeBlockHeader(data, &result)
+					return result, checkRead(n, len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "colfer",
+				marshal: func() ([]byte, error) { return headerToColfer(header).MarshalBinary() },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var colferResult ColferBlockHeader
+					if err := colferResult.UnmarshalBinary(data); err != nil {
+						return nil, err
+					}
+					return colferToHeader(&colferResult)
+				},
+			},
+			typeCodec{
+				name:    "gencode",
+				marshal: func() ([]byte, error) { return headerToGencode(header).Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeBlockHeader
+					n, err := gencodeResult.Unmarshal(data)
+					return gencodeToHeader(&gencodeResult), checkRead(int(n), len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "gencodevar",
+				marshal: func() ([]byte, error) { return headerToGencodeVarint(header).Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeVarintBlockHeader
+					n, err := gencodeResult.Unmarshal(data)
+					return gencodeVarintToHeader(&gencodeResult), checkRead(int(n), len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "colfer-notransform",
+				marshal: func() ([]byte, error) { return colferBlockHeader.MarshalBinary() },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var colferResult ColferBlockHeader
+					err := colferResult.UnmarshalBinary(data)
+					return &colferResult, err
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return colferToHeader(result.(*ColferBlockHeader))
+				},
+			},
+			typeCodec{
+				name:    "gencode-notransform",
+				marshal: func() ([]byte, error) { return gencodeBlockHeader.Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeBlockHeader
+					n, err := gencodeResult.Unmarshal(data)
+					return &gencodeResult, checkRead(int(n), len(data), err)
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return gencodeToHeader(result.(*GencodeBlockHeader)), nil
+				},
+			},
+			typeCodec{
+				name:    "gencodevar-notransform",
+				marshal: func() ([]byte, error) { return gencodeVarintBlockHeader.Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeVarintBlockHeader
+					n, err := gencodeResult.Unmarshal(data)
+					return &gencodeResult, checkRead(int(n), len(data), err)
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return gencodeVarintToHeader(result.(*GencodeVarintBlockHeader)), nil
+				},
+			},
+		),
+	}
+}
+
+func uxOutFixture() typeFixture {
+	ux := getUxOut()
+	// Converted in advance for the notransform codecs
+	colferUxOut := uxOutToColfer(ux)
+	gencodeUxOut := uxOutToGencode(ux)
+	gencodeVarintUxOut := uxOutToGencodeVarint(ux)
+
+	return typeFixture{
+		name: "UxOut",
+		obj:  ux,
+		codecs: append(reflectCodecs(ux),
+			typeCodec{
+				name: "skyenc",
+				marshal: func() ([]byte, error) {
+					buf := make([]byte, EncodeSizeUxOut(&ux))
+					return buf, EncodeUxOut(buf, &ux)
+				},
+				unmarshal: func(data []byte) (interface{}, error) {
+					var result coin.UxOut
+					n, err := DecodeUxOut(data, &result)
+					return result, checkRead(n, len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "colfer",
+				marshal: func() ([]byte, error) { return uxOutToColfer(ux).MarshalBinary() },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var colferResult ColferUxOut
+					if err := colferResult.UnmarshalBinary(data); err != nil {
+						return nil, err
+					}
+					return colferToUxOut(&colferResult)
+				},
+			},
+			typeCodec{
+				name:    "gencode",
+				marshal: func() ([]byte, error) { return uxOutToGencode(ux).Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeUxOut
+					n, err := gencodeResult.Unmarshal(data)
+					return gencodeToUxOut(&gencodeResult), checkRead(int(n), len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "gencodevar",
+				marshal: func() ([]byte, error) { return uxOutToGencodeVarint(ux).Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeVarintUxOut
+					n, err := gencodeResult.Unmarshal(data)
+					return gencodeVarintToUxOut(&gencodeResult), checkRead(int(n), len(data), err)
+				},
+			},
+			typeCodec{
+				name:    "colfer-notransform",
+				marshal: func() ([]byte, error) { return colferUxOut.MarshalBinary() },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var colferResult ColferUxOut
+					err := colferResult.UnmarshalBinary(data)
+					return &colferResult, err
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return colferToUxOut(result.(*ColferUxOut))
+				},
+			},
+			typeCodec{
+				name:    "gencode-notransform",
+				marshal: func() ([]byte, error) { return gencodeUxOut.Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeUxOut
+					n, err := gencodeResult.Unmarshal(data)
+					return &gencodeResult, checkRead(int(n), len(data), err)
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return gencodeToUxOut(result.(*GencodeUxOut)), nil
+				},
+			},
+			typeCodec{
+				name:    "gencodevar-notransform",
+				marshal: func() ([]byte, error) { return gencodeVarintUxOut.Marshal(nil) },
+				unmarshal: func(data []byte) (interface{}, error) {
+					var gencodeResult GencodeVarintUxOut
+					n, err := gencodeResult.Unmarshal(data)
+					return &gencodeResult, checkRead(int(n), len(data), err)
+				},
+				convert: func(result interface{}) (interface{}, error) {
+					return gencodeVarintToUxOut(result.(*GencodeVarintUxOut)), nil
+				},
+			},
+		),
+	}
+}
+
+func typeFixtures() []typeFixture {
+	return []typeFixture{
+		transactionFixture(),
+		blockHeaderFixture(),
+		uxOutFixture(),
+	}
+}
+
+func TestTypesRoundTrip(t *testing.T) {
+	for _, f := range typeFixtures() {
+		for _, c := range f.codecs {
+			t.Run(f.name+"/"+c.name, func(t *testing.T) {
+				data, err := c.marshal()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				result, err := c.decode(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(f.obj, result) {
+					t.Error(cmp.Diff(f.obj, result))
+				}
+			})
+		}
+	}
+}
+
+// TestTypesSameWire checks that the generated skyencoder code writes the same bytes as the reference serializer
+func TestTypesSameWire(t *testing.T) {
+	for _, f := range typeFixtures() {
+		encoded := make(map[string][]byte)
+		for _, c := range f.codecs {
+			data, err := c.marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded[c.name] = data
+		}
+
+		if !bytes.Equal(encoded["sky"], encoded["skyenc"]) {
+			t.Errorf("%s: skyencoder and sky encodings differ", f.name)
+		}
+	}
+}
+
+func TestMarshaledTypesLen(t *testing.T) {
+	for _, f := range typeFixtures() {
+		fmt.Printf("%s:\n", f.name)
+		for _, c := range f.codecs {
+			if c.convert != nil {
+				// Same bytes as the codec with the transform
+				continue
+			}
+			data, err := c.marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fmt.Printf("\t%-10s %4d bytes\n", c.name, len(data))
+		}
+	}
+}
+
+func BenchmarkMarshalTypes(b *testing.B) {
+	for _, f := range typeFixtures() {
+		for _, c := range f.codecs {
+			b.Run(f.name+"/"+c.name, func(b *testing.B) {
+				data, err := c.marshal()
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := c.marshal(); err != nil {
+						b.Fatal(err)
+					}
+				}
+
+				b.ReportMetric(float64(len(data)), "bytes")
+			})
+		}
+	}
+}
+
+func BenchmarkUnmarshalTypes(b *testing.B) {
+	for _, f := range typeFixtures() {
+		for _, c := range f.codecs {
+			b.Run(f.name+"/"+c.name, func(b *testing.B) {
+				data, err := c.marshal()
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					result, err := c.unmarshal(data)
+					if err != nil {
+						b.Fatal(err)
+					}
+
+					if validate {
+						if c.convert != nil {
+							if result, err = c.convert(result); err != nil {
+								b.Fatal(err)
+							}
+						}
+						if !cmp.Equal(f.obj, result) {
+							b.Fatalf("%s unmarshal result differs", c.name)
+						}
+					}
+				}
+
+				b.ReportMetric(float64(len(data)), "bytes")
+			})
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// VarintScheme is a variable-length encoding of a uint64
+type VarintScheme struct {
+	Name string
+	// Append appends the encoding of x to dst
+	Append func(dst []byte, x uint64) []byte
+	// Read decodes the value at the start of b and returns it with its size, or a size of 0 if b is truncated
+	// or the value overflows
+	Read func(b []byte) (uint64, int)
+	// Header is set if the encoding starts with a field header byte holding a flag of the scheme.
+	// Colfer writes that byte for every field anyway, so Size does not count it.
+	Header bool
+}
+
+// Size returns the number of bytes of the encoding of x
+func (s VarintScheme) Size(x uint64) int {
+	var buf [16]byte
+	n := len(s.Append(buf[:0], x))
+	if s.Header {
+		n--
+	}
+	return n
+}
+
+// VarintSchemes are the compared varint schemes
+var VarintSchemes = []VarintScheme{
+	{Name: "leb128", Append: appendUvarint, Read: readLEB128},
+	{Name: "zigzag", Append: appendZigzag, Read: readZigzag},
+	{Name: "prefix", Append: appendPrefixVarint, Read: readPrefixVarint},
+	{Name: "sqlite4", Append: appendSQLite4Varint, Read: readSQLite4Varint},
+	{Name: "colfer", Append: appendColferVarint, Read: readColferVarint, Header: true},
+}
+
+// readLEB128 reads a LEB128 varint of up to 10 bytes, as used by gencode varint and encoding/binary
+func readLEB128(b []byte) (uint64, int) {
+	x, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, 0
+	}
+	return x, n
+}
+
+// appendZigzag appends x as a signed integer, zigzag encoded so that small negative values stay small,
+// then LEB128 encoded. Zigzag moves the sign to the lowest bit, so unsigned values cost a bit more.
+func appendZigzag(dst []byte, x uint64) []byte {
+	return appendUvarint(dst, uint64(int64(x)<<1^int64(x)>>63))
+}
+
+func readZigzag(b []byte) (uint64, int) {
+	z, n := readLEB128(b)
+	return z>>1 ^ -(z & 1), n
+}
+
+// appendPrefixVarint appends x as a PrefixVarint: the number of leading one bits of the first byte is
+// the number of bytes which follow, so the size is known from the first byte without a loop.
+// A value of up to 7k bits takes k bytes, big-endian after the prefix; above 56 bits, 0xff is followed by 8 bytes.
+func appendPrefixVarint(dst []byte, x uint64) []byte {
+	k := 1
+	for k < 9 && x >= 1<<(7*uint(k)) {
+		k++
+	}
+	if k == 9 {
+		dst = append(dst, 0xff)
+		return appendUint64BE(dst, x)
+	}
+
+	prefix := byte(0xff << uint(9-k))
+	dst = append(dst, prefix|byte(x>>(8*uint(k-1))))
+	for i := k - 2; i >= 0; i-- {
+		dst = append(dst, byte(x>>(8*uint(i))))
+	}
+	return dst
+}
+
+func readPrefixVarint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	k := bits.LeadingZeros8(^b[0]) + 1
+	if len(b) < k {
+		return 0, 0
+	}
+	if k == 9 {
+		return binary.BigEndian.Uint64(b[1:9]), 9
+	}
+
+	x := uint64(b[0] & (0xff >> uint(k)))
+	for _, c := range b[1:k] {
+		x = x<<8 | uint64(c)
+	}
+	return x, k
+}
+
+// appendSQLite4Varint appends x as a SQLite4 varint, whose first byte selects the size: values up to 240 take
+// 1 byte, up to 2287 2 bytes and up to 67823 3 bytes; larger values follow a 250-255 byte as 3-8 big-endian bytes.
+// See https://sqlite.org/src4/doc/trunk/www/varint.wiki
+func appendSQLite4Varint(dst []byte, x uint64) []byte {
+	switch {
+	case x <= 240:
+		return append(dst, byte(x))
+	case x <= 2287:
+		y := x - 240
+		return append(dst, byte(y>>8+241), byte(y))
+	case x <= 67823:
+		y := x - 2288
+		return append(dst, 249, byte(y>>8), byte(y))
+	}
+
+	n := (bits.Len64(x) + 7) / 8
+	if n < 3 {
+		n = 3
+	}
+	dst = append(dst, byte(247+n))
+	for i := n - 1; i >= 0; i-- {
+		dst = append(dst, byte(x>>(8*uint(i))))
+	}
+	return dst
+}
+
+func readSQLite4Varint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	a0 := b[0]
+	switch {
+	case a0 <= 240:
+		return uint64(a0), 1
+	case a0 <= 248:
+		if len(b) < 2 {
+			return 0, 0
+		}
+		return 240 + 256*uint64(a0-241) + uint64(b[1]), 2
+	case a0 == 249:
+		if len(b) < 3 {
+			return 0, 0
+		}
+		return 2288 + 256*uint64(b[1]) + uint64(b[2]), 3
+	}
+
+	n := int(a0) - 247
+	if len(b) < 1+n {
+		return 0, 0
+	}
+	var x uint64
+	for _, c := range b[1 : 1+n] {
+		x = x<<8 | uint64(c)
+	}
+	return x, 1 + n
+}
+
+// colferFixedMin is the smallest integer Colfer writes fixed-width, as its varint would take 8 bytes or more
+const colferFixedMin = 1 << 49
+
+// appendColferVarint appends x as Colfer does: a field header, with the 0x80 flag set if x is written as
+// 8 big-endian bytes rather than a LEB128 varint, which is the case from 1<<49, where the varint takes 8 bytes.
+func appendColferVarint(dst []byte, x uint64) []byte {
+	if x >= colferFixedMin {
+		dst = append(dst, 0x80)
+		return appendUint64BE(dst, x)
+	}
+	return appendUvarint(append(dst, 0), x)
+}
+
+func readColferVarint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	if b[0]&0x80 != 0 {
+		if len(b) < 9 {
+			return 0, 0
+		}
+		return binary.BigEndian.Uint64(b[1:9]), 9
+	}
+	x, n := readLEB128(b[1:])
+	if n == 0 {
+		return 0, 0
+	}
+	return x, 1 + n
+}
+
+func appendUint64BE(dst []byte, x uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], x)
+	return append(dst, buf[:]...)
+}
+
+// blockIntegers returns the values of the integer fields of blocks, keyed by the paths in intFields
+func blockIntegers(blocks []coin.SignedBlock) map[string][]uint64 {
+	values := make(map[string][]uint64)
+	add := func(field string, x uint64) {
+		values[field] = append(values[field], x)
+	}
+
+	for _, b := range blocks {
+		head := b.Block.Head
+		add("Block.Head.Version", uint64(head.Version))
+		add("Block.Head.Time", head.Time)
+		add("Block.Head.BkSeq", head.BkSeq)
+		add("Block.Head.Fee", head.Fee)
+
+		for _, txn := range b.Block.Body.Transactions {
+			add("Block.Body.Transactions[].Length", uint64(txn.Length))
+			add("Block.Body.Transactions[].Type", uint64(txn.Type))
+			for _, out := range txn.Out {
+				add("Block.Body.Transactions[].Out[].Address.Version", uint64(out.Address.Version))
+				add("Block.Body.Transactions[].Out[].Coins", out.Coins)
+				add("Block.Body.Transactions[].Out[].Hours", out.Hours)
+			}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,151 @@
+package serializebench
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// varintEdges are the boundaries between the sizes of the varint schemes
+var varintEdges = []uint64{
+	0, 1, 127, 128, 240, 241, 2287, 2288, 16383, 16384, 67823, 67824,
+	1<<24 - 1, 1 << 24, 1<<32 - 1, 1 << 32, 1<<48 - 1, 1 << 48,
+	colferFixedMin - 1, colferFixedMin, 1<<56 - 1, 1 << 56, 1<<63 - 1, 1 << 63, math.MaxUint64,
+}
+
+func TestVarintSchemes(t *testing.T) {
+	for _, s := range VarintSchemes {
+		t.Run(s.Name, func(t *testing.T) {
+			for _, x := range varintEdges {
+				buf := s.Append(nil, x)
+				y, n := s.Read(buf)
+				if y != x || n != len(buf) {
+					t.Errorf("%d: read %d from %d of %d bytes %x", x, y, n, len(buf), buf)
+				}
+
+				// Trailing bytes are not read
+				if _, n := s.Read(append(buf, 0xff)); n != len(buf) {
+					t.Errorf("%d: read %d bytes followed by a trailing byte, expected %d", x, n, len(buf))
+				}
+
+				for i := 0; i < len(buf); i++ {
+					if _, n := s.Read(buf[:i]); n != 0 {
+						t.Errorf("%d: read %d bytes from %d of %d", x, n, i, len(buf))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestVarintSizes(t *testing.T) {
+	cases := []struct {
+		x     uint64
+		sizes map[string]int
+	}{
+		{240, map[string]int{"leb128": 2, "zigzag": 2, "prefix": 2, "sqlite4": 1, "colfer": 2}},
+		{2287, map[string]int{"leb128": 2, "zigzag": 2, "prefix": 2, "sqlite4": 2, "colfer": 2}},
+		{1<<49 - 1, map[string]int{"leb128": 7, "zigzag": 8, "prefix": 7, "sqlite4": 8, "colfer": 7}},
+		{1 << 49, map[string]int{"leb128": 8, "zigzag": 8, "prefix": 8, "sqlite4": 8, "colfer": 8}},
+		{math.MaxUint64, map[string]int{"leb128": 10, "zigzag": 1, "prefix": 9, "sqlite4": 9, "colfer": 8}},
+	}
+
+	for _, tc := range cases {
+		for _, s := range VarintSchemes {
+			if n := s.Size(tc.x); n != tc.sizes[s.Name] {
+				t.Errorf("%s: %d takes %d bytes, expected %d", s.Name, tc.x, n, tc.sizes[s.Name])
+			}
+		}
+	}
+}
+
+// TestVarintFieldSizes prints the mean size of the integer fields of the generated chain and of blocks
+// drawn from SkycoinDistribution in each varint scheme
+func TestVarintFieldSizes(t *testing.T) {
+	sources := []struct {
+		name   string
+		values map[string][]uint64
+	}{
+		{"generated chain", blockIntegers(GenerateChain(storeBlocks, 1))},
+		{"SkycoinDistribution", blockIntegers(DrawBlocks(storeBlocks, 1, SkycoinDistribution))},
+	}
+
+	for _, src := range sources {
+		fmt.Printf("\n%-48s", src.name)
+		for _, s := range VarintSchemes {
+			fmt.Printf(" %8s", s.Name)
+		}
+		fmt.Println()
+
+		totals := make([]int, len(VarintSchemes))
+		for _, field := range intFields {
+			fmt.Printf("%-48s", field)
+			for i, s := range VarintSchemes {
+				var size int
+				for _, x := range src.values[field] {
+					size += s.Size(x)
+				}
+				totals[i] += size
+				fmt.Printf(" %8.2f", float64(size)/float64(len(src.values[field])))
+			}
+			fmt.Println()
+		}
+
+		fmt.Printf("%-48s", "total bytes")
+		for _, n := range totals {
+			fmt.Printf(" %8d", n)
+		}
+		fmt.Println()
+	}
+}
+
+// BenchmarkVarint encodes and decodes all the integer fields of blocks drawn from SkycoinDistribution
+// in each varint scheme
+func BenchmarkVarint(b *testing.B) {
+	fields := blockIntegers(DrawBlocks(storeBlocks, 1, SkycoinDistribution))
+	var values []uint64
+	for _, field := range intFields {
+		values = append(values, fields[field]...)
+	}
+
+	for _, s := range VarintSchemes {
+		var data []byte
+		var size int
+		for _, x := range values {
+			data = s.Append(data, x)
+			size += s.Size(x)
+		}
+
+		b.Run(s.Name+"/encode", func(b *testing.B) {
+			buf := make([]byte, 0, len(data))
+			b.SetBytes(int64(size))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf = buf[:0]
+				for _, x := range values {
+					buf = s.Append(buf, x)
+				}
+			}
+			b.ReportMetric(float64(size)/float64(len(values)), "bytes/value")
+		})
+
+		b.Run(s.Name+"/decode", func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf := data
+				for j := range values {
+					x, n := s.Read(buf)
+					if n == 0 {
+						b.Fatal("truncated")
+					}
+					if validate && x != values[j] {
+						b.Fatalf("%s decoded %d, expected %d", s.Name, x, values[j])
+					}
+					buf = buf[n:]
+				}
+			}
+			b.ReportMetric(float64(size)/float64(len(values)), "bytes/value")
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// A block view reads the fields of an encoded coin.SignedBlock in place, without decoding it.
+// Views are supported for the layouts with fixed-width integers: the Skycoin encoder format and the fixed
+// gencode format. The view is checked once when it is created, so that its accessors can index the encoding
+// without bounds errors, and neither creating a view nor reading from it allocates.
+// Hashes, signatures and addresses are returned as arrays, copied to the caller's stack.
+
+const (
+	// viewHeadSize is the size of an encoded coin.BlockHeader in the view layouts
+	viewHeadSize = 4 + 8 + 8 + 8 + 3*32
+	// viewOutputSize is the size of an encoded coin.TransactionOutput in the view layouts
+	viewOutputSize = addressSize + 8 + 8
+	// viewSigSize is the size of a cipher.Sig
+	viewSigSize = len(cipher.Sig{})
+	// viewHashSize is the size of a cipher.SHA256
+	viewHashSize = len(cipher.SHA256{})
+)
+
+// ViewFormats are the formats supported by NewBlockView
+var ViewFormats = []string{
+	"sky",
+	"skyenc",
+	"cgfixed",
+	"gencode",
+}
+
+// viewLayout returns the wire layout of a format supported by NewBlockView
+func viewLayout(format string) (wireLayout, bool) {
+	switch format {
+	case "sky", "skyenc", "cgfixed":
+		return skyLayout, true
+	case "gencode":
+		return gencodeLayout, true
+	default:
+		return wireLayout{}, false
+	}
+}
+
+// viewLimits returns limits for decoding a view in layout
+func viewLimits(layout wireLayout, limits DecodeLimits) DecodeLimits {
+	// The Skycoin encoder rejects longer slices
+	if !layout.varintLength {
+		return limits.capped(maxLenSkyencoder, 0)
+	}
+	return limits
+}
+
+// BlockView is a view of an encoded coin.SignedBlock. It refers to the encoding, which must not be modified
+// while the view is used.
+type BlockView struct {
+	data   []byte
+	layout wireLayout
+	// head, txns and sig are the offsets of the header, the first transaction and the block signature
+	head  int
+	txns  int
+	sig   int
+	nTxns int
+}
+
+// NewBlockView returns a view of a coin.SignedBlock encoded in the named format, which must contain exactly one block.
+// A payload which is not a valid encoding, or which exceeds DefaultDecodeLimits or the 65535 element limit
+// of the Skycoin encoder, is rejected with a *DecodeError.
+func NewBlockView(format string, data []byte) (BlockView, error) {
+	layout, ok := viewLayout(format)
+	if !ok {
+		return BlockView{}, fmt.Errorf("views are not supported for format %q", format)
+	}
+	b, err := newBlockView(layout, data)
+	if err == nil {
+		err = checkViewLimits(b, data, viewLimits(layout, DefaultDecodeLimits))
+	}
+	return b, err
+}
+
+// newBlockView checks data and returns a view of it. It does not check limits.
+func newBlockView(layout wireLayout, data []byte) (BlockView, error) {
+	b := BlockView{
+		data:   data,
+		layout: layout,
+	}
+
+	offset := 0
+	if layout.sigFirst {
+		b.sig = offset
+		offset += viewSigSize
+	}
+
+	b.head = offset
+	offset += viewHeadSize
+	if offset > len(data) {
+		return BlockView{}, viewError(ErrTruncated, len(data), "Block.Head", nil)
+	}
+
+	n, offset, err := viewLength(layout, data, offset, 1, "Block.Body.Transactions")
+	if err != nil {
+		return BlockView{}, err
+	}
+	b.txns = offset
+	b.nTxns = n
+
+	for i := 0; i < n; i++ {
+		txn, err := newTransactionView(layout, data, offset)
+		if err != nil {
+			if e, ok := err.(*DecodeError); ok {
+				e.Path = joinPath(fmt.Sprintf("Block.Body.Transactions[%d]", i), e.Path)
+			}
+			return BlockView{}, err
+		}
+		offset = txn.end
+	}
+
+	if !layout.sigFirst {
+		b.sig = offset
+		offset += viewSigSize
+	}
+	if offset > len(data) {
+		return BlockView{}, viewError(ErrTruncated, len(data), "Sig", nil)
+	}
+	if offset != len(data) {
+		return BlockView{}, viewError(ErrTrailingBytes, offset, "", nil)
+	}
+
+	return b, nil
+}
+
+// viewError returns a *DecodeError found while checking a view. It is only called on failure,
+// so that checking a valid payload does not allocate.
+func viewError(kind error, offset int, path string, err error) error {
+	return &DecodeError{
+		Kind:   kind,
+		Offset: offset,
+		Path:   path,
+		Err:    err,
+	}
+}
+
+// viewLength reads the length prefix at offset of a list of elements of size bytes,
+// and returns the length and the offset of the first element
+func viewLength(layout wireLayout, data []byte, offset, size int, path string) (int, int, error) {
+	var n uint64
+	next := offset
+	if layout.varintLength {
+		x, k := binary.Uvarint(data[offset:])
+		if k == 0 {
+			return 0, 0, viewError(ErrTruncated, offset, path, nil)
+		}
+		if k < 0 {
+			return 0, 0, viewError(ErrMalformed, offset, path, fmt.Errorf("varint overflows uint64"))
+		}
+		n = x
+		next += k
+	} else {
+		if len(data)-offset < 4 {
+			return 0, 0, viewError(ErrTruncated, offset, path, nil)
+		}
+		n = uint64(layout.order.Uint32(data[offset:]))
+		next += 4
+	}
+
+	if n > uint64((len(data)-next)/size) {
+		return 0, 0, viewError(ErrTruncated, offset, path, fmt.Errorf("length %d exceeds the remaining %d bytes", n, len(data)-next))
+	}
+	return int(n), next, nil
+}
+
+// Version returns Block.Head.Version
+func (b BlockView) Version() uint32 {
+	return b.layout.order.Uint32(b.data[b.head:])
+}
+
+// Time returns Block.Head.Time
+func (b BlockView) Time() uint64 {
+	return b.layout.order.Uint64(b.data[b.head+4:])
+}
+
+// BkSeq returns Block.Head.BkSeq
+func (b BlockView) BkSeq() uint64 {
+	return b.layout.order.Uint64(b.data[b.head+12:])
+}
+
+// Fee returns Block.Head.Fee
+func (b BlockView) Fee() uint64 {
+	return b.layout.order.Uint64(b.data[b.head+20:])
+}
+
+// Head returns Block.Head
+func (b BlockView) Head() coin.BlockHeader {
+	return coin.BlockHeader{
+		Version:  b.Version(),
+		Time:     b.Time(),
+		BkSeq:    b.BkSeq(),
+		Fee:      b.Fee(),
+		PrevHash: b.PrevHash(),
+		BodyHash: b.BodyHash(),
+		UxHash:   b.UxHash(),
+	}
+}
+
+// PrevHash returns Block.Head.PrevHash
+func (b BlockView) PrevHash() cipher.SHA256 {
+	return viewHash(b.data[b.head+28:])
+}
+
+// BodyHash returns Block.Head.BodyHash
+func (b BlockView) BodyHash() cipher.SHA256 {
+	return viewHash(b.data[b.head+60:])
+}
+
+// UxHash returns Block.Head.UxHash
+func (b BlockView) UxHash() cipher.SHA256 {
+	return viewHash(b.data[b.head+92:])
+}
+
+// Sig returns the block signature
+func (b BlockView) Sig() cipher.Sig {
+	var sig cipher.Sig
+	copy(sig[:], b.data[b.sig:])
+	return sig
+}
+
+// NumTransactions returns the number of transactions in the block
+func (b BlockView) NumTransactions() int {
+	return b.nTxns
+}
+
+// Transactions returns an iterator over the transactions of the block
+func (b BlockView) Transactions() TransactionIter {
+	return TransactionIter{
+		block:  b,
+		i:      -1,
+		offset: b.txns,
+	}
+}
+
+func viewHash(data []byte) cipher.SHA256 {
+	var h cipher.SHA256
+	copy(h[:], data)
+	return h
+}
+
+// TransactionIter iterates over the transactions of a BlockView.
+// Transactions are not indexed, so they can only be read in order.
+type TransactionIter struct {
+	block  BlockView
+	i      int
+	offset int
+	txn    TransactionView
+}
+
+// Next advances to the next transaction, and returns false after the last
+func (it *TransactionIter) Next() bool {
+	if it.i+1 >= it.block.nTxns {
+		return false
+	}
+	it.i++
+	// The transactions were checked when the view was created
+	it.txn, _ = newTransactionView(it.block.layout, it.block.data, it.offset)
+	it.offset = it.txn.end
+	return true
+}
+
+// Index returns the index of the current transaction in the block
+func (it *TransactionIter) Index() int {
+	return it.i
+}
+
+// Transaction returns the current transaction
+func (it *TransactionIter) Transaction() TransactionView {
+	return it.txn
+}
+
+// TransactionView is a view of an encoded coin.Transaction
+type TransactionView struct {
+	data  []byte
+	order binary.ByteOrder
+	// start is the offset of the transaction, and sigs, in and out the offsets of the first element of each list
+	start int
+	sigs  int
+	in    int
+	out   int
+	end   int
+	nSigs int
+	nIn   int
+	nOut  int
+}
+
+// newTransactionView checks the transaction at offset and returns a view of it
+func newTransactionView(layout wireLayout, data []byte, offset int) (TransactionView, error) {
+	t := TransactionView{
+		data:  data,
+		order: layout.order,
+		start: offset,
+	}
+
+	offset += 4 + 1 + viewHashSize
+	if offset > len(data) {
+		return TransactionView{}, viewError(ErrTruncated, len(data), "", nil)
+	}
+
+	var err error
+	if t.nSigs, t.sigs, err = viewLength(layout, data, offset, viewSigSize, "Sigs"); err != nil {
+		return TransactionView{}, err
+	}
+	offset = t.sigs + t.nSigs*viewSigSize
+
+	if t.nIn, t.in, err = viewLength(layout, data, offset, viewHashSize, "In"); err != nil {
+		return TransactionView{}, err
+	}
+	offset = t.in + t.nIn*viewHashSize
+
+	if t.nOut, t.out, err = viewLength(layout, data, offset, viewOutputSize, "Out"); err != nil {
+		return TransactionView{}, err
+	}
+	t.end = t.out + t.nOut*viewOutputSize
+
+	return t, nil
+}
+
+// Length returns the Length field of the transaction
+func (t TransactionView) Length() uint32 {
+	return t.order.Uint32(t.data[t.start:])
+}
+
+// Type returns the Type field of the transaction
+func (t TransactionView) Type() uint8 {
+	return t.data[t.start+4]
+}
+
+// InnerHash returns the InnerHash field of the transaction
+func (t TransactionView) InnerHash() cipher.SHA256 {
+	return viewHash(t.data[t.start+5:])
+}
+
+// NumSigs returns the number of signatures
+func (t TransactionView) NumSigs() int {
+	return t.nSigs
+}
+
+// Sig returns signature i
+func (t TransactionView) Sig(i int) cipher.Sig {
+	if i < 0 || i >= t.nSigs {
+		panic(fmt.Sprintf("signature %d is out of range [0, %d)", i, t.nSigs))
+	}
+	var sig cipher.Sig
+	copy(sig[:], t.data[t.sigs+i*viewSigSize:])
+	return sig
+}
+
+// NumIn returns the number of inputs
+func (t TransactionView) NumIn() int {
+	return t.nIn
+}
+
+// In returns the hash of input i
+func (t TransactionView) In(i int) cipher.SHA256 {
+	if i < 0 || i >= t.nIn {
+		panic(fmt.Sprintf("input %d is out of range [0, %d)", i, t.nIn))
+	}
+	return viewHash(t.data[t.in+i*viewHashSize:])
+}
+
+// NumOut returns the number of outputs
+func (t TransactionView) NumOut() int {
+	return t.nOut
+}
+
+// Out returns output i
+func (t TransactionView) Out(i int) OutputView {
+	if i < 0 || i >= t.nOut {
+		panic(fmt.Sprintf("output %d is out of range [0, %d)", i, t.nOut))
+	}
+	offset := t.out + i*viewOutputSize
+	return OutputView{
+		data:  t.data[offset : offset+viewOutputSize],
+		order: t.order,
+	}
+}
+
+// copyTo copies the transaction to txn, whose Sigs, In and Out must have the lengths of the transaction's
+func (t TransactionView) copyTo(txn *coin.Transaction) {
+	txn.Length = t.Length()
+	txn.Type = t.Type()
+	txn.InnerHash = t.InnerHash()
+	for i := range txn.Sigs {
+		txn.Sigs[i] = t.Sig(i)
+	}
+	for i := range txn.In {
+		txn.In[i] = t.In(i)
+	}
+	for i := range txn.Out {
+		o := t.Out(i)
+		txn.Out[i] = coin.TransactionOutput{
+			Address: o.Address(),
+			Coins:   o.Coins(),
+			Hours:   o.Hours(),
+		}
+	}
+}
+
+// OutputView is a view of an encoded coin.TransactionOutput
+type OutputView struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// Address returns the address of the output
+func (o OutputView) Address() cipher.Address {
+	a := cipher.Address{
+		Version: o.data[0],
+	}
+	copy(a.Key[:], o.data[1:addressSize])
+	return a
+}
+
+// HasAddress reports whether the output is to a, without copying its address
+func (o OutputView) HasAddress(a cipher.Address) bool {
+	return o.data[0] == a.Version && string(o.data[1:addressSize]) == string(a.Key[:])
+}
+
+// Coins returns the coins of the output
+func (o OutputView) Coins() uint64 {
+	return o.order.Uint64(o.data[addressSize:])
+}
+
+// Hours returns the hours of the output
+func (o OutputView) Hours() uint64 {
+	return o.order.Uint64(o.data[addressSize+8:])
+}
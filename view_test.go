@@ -0,0 +1,371 @@
+package serializebench
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// scanBlocks is the number of blocks in the store scanned by BenchmarkScanOutputs
+const scanBlocks = 100000
+
+// blockFromView copies a BlockView into a coin.SignedBlock
+func blockFromView(b BlockView) coin.SignedBlock {
+	var obj coin.SignedBlock
+	obj.Block.Head = coin.BlockHeader{
+		Version:  b.Version(),
+		Time:     b.Time(),
+		BkSeq:    b.BkSeq(),
+		Fee:      b.Fee(),
+		PrevHash: b.PrevHash(),
+		BodyHash: b.BodyHash(),
+		UxHash:   b.UxHash(),
+	}
+	obj.Sig = b.Sig()
+
+	it := b.Transactions()
+	for it.Next() {
+		t := it.Transaction()
+		txn := coin.Transaction{
+			Length:    t.Length(),
+			Type:      t.Type(),
+			InnerHash: t.InnerHash(),
+		}
+		for i := 0; i < t.NumSigs(); i++ {
+			txn.Sigs = append(txn.Sigs, t.Sig(i))
+		}
+		for i := 0; i < t.NumIn(); i++ {
+			txn.In = append(txn.In, t.In(i))
+		}
+		for i := 0; i < t.NumOut(); i++ {
+			o := t.Out(i)
+			txn.Out = append(txn.Out, coin.TransactionOutput{
+				Address: o.Address(),
+				Coins:   o.Coins(),
+				Hours:   o.Hours(),
+			})
+		}
+		obj.Block.Body.Transactions = append(obj.Block.Body.Transactions, txn)
+	}
+
+	return obj
+}
+
+// countViewOutputs returns the number of outputs of b to address
+func countViewOutputs(b BlockView, address cipher.Address) int {
+	n := 0
+	it := b.Transactions()
+	for it.Next() {
+		t := it.Transaction()
+		for i := 0; i < t.NumOut(); i++ {
+			if t.Out(i).HasAddress(address) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// countOutputs returns the number of outputs of obj to address
+func countOutputs(obj *coin.SignedBlock, address cipher.Address) int {
+	n := 0
+	for _, txn := range obj.Block.Body.Transactions {
+		for _, o := range txn.Out {
+			if o.Address == address {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestBlockView(t *testing.T) {
+	blocks := append([]coin.SignedBlock{getBlock()}, GenerateChain(20, 1)...)
+
+	for _, format := range ViewFormats {
+		t.Run(format, func(t *testing.T) {
+			c, err := CodecByName(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range blocks {
+				data, err := c.Encode(&blocks[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				b, err := NewBlockView(format, data)
+				if err != nil {
+					t.Fatalf("block %d: %v", i, err)
+				}
+				if b.NumTransactions() != len(blocks[i].Block.Body.Transactions) {
+					t.Errorf("block %d: expected %d transactions, got %d", i, len(blocks[i].Block.Body.Transactions), b.NumTransactions())
+				}
+				if diff := diffBlocks(blocks[i], blockFromView(b)); diff != "" {
+					t.Errorf("block %d: %s", i, diff)
+				}
+			}
+		})
+	}
+
+	if _, err := NewBlockView("json", nil); err == nil {
+		t.Error("expected an error for a format without views")
+	}
+}
+
+func TestBlockViewErrors(t *testing.T) {
+	block := getBlock()
+
+	for _, format := range []string{"skyenc", "gencode"} {
+		t.Run(format, func(t *testing.T) {
+			c, err := CodecByName(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := c.Encode(&block)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Every truncation is rejected, rather than panicking when the view is read
+			for n := 0; n < len(data); n++ {
+				_, err := NewBlockView(format, data[:n])
+				if !errors.Is(err, ErrTruncated) {
+					t.Fatalf("truncated to %d bytes: expected ErrTruncated, got %v", n, err)
+				}
+			}
+
+			_, err = NewBlockView(format, append(append([]byte(nil), data...), 0))
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) || !errors.Is(err, ErrTrailingBytes) {
+				t.Fatalf("expected ErrTrailingBytes, got %v", err)
+			}
+			if decodeErr.Offset != len(data) {
+				t.Errorf("expected the trailing bytes at %d, got %d", len(data), decodeErr.Offset)
+			}
+		})
+	}
+}
+
+func TestBlockViewLimits(t *testing.T) {
+	block := wideBlock(maxLenSkyencoder + 1)
+	var limitErr *LimitError
+
+	// gencode has no element limit of its own, so the block is encoded, but its view exceeds DefaultDecodeLimits
+	c, err := CodecByName("gencode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := c.Encode(&block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBlockView("gencode", data); !errors.As(err, &limitErr) || limitErr.Limit != "MaxSigs" {
+		t.Errorf("expected a MaxSigs *LimitError, got %v", err)
+	}
+
+	// The Skycoin encoder rejects the block, so its encoding is made from the encoding of a block with one signature
+	one := wideBlock(1)
+	data, err = encodeSkyencoder(&one)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := viewHeadSize + 4 + 4 + 1 + viewHashSize
+	binary.LittleEndian.PutUint32(data[offset:], maxLenSkyencoder+1)
+	sigs := offset + 4 + viewSigSize
+	data = append(data[:sigs], append(make([]byte, maxLenSkyencoder*viewSigSize), data[sigs:]...)...)
+	if _, err := NewBlockView("skyenc", data); !errors.As(err, &limitErr) || limitErr.Max != maxLenSkyencoder {
+		t.Errorf("expected a *LimitError of the Skycoin encoder limit, got %v", err)
+	}
+
+	// A mapped store checks its blocks against the store's limits
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	s, err := OpenBlockStore(filepath.Join(dir, "blocks"), c, DefaultDecodeLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Append(&block); err != nil {
+		t.Fatal(err)
+	}
+	m, err := s.Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if _, err := m.View(0); !errors.As(err, &limitErr) || limitErr.Limit != "MaxSigs" {
+		t.Errorf("expected a MaxSigs *LimitError, got %v", err)
+	}
+}
+
+func TestMappedBlockStore(t *testing.T) {
+	blocks := GenerateChain(100, 1)
+	address := blocks[0].Block.Body.Transactions[0].Out[0].Address
+
+	for _, format := range ViewFormats {
+		t.Run(format, func(t *testing.T) {
+			c, err := CodecByName(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir := tempDir(t)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "blocks")
+			writeStore(t, path, c, blocks[:50])
+
+			s, err := OpenBlockStore(path, c, DefaultDecodeLimits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			// Blocks not yet synced are mapped too
+			for i := 50; i < len(blocks); i++ {
+				if _, err := s.Append(&blocks[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			m, err := s.Map()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+			if m.Len() != len(blocks) {
+				t.Fatalf("expected %d blocks, got %d", len(blocks), m.Len())
+			}
+
+			expected := 0
+			err = m.Range(func(i int, b BlockView) error {
+				if diff := diffBlocks(blocks[i], blockFromView(b)); diff != "" {
+					t.Errorf("block %d: %s", i, diff)
+				}
+				expected += countOutputs(&blocks[i], address)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Scanning the views does not allocate
+			var found int
+			allocs := testing.AllocsPerRun(10, func() {
+				found = 0
+				if err := m.Range(func(i int, b BlockView) error {
+					found += countViewOutputs(b, address)
+					return nil
+				}); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if allocs != 0 {
+				t.Errorf("expected no allocations, got %v per scan", allocs)
+			}
+			if found != expected {
+				t.Errorf("expected %d outputs to %s, found %d", expected, address, found)
+			}
+
+			if _, err := m.View(len(blocks)); err == nil {
+				t.Error("expected an error for a block out of range")
+			}
+		})
+	}
+
+	c, err := CodecByName("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	s, err := OpenBlockStore(filepath.Join(dir, "blocks"), c, DefaultDecodeLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Map(); err == nil {
+		t.Error("expected an error mapping a store in a format without views")
+	}
+}
+
+// BenchmarkScanOutputs finds the outputs to one address in a store of scanBlocks blocks, per op.
+// view scans a MappedBlockStore, and decode decodes each block into a coin.SignedBlock with BlockStore.Range,
+// which for skyenc is DecodeSignedBlock.
+func BenchmarkScanOutputs(b *testing.B) {
+	chain := GenerateChain(storeBlocks, 1)
+	address := chain[0].Block.Body.Transactions[0].Out[0].Address
+
+	for _, format := range []string{"skyenc", "gencode"} {
+		c, err := CodecByName(format)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		dir := tempDir(b)
+		defer os.RemoveAll(dir)
+
+		// The chain is repeated, renumbered, to make scanBlocks blocks without holding them all in memory
+		s, err := OpenBlockStore(filepath.Join(dir, "blocks"), c, DefaultDecodeLimits)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer s.Close()
+		for i := 0; i < scanBlocks; i++ {
+			block := chain[i%len(chain)]
+			block.Block.Head.BkSeq = uint64(i)
+			if _, err := s.Append(&block); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := s.Sync(); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(format+"/view", func(b *testing.B) {
+			m, err := s.Map()
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer m.Close()
+
+			b.SetBytes(s.Size())
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				found := 0
+				if err := m.Range(func(_ int, v BlockView) error {
+					found += countViewOutputs(v, address)
+					return nil
+				}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N*scanBlocks)/time.Since(start).Seconds(), "blocks/s")
+		})
+
+		b.Run(format+"/decode", func(b *testing.B) {
+			b.SetBytes(s.Size())
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				found := 0
+				if err := s.Range(func(_ int, obj *coin.SignedBlock) error {
+					found += countOutputs(obj, address)
+					return nil
+				}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N*scanBlocks)/time.Since(start).Seconds(), "blocks/s")
+		})
+	}
+}